@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"sync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -9,24 +10,54 @@ import (
 // App 应用程序结构
 type App struct {
 	ctx context.Context
+
+	watchMu  sync.Mutex
+	watchSeq int
+	watches  map[string]*folderWatcher
+
+	jobsMu sync.Mutex
+	jobs   map[string]context.CancelFunc // 可取消的操作，按 jobID 索引
+
+	queue *jobQueue // 后台任务队列
 }
 
 // NewApp 创建新的应用实例
 func NewApp() *App {
-	return &App{}
+	a := &App{
+		watches: make(map[string]*folderWatcher),
+		jobs:    make(map[string]context.CancelFunc),
+	}
+	a.queue = newJobQueue(a)
+	return a
 }
 
 // startup 应用启动时调用
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	a.queue.start()
+}
+
+// shutdown 应用退出时调用
+func (a *App) shutdown(ctx context.Context) {
+	a.queue.shutdown()
+	a.cancelAllJobs()
+	a.stopAllWatches()
+}
+
+// emit 向前端发送事件（无界面运行时忽略）
+func (a *App) emit(event string, data interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, event, data)
 }
 
 // SelectImages 选择图片文件
 func (a *App) SelectImages() []string {
 	files, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "选择图片",
+		Title: tr("dialog.select_images"),
 		Filters: []runtime.FileFilter{
-			{DisplayName: "图片文件", Pattern: "*.jpg;*.jpeg;*.png;*.gif;*.webp;*.tiff;*.tif;*.bmp"},
+			{DisplayName: tr("filter.images"), Pattern: "*.jpg;*.jpeg;*.png;*.gif;*.webp;*.avif;*.tiff;*.tif;*.bmp"},
 		},
 	})
 	if err != nil {
@@ -38,10 +69,81 @@ func (a *App) SelectImages() []string {
 // SelectOutputDir 选择输出目录
 func (a *App) SelectOutputDir() string {
 	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "选择输出目录",
+		Title: tr("dialog.select_output_dir"),
 	})
 	if err != nil {
 		return ""
 	}
 	return dir
 }
+
+// SelectFolder 选择要批量压缩的文件夹
+func (a *App) SelectFolder() string {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: tr("dialog.select_folder"),
+	})
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+// SelectPresetFile 选择要导入的预设文件
+func (a *App) SelectPresetFile() string {
+	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: tr("dialog.import_presets"),
+		Filters: []runtime.FileFilter{
+			{DisplayName: tr("filter.presets"), Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return ""
+	}
+	return file
+}
+
+// SelectPresetExportPath 选择预设导出位置
+func (a *App) SelectPresetExportPath() string {
+	file, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           tr("dialog.export_presets"),
+		DefaultFilename: sharedPresetFile,
+		Filters: []runtime.FileFilter{
+			{DisplayName: tr("filter.presets"), Pattern: "*.json"},
+		},
+	})
+	if err != nil {
+		return ""
+	}
+	return file
+}
+
+// SelectHistoryExportPath 选择历史记录导出位置
+func (a *App) SelectHistoryExportPath() string {
+	file, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           tr("dialog.export_history"),
+		DefaultFilename: "squash-history.csv",
+		Filters: []runtime.FileFilter{
+			{DisplayName: tr("filter.csv"), Pattern: "*.csv"},
+		},
+	})
+	if err != nil {
+		return ""
+	}
+	return file
+}
+
+// SelectReportExportPath 选择批处理报告导出位置（.json 或 .csv）
+func (a *App) SelectReportExportPath() string {
+	file, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           tr("dialog.export_report"),
+		DefaultFilename: "squash-report.json",
+		Filters: []runtime.FileFilter{
+			{DisplayName: tr("filter.json_report"), Pattern: "*.json"},
+			{DisplayName: tr("filter.csv_report"), Pattern: "*.csv"},
+		},
+	})
+	if err != nil {
+		return ""
+	}
+	return file
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/color"
+)
+
+// "auto" 输出格式的选择方式
+const (
+	AutoPNGLossless  = "png-lossless"  // 不超过 256 种颜色，无损索引色 PNG
+	AutoPNGQuantized = "png-quantized" // 图形、截图：大面积纯色和硬边缘，量化 PNG
+	AutoJPEG         = "jpeg"          // 不透明的照片
+	AutoWebP         = "webp"          // 带透明通道的照片
+)
+
+const (
+	autoSampleSize     = 256  // 统计相邻像素差异时每个方向最多采样的点数
+	autoColorSamples   = 4096 // 统计颜色数时的采样数
+	autoEdgeThreshold  = 64   // 相邻像素通道差异达到该值视为硬边缘
+	autoNoiseThreshold = 16   // 相邻像素通道差异小于该值（且不为 0）视为噪点或渐变
+)
+
+// analyzeImage 统计图片的颜色数、透明通道以及相邻像素的差异分布
+func analyzeImage(ctx context.Context, img image.Image) (*FormatDecision, error) {
+	b := img.Bounds()
+	d := &FormatDecision{
+		UniqueColors: countUniqueColors(img, autoColorSamples),
+		HasAlpha:     !isOpaque(img),
+	}
+
+	stepX, stepY := max(1, b.Dx()/autoSampleSize), max(1, b.Dy()/autoSampleSize)
+	var total, flat, edge, noise int
+	for y := b.Min.Y; y < b.Max.Y; y += stepY {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
+		for x := b.Min.X; x+1 < b.Max.X; x += stepX {
+			diff := channelDiff(img.At(x, y), img.At(x+1, y))
+			total++
+			switch {
+			case diff == 0:
+				flat++
+			case diff >= autoEdgeThreshold:
+				edge++
+			case diff < autoNoiseThreshold:
+				noise++
+			}
+		}
+	}
+	if total > 0 {
+		d.FlatRatio = float64(flat) / float64(total)
+		d.EdgeRatio = float64(edge) / float64(total)
+		d.NoiseRatio = float64(noise) / float64(total)
+	}
+	return d, nil
+}
+
+// channelDiff 返回两个颜色各通道（8 位）差值的最大值
+func channelDiff(a, b color.Color) int {
+	c1 := color.NRGBAModel.Convert(a).(color.NRGBA)
+	c2 := color.NRGBAModel.Convert(b).(color.NRGBA)
+	diff := 0
+	for _, d := range []int{
+		int(c1.R) - int(c2.R), int(c1.G) - int(c2.G), int(c1.B) - int(c2.B), int(c1.A) - int(c2.A),
+	} {
+		diff = max(diff, d, -d)
+	}
+	return diff
+}
+
+// chooseAutoFormat 根据内容分析选择输出方式：
+// 颜色很少时用无损索引色 PNG；纯色区域多、噪点少的图形用量化 PNG（没有 JPEG 的振铃伪影）；
+// 照片用 JPEG，带透明通道的照片用 WebP（不支持时用量化 PNG）
+func chooseAutoFormat(d *FormatDecision) {
+	graphic := d.FlatRatio >= 0.5 && d.NoiseRatio < 0.25
+	switch {
+	case d.UniqueColors <= 256:
+		d.Mode = AutoPNGLossless
+	case graphic:
+		d.Mode = AutoPNGQuantized
+	case d.HasAlpha && webpSupported():
+		d.Mode = AutoWebP
+	case d.HasAlpha:
+		d.Mode = AutoPNGQuantized
+	default:
+		d.Mode = AutoJPEG
+	}
+	d.Format = "png"
+	switch d.Mode {
+	case AutoJPEG:
+		d.Format = "jpeg"
+	case AutoWebP:
+		d.Format = "webp"
+	}
+}
+
+// encodeAutoFormat 分析图片内容后按选择的方式编码
+func encodeAutoFormat(ctx context.Context, img image.Image, quality int, jo jpegOptions, pngEffort int) (*FormatDecision, []byte, string, error) {
+	d, err := analyzeImage(ctx, img)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	chooseAutoFormat(d)
+
+	if d.Mode == AutoPNGLossless {
+		// 采样可能漏掉少量颜色，完整统计超过 256 色时改用量化 PNG
+		paletted, err := exactPaletted(ctx, img)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if paletted != nil {
+			data, err := encodePNG(ctx, paletted, pngEffort)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			logger.Debug("auto format", "mode", d.Mode, "colors", len(paletted.Palette))
+			return d, data, "image/png", nil
+		}
+		d.Mode = AutoPNGQuantized
+	}
+
+	data, mimeType, err := encodeImageWith(ctx, img, d.Format, quality, jo, pngEffort)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	logger.Debug("auto format", "mode", d.Mode, "colors", d.UniqueColors, "alpha", d.HasAlpha,
+		"flat", d.FlatRatio, "edge", d.EdgeRatio, "noise", d.NoiseRatio)
+	return d, data, mimeType, nil
+}
+
+// exactPaletted 图片不超过 256 种颜色（含透明度）时返回颜色完全相同的索引色图片，否则返回 nil
+func exactPaletted(ctx context.Context, img image.Image) (*image.Paletted, error) {
+	b := img.Bounds()
+	index := make(map[color.NRGBA]uint8)
+	var palette color.Palette
+	out := image.NewPaletted(b, nil)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i, ok := index[c]
+			if !ok {
+				if len(palette) == 256 {
+					return nil, nil
+				}
+				i = uint8(len(palette))
+				index[c] = i
+				palette = append(palette, c)
+			}
+			out.SetColorIndex(x, y, i)
+		}
+	}
+	out.Palette = palette
+	return out, nil
+}
+
+// describeFormatDecision 生成选择理由（按当前语言），缓存中只保存分析结果
+func describeFormatDecision(d *FormatDecision) *FormatDecision {
+	if d == nil {
+		return nil
+	}
+	out := *d
+	percent := func(r float64) int { return int(r*100 + 0.5) }
+	switch d.Mode {
+	case AutoPNGLossless:
+		out.Reason = tr("auto.png_lossless", d.UniqueColors)
+	case AutoPNGQuantized:
+		if d.FlatRatio >= 0.5 {
+			out.Reason = tr("auto.png_quantized", percent(d.FlatRatio), percent(d.EdgeRatio))
+		} else {
+			out.Reason = tr("auto.png_quantized_alpha")
+		}
+	case AutoWebP:
+		out.Reason = tr("auto.webp", percent(d.NoiseRatio))
+	default:
+		out.Reason = tr("auto.jpeg", percent(d.NoiseRatio), d.UniqueColors)
+	}
+	return &out
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"bytes"
+	"image"
+
+	"github.com/gen2brain/avif"
+)
+
+// encodeAvif 编码为 AVIF 格式（纯 Go 版本，内置 WASM 编码器）
+func encodeAvif(buf *bytes.Buffer, img image.Image, quality int) error {
+	return avif.Encode(buf, img, avif.Options{Quality: quality, QualityAlpha: quality, Speed: 8})
+}
+
+// avifSupported 是否支持 AVIF 输出
+func avifSupported() bool {
+	return true
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bestFormatOrder "best" 输出格式的候选顺序，大小相同时靠前的优先
+var bestFormatOrder = []string{"avif", "webp", "jpeg", "png"}
+
+// normalizeFormatName 将格式名或 MIME 类型（如 image/webp、jpg）转换为内部格式名，
+// 通配符（*/*、image/*）返回 "*"，无法识别时返回空字符串
+func normalizeFormatName(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "*", "*/*", "image/*":
+		return "*"
+	}
+	s = strings.TrimPrefix(s, "image/")
+	switch s {
+	case "jpg", "jpeg", "pjpeg":
+		return "jpeg"
+	case "png", "webp", "avif", "gif":
+		return s
+	}
+	return ""
+}
+
+// acceptsFormat 判断格式是否在可接受的格式中，列表为空时接受所有格式
+func acceptsFormat(accept []string, format string) bool {
+	if len(accept) == 0 {
+		return true
+	}
+	format = normalizeFormatName(format)
+	for _, a := range accept {
+		if n := normalizeFormatName(a); n == "*" || n == format {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptKey 返回参与缓存键计算的可接受格式（规范化并排序），非 "best" 时为空
+func acceptKey(options CompressOptions) string {
+	if options.OutputFormat != "best" || len(options.AcceptFormats) == 0 {
+		return ""
+	}
+	var names []string
+	for _, a := range options.AcceptFormats {
+		if n := normalizeFormatName(a); n != "" && !containsString(names, n) {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		return "none" // 没有可识别的格式，与"接受所有格式"区分
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// parseAcceptHeader 解析 HTTP Accept 头，返回客户端接受的媒体类型（去掉 q=0 的项）
+func parseAcceptHeader(header string) []string {
+	var types []string
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.TrimSpace(fields[0])
+		if mediaType == "" {
+			continue
+		}
+		rejected := false
+		for _, param := range fields[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(k, "q") {
+				if q, err := strconv.ParseFloat(v, 64); err == nil && q == 0 {
+					rejected = true
+				}
+			}
+		}
+		if !rejected {
+			types = append(types, mediaType)
+		}
+	}
+	return types
+}
+
+// bestFormatCandidates 返回参与比较的格式：AVIF、WebP（当前平台支持时）以及 JPEG 或 PNG 之一
+// 原图为 PNG 或带透明通道时使用 PNG，否则使用 JPEG；只保留可接受的格式
+func bestFormatCandidates(img image.Image, inputFormat string, accept []string) []string {
+	fallback := "jpeg"
+	if inputFormat == "png" || !isOpaque(img) {
+		fallback = "png"
+	}
+	var formats []string
+	for _, f := range bestFormatOrder {
+		if (f == "jpeg" || f == "png") && f != fallback {
+			continue
+		}
+		if responsiveFormatSupported(f) && acceptsFormat(accept, f) {
+			formats = append(formats, f)
+		}
+	}
+	// 带透明通道的图片不能用 JPEG，客户端只接受 JPEG 时仍然输出 JPEG
+	if len(formats) == 0 && fallback == "png" && acceptsFormat(accept, "jpeg") {
+		formats = append(formats, "jpeg")
+	}
+	return formats
+}
+
+// isOpaque 判断图片是否不透明
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+// encodeBestFormat 用每种候选格式编码图片，返回最小的结果以及各候选的大小
+// 不支持透明通道的格式使用合成到 background 上的图片
+func encodeBestFormat(ctx context.Context, img image.Image, inputFormat string, accept []string, quality int, background color.Color, jo jpegOptions, pngEffort int) (string, []byte, string, []FormatCandidate, error) {
+	formats := bestFormatCandidates(img, inputFormat, accept)
+	if len(formats) == 0 {
+		return "", nil, "", nil, newError(ErrUnsupportedFormat, nil, "err.no_accepted_format", strings.Join(accept, ", "))
+	}
+
+	var bestFormat, bestMime string
+	var bestData []byte
+	candidates := make([]FormatCandidate, 0, len(formats))
+	opaque := isOpaque(img)
+	for _, f := range formats {
+		src := img
+		if !opaque && !formatSupportsAlpha(f) {
+			src = flattenAlpha(img, background)
+		}
+		data, mimeType, err := encodeImageWith(ctx, src, f, quality, jo, pngEffort)
+		if err != nil {
+			return "", nil, "", nil, err
+		}
+		candidates = append(candidates, FormatCandidate{Format: f, Size: int64(len(data))})
+		if bestData == nil || len(data) < len(bestData) {
+			bestFormat, bestData, bestMime = f, data, mimeType
+		}
+	}
+	logger.Debug("best format", "format", bestFormat, "candidates", candidates)
+	return bestFormat, bestData, bestMime, candidates, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+func TestParseAcceptHeader(t *testing.T) {
+	cases := map[string][]string{
+		"":                                 nil,
+		"image/avif,image/webp,*/*;q=0.8":  {"image/avif", "image/webp", "*/*"},
+		"image/webp;q=0, image/png; q=0.5": {"image/png"},
+		"image/avif;Q=0.0,image/jpeg":      {"image/jpeg"},
+		" , image/png ;level=1, ;q=1":      {"image/png"},
+		"image/webp;q=bogus":               {"image/webp"},
+		"text/html,application/xml;q=0.9,": {"text/html", "application/xml"},
+	}
+	for header, want := range cases {
+		if got := parseAcceptHeader(header); !slices.Equal(got, want) {
+			t.Errorf("%q: got %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestAcceptsFormat(t *testing.T) {
+	cases := []struct {
+		accept []string
+		format string
+		want   bool
+	}{
+		{nil, "avif", true},
+		{[]string{"image/webp"}, "webp", true},
+		{[]string{"image/webp"}, "avif", false},
+		{[]string{"JPG"}, "jpeg", true},
+		{[]string{"image/pjpeg"}, "jpg", true},
+		{[]string{"image/*"}, "png", true},
+		{[]string{"*/*"}, "avif", true},
+		{[]string{"text/html"}, "png", false},
+	}
+	for _, c := range cases {
+		if got := acceptsFormat(c.accept, c.format); got != c.want {
+			t.Errorf("%q %s: got %v", c.accept, c.format, got)
+		}
+	}
+}
+
+// testSupported 去掉当前平台不支持的格式
+func testSupported(formats ...string) []string {
+	var out []string
+	for _, f := range formats {
+		if responsiveFormatSupported(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// 不透明的非 PNG 原图用 JPEG 比较，PNG 或带透明通道时用 PNG；透明图片只接受 JPEG 时仍输出 JPEG
+func TestBestFormatCandidates(t *testing.T) {
+	photo := testPhoto(8, 8)
+	alpha := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	cases := []struct {
+		name   string
+		img    image.Image
+		input  string
+		accept []string
+		want   []string
+	}{
+		{"photo", photo, "jpeg", nil, testSupported("avif", "webp", "jpeg")},
+		{"png input", photo, "png", nil, testSupported("avif", "webp", "png")},
+		{"alpha", alpha, "webp", nil, testSupported("avif", "webp", "png")},
+		{"accept webp", photo, "jpeg", []string{"image/webp", "image/jpeg"}, testSupported("webp", "jpeg")},
+		{"accept jpeg only", alpha, "png", []string{"image/jpeg"}, []string{"jpeg"}},
+		{"accept png for photo", photo, "jpeg", []string{"image/png"}, nil},
+	}
+	for _, c := range cases {
+		if got := bestFormatCandidates(c.img, c.input, c.accept); !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+// 返回各候选中最小的结果；没有可接受的格式时返回 ErrUnsupportedFormat
+func TestEncodeBestFormat(t *testing.T) {
+	ctx := context.Background()
+	img := testPNGImage(32, 32, func(x, y int) color.NRGBA {
+		return color.NRGBA{uint8(x * 8), uint8(y * 8), 0, uint8(255 - x)}
+	})
+	format, data, mimeType, candidates, err := encodeBestFormat(ctx, img, "png", nil, 80, defaultBackground, defaultJPEGOptions, PNGEffortOff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != len(testSupported("avif", "webp", "png")) {
+		t.Fatalf("candidates %+v", candidates)
+	}
+	for _, c := range candidates {
+		if c.Size < int64(len(data)) || (c.Format == format && c.Size != int64(len(data))) {
+			t.Errorf("chose %s (%d bytes), candidate %s is %d bytes", format, len(data), c.Format, c.Size)
+		}
+	}
+	if mimeType != formatMimeType(format) {
+		t.Errorf("mime type %q for %s", mimeType, format)
+	}
+
+	if _, _, _, _, err := encodeBestFormat(ctx, testPhoto(8, 8), "jpeg", []string{"image/png"}, 80, defaultBackground, defaultJPEGOptions, PNGEffortOff); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("want ErrUnsupportedFormat, got %v", err)
+	}
+}
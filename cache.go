@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheVersion 编码器输出变化时递增，使旧的缓存失效
+const cacheVersion = 4
+
+// encodedImage 编码阶段的结果，可缓存后跳过解码和编码
+type encodedImage struct {
+	Data           []byte `json:"-"`
+	InputFormat    string `json:"inputFormat"`
+	Format         string `json:"format"`
+	MimeType       string `json:"mimeType"`
+	OriginalWidth  int    `json:"originalWidth"`
+	OriginalHeight int    `json:"originalHeight"`
+	NewWidth       int    `json:"newWidth"`
+	NewHeight      int    `json:"newHeight"`
+	UseOriginal    bool   `json:"useOriginal"` // 压缩后更大，沿用原文件（缓存中不重复保存数据）
+
+	Candidates []FormatCandidate `json:"candidates,omitempty"` // "best" 时各候选格式的编码大小
+	Decision   *FormatDecision   `json:"decision,omitempty"`   // "auto" 时的内容分析（不含按语言生成的理由）
+	Flattened  string            `json:"flattened,omitempty"`  // 输出格式不支持透明通道时合成使用的背景色
+	Lossless   bool              `json:"lossless,omitempty"`   // JPEG 无损优化（只重写熵编码，没有重新编码）
+}
+
+// cacheMaxBytes 缓存目录的大小上限，超出后按最近使用时间淘汰到 cacheLowBytes 以下
+const (
+	cacheMaxBytes = 512 << 20
+	cacheLowBytes = cacheMaxBytes * 4 / 5
+)
+
+// cacheDir 返回压缩结果缓存目录（系统缓存目录，可随时清除）
+func cacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "squash"), nil
+}
+
+// cacheUsage 缓存目录的当前大小，首次写入时扫描目录得到，之后按写入累加
+var (
+	cacheMu     sync.Mutex
+	cacheLoaded bool
+	cacheUsage  int64
+)
+
+// removeLegacyCache 删除旧版本放在配置目录中的缓存
+func removeLegacyCache() {
+	dir, err := appDataDir()
+	if err != nil {
+		return
+	}
+	os.RemoveAll(filepath.Join(dir, "cache"))
+}
+
+// cacheEntry 一个缓存条目（元数据和数据文件）
+type cacheEntry struct {
+	base string
+	size int64
+	used time.Time
+}
+
+// scanCache 列出缓存条目，使用时间为元数据文件的修改时间（命中时更新）
+func scanCache(dir string) ([]cacheEntry, int64) {
+	entries := make(map[string]*cacheEntry)
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		base := strings.TrimSuffix(strings.TrimSuffix(path, ".json"), ".bin")
+		e := entries[base]
+		if e == nil {
+			e = &cacheEntry{base: base}
+			entries[base] = e
+		}
+		e.size += info.Size()
+		total += info.Size()
+		if strings.HasSuffix(path, ".json") || e.used.IsZero() {
+			e.used = info.ModTime()
+		}
+		return nil
+	})
+	list := make([]cacheEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, *e)
+	}
+	return list, total
+}
+
+// trackCacheWrite 记录写入的字节数，超过上限时淘汰最久未使用的条目
+func trackCacheWrite(dir string, n int64) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if !cacheLoaded {
+		removeLegacyCache()
+		_, cacheUsage = scanCache(dir)
+		cacheLoaded = true
+	} else {
+		cacheUsage += n
+	}
+	if cacheUsage <= cacheMaxBytes {
+		return
+	}
+
+	list, total := scanCache(dir)
+	sort.Slice(list, func(i, j int) bool { return list[i].used.Before(list[j].used) })
+	for _, e := range list {
+		if total <= cacheLowBytes {
+			break
+		}
+		// 先删元数据，读取方不会命中只剩一半的条目
+		os.Remove(e.base + ".json")
+		os.Remove(e.base + ".bin")
+		total -= e.size
+	}
+	logger.Debug("cache evicted", "before", cacheUsage, "after", total)
+	cacheUsage = total
+}
+
+// compressCacheKey 由源文件内容哈希和影响编码结果的选项计算缓存键
+// 输出目录、文件名模板等只影响写入位置的选项不参与计算；原地优化会限制 auto/best 的输出格式，因此参与计算
+func compressCacheKey(inputHash string, options CompressOptions) string {
+	data, _ := json.Marshal(struct {
+		Version      int    `json:"v"`
+		Input        string `json:"input"`
+		Quality      int    `json:"quality"`
+		MaxWidth     uint   `json:"maxWidth"`
+		MaxHeight    uint   `json:"maxHeight"`
+		OutputFormat string `json:"outputFormat"`
+		KeepAspect   bool   `json:"keepAspect"`
+		Accept       string `json:"accept,omitempty"`
+		Background   string `json:"background,omitempty"`
+		Progressive  bool   `json:"jpegProgressive,omitempty"`
+		Subsampling  string `json:"jpegSubsampling,omitempty"`
+		QuantTable   string `json:"jpegQuantTable,omitempty"`
+		Lossless     bool   `json:"jpegLossless,omitempty"`
+		PNGEffort    int    `json:"pngEffort,omitempty"`
+		InPlace      bool   `json:"inPlace,omitempty"`
+	}{cacheVersion, inputHash, options.Quality, options.MaxWidth, options.MaxHeight, options.OutputFormat, options.KeepAspect, acceptKey(options), options.Background,
+		options.JPEGProgressive, options.JPEGSubsampling, options.JPEGQuantTable, options.JPEGLossless, options.PNGEffort, options.InPlace})
+	return hashBytes(data)
+}
+
+// cachePaths 返回缓存条目的元数据和数据文件路径
+func cachePaths(key string) (string, string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", "", err
+	}
+	base := filepath.Join(dir, key[:2], key)
+	return base + ".json", base + ".bin", nil
+}
+
+// loadCompressCache 读取缓存的编码结果，未命中时返回 false
+func loadCompressCache(key string, originalData []byte) (encodedImage, bool) {
+	var enc encodedImage
+	metaPath, dataPath, err := cachePaths(key)
+	if err != nil {
+		return enc, false
+	}
+	meta, err := os.ReadFile(metaPath)
+	if err != nil {
+		return enc, false
+	}
+	if err := json.Unmarshal(meta, &enc); err != nil {
+		return enc, false
+	}
+	if enc.UseOriginal {
+		enc.Data = originalData
+		touchCache(metaPath)
+		return enc, true
+	}
+	enc.Data, err = os.ReadFile(dataPath)
+	if err != nil {
+		return enc, false
+	}
+	touchCache(metaPath)
+	return enc, true
+}
+
+// touchCache 更新条目的使用时间，淘汰时保留最近命中的条目
+func touchCache(metaPath string) {
+	now := time.Now()
+	os.Chtimes(metaPath, now, now)
+}
+
+// saveCompressCache 保存编码结果，缓存只是加速手段，写入失败不影响压缩
+func saveCompressCache(key string, enc encodedImage) error {
+	metaPath, dataPath, err := cachePaths(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0755); err != nil {
+		return err
+	}
+	if !enc.UseOriginal {
+		if err := writeFileAtomic(dataPath, enc.Data, 0644); err != nil {
+			return err
+		}
+	}
+	meta, err := json.Marshal(enc)
+	if err != nil {
+		return err
+	}
+	// 元数据最后写入，保证命中时数据文件已完整
+	if err := writeFileAtomic(metaPath, meta, 0644); err != nil {
+		return err
+	}
+	n := int64(len(meta))
+	if !enc.UseOriginal {
+		n += int64(len(enc.Data))
+	}
+	dir, _ := cacheDir()
+	trackCacheWrite(dir, n)
+	return nil
+}
+
+// ClearCache 清空压缩结果缓存
+func (a *App) ClearCache() ActionResult {
+	dir, err := cacheDir()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("cache.clear_failed", err)}
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if err := os.RemoveAll(dir); err != nil {
+		return ActionResult{Success: false, Message: tr("cache.clear_failed", err)}
+	}
+	cacheUsage = 0
+	return ActionResult{Success: true, Message: tr("cache.cleared")}
+}
+
+// producedHashes 由 Squash 生成的文件内容哈希，首次使用时从历史记录加载
+var (
+	producedMu     sync.Mutex
+	producedLoaded bool
+	producedHashes = make(map[string]bool)
+)
+
+// markProduced 记录 Squash 生成的文件（与源文件内容相同时不记录，避免把未改动的源文件当作产物）
+func markProduced(inputHash, outputHash string) {
+	if outputHash == "" || outputHash == inputHash {
+		return
+	}
+	producedMu.Lock()
+	producedHashes[outputHash] = true
+	producedMu.Unlock()
+}
+
+// isSquashOutput 判断文件内容是否由 Squash 生成，再次有损压缩会逐代损失画质
+func isSquashOutput(hash string) bool {
+	producedMu.Lock()
+	defer producedMu.Unlock()
+
+	if !producedLoaded {
+		entries, err := loadHistory()
+		if err != nil {
+			return false
+		}
+		for _, e := range entries {
+			if e.Success && e.OutputHash != "" && e.OutputHash != e.InputHash {
+				producedHashes[e.OutputHash] = true
+			}
+		}
+		producedLoaded = true
+	}
+	return producedHashes[hash]
+}
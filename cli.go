@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// cliCommands 命令行模式支持的子命令
+var cliCommands = map[string]func(app *App, args []string) int{
+	"compress": cliCompress,
+	"watch":    cliWatch,
+	"serve":    cliServe,
+}
+
+// isCLICommand 判断参数是否为命令行子命令
+func isCLICommand(arg string) bool {
+	_, ok := cliCommands[arg]
+	return ok || arg == "help" || arg == "-h" || arg == "--help"
+}
+
+// runCLI 以命令行模式运行，返回进程退出码
+func runCLI(args []string) int {
+	cmd, ok := cliCommands[args[0]]
+	if !ok {
+		printCLIUsage()
+		return 0
+	}
+	return cmd(NewApp(), args[1:])
+}
+
+// printCLIUsage 打印命令行用法
+func printCLIUsage() {
+	fmt.Fprintln(os.Stderr, tr("cli.usage"))
+}
+
+// stringList 可重复的字符串参数，如 -exclude a -exclude b
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// defaultCLIOptions 命令行的默认压缩选项
+func defaultCLIOptions() CompressOptions {
+	return CompressOptions{
+		Quality:      80,
+		OutputFormat: "original",
+		KeepAspect:   true,
+		Collision:    CollisionOverwrite,
+		Backup:       BackupNone,
+	}
+}
+
+// presetDefaults 从参数中提取 -preset/--preset，返回预设的压缩选项作为参数默认值
+// 预设先在当前目录向上的 squash-presets.json 中查找，再查找用户预设
+func presetDefaults(args []string) (CompressOptions, error) {
+	defaults := defaultCLIOptions()
+
+	name := ""
+	for i, arg := range args {
+		trimmed := strings.TrimLeft(arg, "-")
+		if trimmed == arg {
+			continue
+		}
+		if trimmed == "preset" && i+1 < len(args) {
+			name = args[i+1]
+		} else if strings.HasPrefix(trimmed, "preset=") {
+			name = strings.TrimPrefix(trimmed, "preset=")
+		}
+	}
+	if name == "" {
+		return defaults, nil
+	}
+
+	preset, err := findPreset(name, ".")
+	if err != nil {
+		return defaults, err
+	}
+	if preset.Compress == nil {
+		return defaults, newError(ErrInvalidOptions, nil, "preset.no_compress_options", name)
+	}
+	options := *preset.Compress
+	options.KeepAspect = true
+	if options.Collision == "" {
+		options.Collision = CollisionOverwrite
+	}
+	if options.Backup == "" {
+		options.Backup = BackupNone
+	}
+	return options, nil
+}
+
+// addCompressFlags 注册与 CompressOptions 对应的命令行参数，defaults 为参数默认值（来自预设时可被参数覆盖）
+func addCompressFlags(fs *flag.FlagSet, options *CompressOptions, defaults CompressOptions) {
+	fs.String("preset", "", tr("flag.preset"))
+	fs.IntVar(&options.Quality, "quality", defaults.Quality, tr("flag.quality"))
+	fs.StringVar(&options.OutputFormat, "format", defaults.OutputFormat, tr("flag.format"))
+	options.AcceptFormats = defaults.AcceptFormats
+	fs.Var((*stringList)(&options.AcceptFormats), "accept", tr("flag.accept"))
+	fs.StringVar(&options.Background, "background", defaults.Background, tr("flag.background"))
+	fs.BoolVar(&options.JPEGProgressive, "progressive", defaults.JPEGProgressive, tr("flag.progressive"))
+	fs.StringVar(&options.JPEGSubsampling, "subsampling", defaults.JPEGSubsampling, tr("flag.subsampling"))
+	fs.StringVar(&options.JPEGQuantTable, "quant-table", defaults.JPEGQuantTable, tr("flag.quant_table"))
+	fs.BoolVar(&options.JPEGLossless, "jpeg-lossless", defaults.JPEGLossless, tr("flag.jpeg_lossless"))
+	fs.IntVar(&options.PNGEffort, "png-effort", defaults.PNGEffort, tr("flag.png_effort"))
+	fs.UintVar(&options.MaxWidth, "max-width", defaults.MaxWidth, tr("flag.max_width"))
+	fs.UintVar(&options.MaxHeight, "max-height", defaults.MaxHeight, tr("flag.max_height"))
+	fs.StringVar(&options.OutputDir, "out", defaults.OutputDir, tr("flag.out"))
+	fs.StringVar(&options.NameTemplate, "name", defaults.NameTemplate, tr("flag.name"))
+	fs.StringVar(&options.Collision, "collision", defaults.Collision, tr("flag.collision"))
+	fs.BoolVar(&options.InPlace, "in-place", defaults.InPlace, tr("flag.in_place"))
+	fs.StringVar(&options.Backup, "backup", defaults.Backup, tr("flag.backup"))
+	fs.BoolVar(&options.IgnoreProjectConfig, "no-project-config", defaults.IgnoreProjectConfig, tr("flag.no_project_config"))
+	fs.BoolVar(&options.Force, "force", defaults.Force, tr("flag.force"))
+	options.KeepAspect = defaults.KeepAspect
+}
+
+// addLogFlag 注册 -v 参数：以 debug 级别记录日志并同时输出到标准错误
+func addLogFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("v", false, tr("flag.verbose"))
+}
+
+// initCLILogging 初始化命令行模式的日志，verbose 只影响本次运行，不修改保存的日志级别
+func initCLILogging(verbose bool) {
+	if !verbose {
+		initLogging(nil)
+		return
+	}
+	initLogging(os.Stderr)
+	logLevel.Set(slog.LevelDebug)
+}
+
+// printCompressResult 打印单个文件的压缩结果
+func printCompressResult(path string, r CompressResult) {
+	switch {
+	case !r.Success:
+		fmt.Fprintf(os.Stderr, "✗ %s: [%s] %s\n", path, r.Code, r.Message)
+	case r.Skipped:
+		fmt.Printf("- %s: %s\n", path, r.Message)
+	default:
+		fmt.Printf("✓ %s → %s  %s → %s (%.1f%%)\n", path, r.OutputPath,
+			formatFileSize(r.OriginalSize), formatFileSize(r.NewSize), r.CompressionRatio)
+	}
+}
+
+// cliCompress 压缩文件或文件夹
+func cliCompress(app *App, args []string) int {
+	defaults, err := presetDefaults(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("compress", flag.ExitOnError)
+	var options CompressOptions
+	addCompressFlags(fs, &options, defaults)
+	var include, exclude stringList
+	fs.Var(&include, "include", tr("flag.include_dir"))
+	fs.Var(&exclude, "exclude", tr("flag.exclude_dir"))
+	var reports stringList
+	fs.Var(&reports, "report", tr("flag.report"))
+	verbose := addLogFlag(fs)
+	fs.Parse(args)
+	initCLILogging(*verbose)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	// Ctrl+C 中止正在压缩的文件，剩余文件标记为已取消，报告仍会写入
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := 0
+	var results []CompressResult
+	for _, path := range fs.Args() {
+		stat, err := os.Stat(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %s: %v\n", path, err)
+			r := failResult(newError(ErrRead, err, "err.open_file"))
+			r.InputPath = path
+			results = append(results, r)
+			failed++
+			continue
+		}
+
+		if !stat.IsDir() {
+			r := app.compressFile(ctx, path, options, false)
+			printCompressResult(path, r)
+			results = append(results, r)
+			if !r.Success {
+				failed++
+			}
+			continue
+		}
+
+		result := app.compressDirectory(ctx, path, DirectoryOptions{
+			Compress: options,
+			Include:  include,
+			Exclude:  exclude,
+		})
+		if result.Message != "" && result.Total == 0 && !result.Success {
+			fmt.Fprintf(os.Stderr, "✗ %s: %s\n", path, result.Message)
+			failed++
+			continue
+		}
+		for _, f := range result.Files {
+			printCompressResult(f.RelPath, f.Result)
+		}
+		fmt.Println(result.Message)
+		results = append(results, directoryResults(result)...)
+		failed += result.Failed
+	}
+
+	if len(reports) > 0 {
+		report := newBatchReport(results)
+		for _, path := range reports {
+			if err := writeBatchReport(path, report); err != nil {
+				fmt.Fprintf(os.Stderr, "✗ %s\n", tr("report.write_failed", err))
+				failed++
+			}
+		}
+	}
+
+	if ctx.Err() != nil {
+		return 130
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// cliWatch 监视文件夹，直到收到中断信号
+func cliWatch(app *App, args []string) int {
+	defaults, err := presetDefaults(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	var options WatchOptions
+	addCompressFlags(fs, &options.Compress, defaults)
+	var include, exclude stringList
+	fs.Var(&include, "include", tr("flag.include"))
+	fs.Var(&exclude, "exclude", tr("flag.exclude"))
+	fs.BoolVar(&options.Recursive, "recursive", false, tr("flag.recursive"))
+	fs.IntVar(&options.DebounceMs, "debounce", 1000, tr("flag.debounce"))
+	fs.StringVar(&options.Mode, "mode", "copy", tr("flag.mode"))
+	verbose := addLogFlag(fs)
+	fs.Parse(args)
+	initCLILogging(*verbose)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	options.Dir = fs.Arg(0)
+	options.Include = include
+	options.Exclude = exclude
+
+	w, err := newFolderWatcher(app, "cli", options, printCompressResult)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		return 1
+	}
+	defer w.stop()
+
+	fmt.Println(tr("cli.watching", options.Dir, options.Compress.OutputDir))
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	<-sig
+
+	info := w.info()
+	fmt.Println()
+	fmt.Println(tr("cli.watch_stopped", info.Processed, info.Failed))
+	return 0
+}
+
+// cliServe 启动本地 HTTP API（以及可选的 gRPC 接口），直到收到中断信号
+func cliServe(app *App, args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", tr("flag.addr"))
+	maxBody := fs.Int64("max-body", 100, tr("flag.max_body"))
+	concurrency := fs.Int("concurrency", runtime.NumCPU(), tr("flag.concurrency"))
+	keysPath := fs.String("keys", "", tr("flag.keys"))
+	grpcAddr := fs.String("grpc-addr", "", tr("flag.grpc_addr"))
+	proxyRoot := fs.String("root", "", tr("flag.proxy_root"))
+	verbose := addLogFlag(fs)
+	fs.Parse(args)
+	initCLILogging(*verbose)
+
+	if *keysPath == "" {
+		path, err := defaultAPIKeysPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			return 1
+		}
+		*keysPath = path
+	}
+	keys, err := loadAPIKeys(*keysPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		return 1
+	}
+
+	api := newAPIServer(app, *maxBody<<20, *concurrency)
+	api.tinify.setKeys(keys, *keysPath)
+	if *proxyRoot != "" {
+		if err := api.enableProxy(*proxyRoot); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			return 1
+		}
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           api,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s\n", tr("server.failed", err))
+		return 1
+	}
+	logger.Info("server started", "addr", ln.Addr().String())
+	fmt.Println(tr("server.listening", ln.Addr().String()))
+	if len(keys) > 0 {
+		fmt.Println(tr("tinify.keys_loaded", len(keys), *keysPath))
+	}
+	if *proxyRoot != "" {
+		fmt.Println(tr("proxy.enabled", *proxyRoot, ln.Addr().String()))
+	}
+
+	errc := make(chan error, 2)
+	var grpcSrv *grpc.Server
+	if *grpcAddr != "" {
+		grpcLn, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			ln.Close()
+			fmt.Fprintf(os.Stderr, "✗ %s\n", tr("server.failed", err))
+			return 1
+		}
+		grpcSrv = newGRPCServer(api)
+		go func() { errc <- grpcSrv.Serve(grpcLn) }()
+		logger.Info("grpc server started", "addr", grpcLn.Addr().String())
+		fmt.Println(tr("server.grpc_listening", grpcLn.Addr().String()))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(os.Stderr, "✗ %s\n", tr("server.failed", err))
+		return 1
+	case <-ctx.Done():
+	}
+
+	// 等待进行中的请求完成
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if grpcSrv != nil {
+		timer := time.AfterFunc(30*time.Second, grpcSrv.Stop)
+		grpcSrv.GracefulStop()
+		timer.Stop()
+	}
+	srv.Shutdown(shutdownCtx)
+	fmt.Println()
+	fmt.Println(tr("server.stopped"))
+	return 0
+}
@@ -2,14 +2,17 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
-	"fmt"
+	"errors"
 	"image"
 	"image/gif"
 	"image/jpeg"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/nfnt/resize"
 	"golang.org/x/image/tiff"
@@ -22,21 +25,18 @@ func (a *App) GetImageInfo(filePath string) ImageInfo {
 		Name: filepath.Base(filePath),
 	}
 
-	// 获取文件大小
-	stat, err := os.Stat(filePath)
-	if err != nil {
-		return info
-	}
-	info.Size = stat.Size()
-
 	// 读取并解码图片
 	data, err := os.ReadFile(filePath)
 	if err != nil {
+		err = newError(ErrRead, err, "err.open_file")
+		info.Code, info.Message = errorCode(err), err.Error()
 		return info
 	}
+	info.Size = int64(len(data))
 
 	img, format, err := decodeImage(data, filePath)
 	if err != nil {
+		info.Code, info.Message = errorCode(err), err.Error()
 		return info
 	}
 
@@ -46,19 +46,28 @@ func (a *App) GetImageInfo(filePath string) ImageInfo {
 	info.Format = format
 
 	// 生成预览缩略图
-	previewImg := resize.Thumbnail(200, 200, img, resize.Lanczos3)
-	var previewBuf bytes.Buffer
-	jpeg.Encode(&previewBuf, previewImg, &jpeg.Options{Quality: 80})
-	info.Preview = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(previewBuf.Bytes())
+	info.Preview, err = jpegPreview(img, 200, 80)
+	if err != nil {
+		info.Code, info.Message = errorCode(err), err.Error()
+	}
 
 	return info
 }
 
+// maxImagePixels 允许解码的最大像素数，防止超大图片（或伪造尺寸的文件）耗尽内存
+const maxImagePixels = 16384 * 16384
+
 // decodeImage 解码各种格式的图片
 func decodeImage(data []byte, filePath string) (image.Image, string, error) {
 	reader := bytes.NewReader(data)
 	ext := strings.ToLower(filepath.Ext(filePath))
 
+	// 解码前先检查尺寸
+	if config, _, err := image.DecodeConfig(reader); err == nil && config.Width*config.Height > maxImagePixels {
+		return nil, "", newError(ErrTooLarge, nil, "err.image_too_large", config.Width, config.Height)
+	}
+	reader.Seek(0, io.SeekStart)
+
 	// 先尝试标准解码
 	img, format, err := image.Decode(reader)
 	if err == nil {
@@ -66,7 +75,7 @@ func decodeImage(data []byte, filePath string) (image.Image, string, error) {
 	}
 
 	// 根据扩展名尝试特定格式
-	reader.Seek(0, 0)
+	reader.Seek(0, io.SeekStart)
 	switch ext {
 	case ".webp":
 		img, err = decodeWebp(data)
@@ -85,47 +94,385 @@ func decodeImage(data []byte, filePath string) (image.Image, string, error) {
 		}
 	}
 
-	return nil, "", fmt.Errorf("无法解码图片: %v", err)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, "", newError(ErrUnsupportedFormat, err, "err.unsupported_image")
+	}
+	return nil, "", newError(ErrDecode, err, "err.decode_image")
 }
 
 // CompressImage 压缩单张图片
 func (a *App) CompressImage(inputPath string, options CompressOptions) CompressResult {
+	ctx, done := a.beginJob(options.JobID)
+	defer done()
+	return a.compressFile(ctx, inputPath, options, true)
+}
+
+// compressFile 压缩单个文件并记录到历史，withPreview 控制是否生成 Base64 预览（批量处理时关闭）
+// 取消的操作没有产生输出，不记录到历史
+func (a *App) compressFile(ctx context.Context, inputPath string, options CompressOptions, withPreview bool) CompressResult {
+	start := time.Now()
+	result := processImage(ctx, inputPath, options, withPreview)
+	result.InputPath = inputPath
+	result.DurationMs = time.Since(start).Milliseconds()
+	logCompressResult(result)
+	if !result.Skipped && result.Code != CodeCanceled {
+		if err := recordCompressHistory(inputPath, options, result); err != nil {
+			logger.Warn("history write failed", "error", err)
+			result.Warnings = append(result.Warnings, tr("history.write_failed", err))
+		}
+	}
+	return result
+}
+
+// logCompressResult 记录单个文件的压缩结果
+func logCompressResult(r CompressResult) {
+	switch {
+	case !r.Success:
+		logger.Warn("compress failed", "path", r.InputPath, "code", r.Code, "error", r.Message, "duration_ms", r.DurationMs)
+	case r.Skipped:
+		logger.Info("compress skipped", "path", r.InputPath, "reason", r.Message)
+	default:
+		logger.Info("compress", "path", r.InputPath, "output", r.OutputPath,
+			"input_format", r.InputFormat, "output_format", r.OutputFormat, "quality", r.Quality,
+			"original_size", r.OriginalSize, "new_size", r.NewSize, "cached", r.Cached,
+			"warnings", len(r.Warnings), "duration_ms", r.DurationMs)
+	}
+}
+
+// processImage 压缩单个文件：读取、解码、缩放、编码、写入
+// 写入前检查 ctx，取消时不会留下输出文件
+func processImage(ctx context.Context, inputPath string, options CompressOptions, withPreview bool) CompressResult {
+	if err := checkCanceled(ctx); err != nil {
+		return failResult(err)
+	}
+
+	// 按项目配置文件（.squashrc / squash.toml）解析该文件的选项
+	var project projectResolution
+	if !options.IgnoreProjectConfig {
+		resolved, res, err := resolveProjectOptions(inputPath, options)
+		if err != nil {
+			return failResult(err)
+		}
+		if res.Skip {
+			return CompressResult{
+				Success:       true,
+				Skipped:       true,
+				Message:       tr("compress.excluded_by_project"),
+				ProjectConfig: res.ConfigPath,
+				AppliedRules:  res.Rules,
+			}
+		}
+		options = resolved
+		project = res
+	}
+
 	// 读取原始文件
 	originalData, err := os.ReadFile(inputPath)
 	if err != nil {
-		return CompressResult{Success: false, Message: fmt.Sprintf("无法打开文件: %v", err)}
+		return failResult(newError(ErrRead, err, "err.open_file"))
 	}
 	originalSize := int64(len(originalData))
+	inputHash := hashBytes(originalData)
+
+	// 跳过 Squash 生成的文件，避免重复有损压缩造成逐代画质损失
+	if !options.Force && isSquashOutput(inputHash) {
+		return CompressResult{
+			Success:       true,
+			Skipped:       true,
+			Message:       tr("compress.already_squashed"),
+			OriginalSize:  originalSize,
+			ProjectConfig: project.ConfigPath,
+			AppliedRules:  project.Rules,
+			InputHash:     inputHash,
+		}
+	}
+
+	// 相同内容和选项命中缓存时直接使用缓存的编码结果
+	cacheKey := compressCacheKey(inputHash, options)
+	var warnings []string
+	var img, resizedImg image.Image
+	enc, cached := encodedImage{}, false
+	if !options.Force {
+		enc, cached = loadCompressCache(cacheKey, originalData)
+	}
+	if cached {
+		// 原地优化不支持格式转换
+		if options.InPlace && !sameImageFormat(enc.Format, enc.InputFormat) {
+			return failResult(errInPlaceConvert())
+		}
+	} else {
+		enc, img, resizedImg, err = encodeWithOptions(ctx, originalData, inputPath, options)
+		if err != nil {
+			return failResult(err)
+		}
+		if err := saveCompressCache(cacheKey, enc); err != nil {
+			logger.Warn("cache write failed", "path", inputPath, "error", err)
+			warnings = append(warnings, tr("cache.write_failed", err))
+		}
+	}
+
+	compressedData := enc.Data
+	newSize := int64(len(compressedData))
+	useOriginal := enc.UseOriginal
+	originalWidth, originalHeight := enc.OriginalWidth, enc.OriginalHeight
+	newWidth, newHeight := enc.NewWidth, enc.NewHeight
+	outputFormat, mimeType := enc.Format, enc.MimeType
+
+	// 写入是最后一个检查点，写入之后的取消不再生效（输出已经完整）
+	if err := checkCanceled(ctx); err != nil {
+		return failResult(err)
+	}
+
+	var outputPath, backupPath string
+	writeStart := time.Now()
+	if options.InPlace {
+		// 原地优化：只有结果更小时才原子替换源文件，否则保持不动
+		outputPath = inputPath
+		if newSize >= originalSize {
+			compressedData = originalData
+			newSize = originalSize
+			useOriginal = true
+		} else {
+			backupPath, err = replaceFileInPlace(inputPath, compressedData, originalData, options.Backup)
+			if err != nil {
+				return failResult(withKind(ErrWrite, err, "err.save"))
+			}
+		}
+	} else {
+		// 生成输出文件名
+		baseName := filepath.Base(inputPath)
+		ext := filepath.Ext(baseName)
+		nameWithoutExt := strings.TrimSuffix(baseName, ext)
+
+		outputExt := outputExtension(outputFormat, ext)
+
+		outputDir := options.OutputDir
+		if outputDir == "" {
+			outputDir = filepath.Dir(inputPath)
+		}
+		nameTemplate := options.NameTemplate
+		if nameTemplate == "" {
+			nameTemplate = defaultNameTemplate
+			if sameFilePath(outputDir, filepath.Dir(inputPath)) {
+				nameTemplate = defaultSameDirNameTemplate
+			}
+		}
+		outputPath = filepath.Join(outputDir, renderNameTemplate(nameTemplate, nameVars{
+			Name:    nameWithoutExt,
+			Ext:     strings.TrimPrefix(outputExt, "."),
+			Width:   newWidth,
+			Height:  newHeight,
+			Quality: options.Quality,
+			Data:    compressedData,
+		}))
+
+		// 按冲突策略确定最终路径
+		var skip bool
+		outputPath, skip, err = resolveOutputPath(outputPath, inputPath, options.Collision)
+		if err != nil {
+			return failResult(err)
+		}
+		if skip {
+			return CompressResult{
+				Success:        true,
+				Skipped:        true,
+				Message:        tr("compress.target_exists_skipped"),
+				OriginalSize:   originalSize,
+				OutputPath:     outputPath,
+				OriginalWidth:  originalWidth,
+				OriginalHeight: originalHeight,
+			}
+		}
+
+		// 保存压缩后的文件
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return failResult(newError(ErrWrite, err, "err.create_output_dir"))
+		}
+		err = writeFileAtomic(outputPath, compressedData, 0644)
+		if err != nil {
+			return failResult(newError(ErrWrite, err, "err.save"))
+		}
+	}
+	if !useOriginal || !options.InPlace {
+		logger.Debug("write", "path", outputPath, "size", newSize, "backup", backupPath, since(writeStart))
+	}
+
+	compressionRatio := float64(originalSize-newSize) / float64(originalSize) * 100
+
+	// 生成预览图
+	originalBase64 := ""
+	compressedBase64 := ""
+
+	if withPreview {
+		// 文件已经写入，预览失败只作为警告
+		originalBase64, compressedBase64, err = compressPreviews(img, resizedImg, originalData, compressedData, inputPath, mimeType, useOriginal)
+		if err != nil {
+			warnings = append(warnings, tr("preview.failed_detail", err))
+		}
+	}
+
+	message := tr("compress.success")
+	if useOriginal {
+		message = tr("compress.kept_original")
+		warnings = append(warnings, tr("compress.warn_kept_original"))
+	}
+	if enc.Flattened != "" {
+		warnings = append(warnings, tr("compress.warn_alpha_flattened", enc.Flattened))
+	}
+	if len(enc.Candidates) > 1 {
+		message += tr("compress.best_format", enc.Format)
+	}
+	decision := describeFormatDecision(enc.Decision)
+	if decision != nil {
+		message += tr("compress.auto_format", enc.Format, decision.Reason)
+	}
+	if enc.Lossless {
+		message += tr("compress.lossless_suffix")
+	}
+	if cached {
+		message += tr("compress.cached_suffix")
+	}
+
+	return CompressResult{
+		Success:          true,
+		Message:          message,
+		OriginalSize:     originalSize,
+		NewSize:          newSize,
+		OutputPath:       outputPath,
+		OriginalBase64:   originalBase64,
+		CompressedBase64: compressedBase64,
+		OriginalWidth:    originalWidth,
+		OriginalHeight:   originalHeight,
+		NewWidth:         newWidth,
+		NewHeight:        newHeight,
+		CompressionRatio: compressionRatio,
+		BackupPath:       backupPath,
+		ProjectConfig:    project.ConfigPath,
+		AppliedRules:     project.Rules,
+		InputHash:        inputHash,
+		Cached:           cached,
+		InputFormat:      enc.InputFormat,
+		OutputFormat:     outputFormat,
+		Quality:          options.Quality,
+		Warnings:         warnings,
+		FormatCandidates: enc.Candidates,
+		FormatDecision:   decision,
+		Lossless:         enc.Lossless,
+	}
+}
+
+// compressBytes 压缩内存中的图片数据（HTTP 接口等不读写文件的场景），返回编码结果和是否命中缓存
+// 只使用影响编码结果的选项，输出目录、原地优化等写入相关的选项被忽略
+func compressBytes(ctx context.Context, data []byte, name string, options CompressOptions) (encodedImage, bool, error) {
+	options.InPlace = false
+	cacheKey := compressCacheKey(hashBytes(data), options)
+	if !options.Force {
+		if enc, ok := loadCompressCache(cacheKey, data); ok {
+			return enc, true, nil
+		}
+	}
+	enc, _, _, err := encodeWithOptions(ctx, data, name, options)
+	if err != nil {
+		return enc, false, err
+	}
+	if err := saveCompressCache(cacheKey, enc); err != nil {
+		logger.Warn("cache write failed", "name", name, "error", err)
+	}
+	return enc, false, nil
+}
+
+// compressPreviews 生成原图和压缩结果的 Base64 预览
+// img/resizedImg 为 nil 时（命中缓存）从数据重新解码
+func compressPreviews(img, resizedImg image.Image, originalData, compressedData []byte, inputPath, mimeType string, useOriginal bool) (string, string, error) {
+	var err error
+	if img == nil {
+		img, _, err = decodeImage(originalData, inputPath)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	// 生成原图预览
+	originalBase64, err := jpegPreview(img, 800, 85)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 生成压缩后预览
+	if useOriginal {
+		// 使用原文件，预览也用原图
+		return originalBase64, originalBase64, nil
+	}
+	if len(compressedData) < 500*1024 { // 小于500KB直接使用
+		return originalBase64, "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(compressedData), nil
+	}
+
+	// 大图片生成预览
+	if resizedImg == nil {
+		resizedImg, _, err = decodeImage(compressedData, "")
+		if err != nil {
+			return originalBase64, "", err
+		}
+	}
+	compressedBase64, err := jpegPreview(resizedImg, 800, 85)
+	return originalBase64, compressedBase64, err
+}
+
+// jpegPreview 生成不超过 maxSize 的 JPEG 缩略图（data URL）
+func jpegPreview(img image.Image, maxSize uint, quality int) (string, error) {
+	previewImg := resize.Thumbnail(maxSize, maxSize, img, resize.Lanczos3)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, previewImg, &jpeg.Options{Quality: quality}); err != nil {
+		return "", newError(ErrEncode, err, "preview.failed")
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// encodeWithOptions 解码、缩放并编码图片，返回编码结果以及解码后和缩放后的图片（用于生成预览）
+func encodeWithOptions(ctx context.Context, originalData []byte, inputPath string, options CompressOptions) (encodedImage, image.Image, image.Image, error) {
+	originalSize := int64(len(originalData))
+	if err := validateBackground(options.Background); err != nil {
+		return encodedImage{}, nil, nil, err
+	}
+	jo, err := parseJPEGOptions(options)
+	if err != nil {
+		return encodedImage{}, nil, nil, err
+	}
+	if err := validatePNGEffort(options.PNGEffort); err != nil {
+		return encodedImage{}, nil, nil, err
+	}
 
 	// 解码图片
+	start := time.Now()
 	img, format, err := decodeImage(originalData, inputPath)
 	if err != nil {
-		return CompressResult{Success: false, Message: fmt.Sprintf("无法解码图片: %v", err)}
+		return encodedImage{}, nil, nil, err
 	}
 
 	originalBounds := img.Bounds()
 	originalWidth := originalBounds.Dx()
 	originalHeight := originalBounds.Dy()
+	logger.Debug("decode", "path", inputPath, "format", format, "width", originalWidth, "height", originalHeight, since(start))
+	if err := checkCanceled(ctx); err != nil {
+		return encodedImage{}, nil, nil, err
+	}
 
 	// 调整尺寸
-	resizedImg := img
-	if options.MaxWidth > 0 || options.MaxHeight > 0 {
-		if options.KeepAspect {
-			resizedImg = resize.Thumbnail(options.MaxWidth, options.MaxHeight, img, resize.Lanczos3)
-		} else {
-			if options.MaxWidth > 0 && options.MaxHeight > 0 {
-				resizedImg = resize.Resize(options.MaxWidth, options.MaxHeight, img, resize.Lanczos3)
-			} else if options.MaxWidth > 0 {
-				resizedImg = resize.Resize(options.MaxWidth, 0, img, resize.Lanczos3)
-			} else {
-				resizedImg = resize.Resize(0, options.MaxHeight, img, resize.Lanczos3)
-			}
-		}
+	start = time.Now()
+	resizedImg, err := resizeImage(img, options.MaxWidth, options.MaxHeight, options.KeepAspect)
+	if err != nil {
+		return encodedImage{}, nil, nil, err
 	}
 
 	newBounds := resizedImg.Bounds()
 	newWidth := newBounds.Dx()
 	newHeight := newBounds.Dy()
+	if newWidth != originalWidth || newHeight != originalHeight {
+		logger.Debug("resize", "path", inputPath, "width", newWidth, "height", newHeight, since(start))
+		if err := checkCanceled(ctx); err != nil {
+			return encodedImage{}, nil, nil, err
+		}
+	}
 
 	// 确定输出格式
 	outputFormat := options.OutputFormat
@@ -146,61 +493,82 @@ func (a *App) CompressImage(inputPath string, options CompressOptions) CompressR
 		// }
 	}
 
-	// 调试日志
-	fmt.Printf("[DEBUG] 输入格式: %s, 选项格式: %s, 输出格式: %s\n", format, options.OutputFormat, outputFormat)
+	auto := outputFormat == "auto"
+	if auto && options.InPlace {
+		outputFormat, auto = format, false // 原地优化不能转换格式，保持原格式
+	}
 
-	// 生成输出文件名
-	baseName := filepath.Base(inputPath)
-	ext := filepath.Ext(baseName)
-	nameWithoutExt := strings.TrimSuffix(baseName, ext)
+	best := outputFormat == "best"
+	accept := options.AcceptFormats
+	if best && options.InPlace {
+		// 原地优化不能转换格式，只能与原格式比较
+		accept = []string{format}
+	}
 
-	var outputExt string
-	switch outputFormat {
-	case "jpeg", "jpg":
-		outputExt = ".jpg"
-	case "png":
-		outputExt = ".png"
-	case "webp":
-		outputExt = ".webp"
-	case "gif":
-		outputExt = ".gif"
-	default:
-		outputExt = ext
+	if !best && !auto && !isOutputFormat(outputFormat) && outputFormat != format {
+		return encodedImage{}, nil, nil, newError(ErrUnsupportedFormat, nil, "err.unsupported_output", outputFormat)
+	}
+
+	// 原地优化不支持格式转换
+	if !best && !auto && options.InPlace && !sameImageFormat(outputFormat, format) {
+		return encodedImage{}, nil, nil, errInPlaceConvert()
+	}
+
+	// 输出格式不支持透明通道时先合成到背景色上，否则透明区域会变成黑色
+	opaque := isOpaque(resizedImg)
+	background := backgroundColor(options.Background, resizedImg)
+	flattened := ""
+	if !best && !auto && !opaque && !formatSupportsAlpha(outputFormat) {
+		resizedImg = flattenAlpha(resizedImg, background)
+		flattened = hexColor(background)
 	}
 
-	outputPath := filepath.Join(options.OutputDir, nameWithoutExt+outputExt)
+	// JPEG 无损优化：JPEG 输出且没有缩放时直接重写原文件的 DCT 系数，不重新编码
+	lossless := options.JPEGLossless && !best && !auto && format == "jpeg" && sameImageFormat(outputFormat, format) &&
+		newWidth == originalWidth && newHeight == originalHeight
 
 	// 压缩图片
-	var buf bytes.Buffer
+	start = time.Now()
+	var compressedData []byte
 	var mimeType string
-
-	switch outputFormat {
-	case "jpeg", "jpg":
-		err = jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: options.Quality})
+	var candidates []FormatCandidate
+	var decision *FormatDecision
+	switch {
+	case best:
+		outputFormat, compressedData, mimeType, candidates, err = encodeBestFormat(ctx, resizedImg, format, accept, options.Quality, background, jo, options.PNGEffort)
+		if err == nil && !opaque && !formatSupportsAlpha(outputFormat) {
+			flattened = hexColor(background)
+		}
+	case auto:
+		decision, compressedData, mimeType, err = encodeAutoFormat(ctx, resizedImg, options.Quality, jo, options.PNGEffort)
+		if err == nil {
+			outputFormat = decision.Format
+		}
+	case lossless:
 		mimeType = "image/jpeg"
-	case "png":
-		// 使用类似 TinyPNG 的量化压缩
-		pngData, _ := compressPNGLikeTinyPNG(resizedImg, options.Quality)
-		buf.Write(pngData)
-		mimeType = "image/png"
-	case "webp":
-		err = encodeWebp(&buf, resizedImg, options.Quality)
-		mimeType = "image/webp"
-	case "gif":
-		err = gif.Encode(&buf, resizedImg, nil)
-		mimeType = "image/gif"
+		compressedData, err = optimizeJPEGLossless(ctx, originalData)
+		if err != nil {
+			if cerr := checkCanceled(ctx); cerr != nil {
+				return encodedImage{}, nil, nil, cerr
+			}
+			// 不支持的 JPEG（算术编码、12 位等）保持原文件，不退回有损的重新编码
+			logger.Warn("jpeg lossless failed", "path", inputPath, "error", err)
+			compressedData, err = originalData, nil
+		}
 	default:
-		// 默认使用 JPEG
-		err = jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: options.Quality})
-		mimeType = "image/jpeg"
+		compressedData, mimeType, err = encodeImageWith(ctx, resizedImg, outputFormat, options.Quality, jo, options.PNGEffort)
 	}
-
 	if err != nil {
-		return CompressResult{Success: false, Message: fmt.Sprintf("压缩失败: %v", err)}
+		return encodedImage{}, nil, nil, withKind(ErrEncode, err, "err.compress")
+	}
+	// PNG 优化时保留原图中影响颜色显示的辅助块，其余辅助块去掉
+	if options.PNGEffort > PNGEffortOff && format == "png" && outputFormat == "png" {
+		compressedData = insertPNGChunks(compressedData, pngColorChunks(originalData))
 	}
+	logger.Debug("encode", "path", inputPath, "format", outputFormat, "requested", options.OutputFormat,
+		"quality", options.Quality, "lossless", lossless, "size", len(compressedData), since(start))
 
 	// 智能判断：如果压缩后更大且没有改变尺寸，使用原文件
-	compressedData := buf.Bytes()
 	newSize := int64(len(compressedData))
 
 	// 检查是否尺寸未变（没有缩放）
@@ -208,69 +576,188 @@ func (a *App) CompressImage(inputPath string, options CompressOptions) CompressR
 		(newWidth == originalWidth && newHeight == originalHeight)
 
 	// 如果格式相同、尺寸未变、且压缩后更大，使用原文件
-	sameFormat := (outputFormat == format) ||
-		(outputFormat == "jpeg" && format == "jpg") ||
-		(outputFormat == "jpg" && format == "jpeg")
+	// "best" 时只要原格式可以接受，原文件也参与比较
+	sameFormat := sameImageFormat(outputFormat, format) || (best && acceptsFormat(accept, format))
 
 	useOriginal := false
 	if sameFormat && sizeUnchanged && newSize >= originalSize {
 		// 压缩后反而更大，直接复制原文件
 		compressedData = originalData
-		newSize = originalSize
 		useOriginal = true
+		if best {
+			outputFormat, mimeType = format, formatMimeType(format)
+		}
+		flattened = ""
+		lossless = false
 	}
 
-	// 保存压缩后的文件
-	err = os.WriteFile(outputPath, compressedData, 0644)
-	if err != nil {
-		return CompressResult{Success: false, Message: fmt.Sprintf("保存失败: %v", err)}
+	return encodedImage{
+		Data:           compressedData,
+		InputFormat:    format,
+		Format:         outputFormat,
+		MimeType:       mimeType,
+		OriginalWidth:  originalWidth,
+		OriginalHeight: originalHeight,
+		NewWidth:       newWidth,
+		NewHeight:      newHeight,
+		UseOriginal:    useOriginal,
+		Candidates:     candidates,
+		Decision:       decision,
+		Flattened:      flattened,
+		Lossless:       lossless,
+	}, img, resizedImg, nil
+}
+
+// resizeImage 按最大宽高调整尺寸，maxWidth/maxHeight 为 0 表示不限制
+// 不保持宽高比或只指定一边时会放大，输出超过 maxImagePixels 时返回 ErrTooLarge（不分配内存）
+func resizeImage(img image.Image, maxWidth, maxHeight uint, keepAspect bool) (image.Image, error) {
+	if maxWidth == 0 && maxHeight == 0 {
+		return img, nil
+	}
+	if !keepAspect {
+		b := img.Bounds()
+		w, h := float64(maxWidth), float64(maxHeight)
+		switch {
+		case maxHeight == 0 && b.Dx() > 0:
+			h = w * float64(b.Dy()) / float64(b.Dx())
+		case maxWidth == 0 && b.Dy() > 0:
+			w = h * float64(b.Dx()) / float64(b.Dy())
+		}
+		if err := checkResizeSize(w, h); err != nil {
+			return nil, err
+		}
+	}
+	if keepAspect {
+		// Thumbnail 把 0 当作上限 0，未限制的一边使用原图尺寸
+		b := img.Bounds()
+		if maxWidth == 0 {
+			maxWidth = uint(b.Dx())
+		}
+		if maxHeight == 0 {
+			maxHeight = uint(b.Dy())
+		}
+		return resize.Thumbnail(maxWidth, maxHeight, img, resize.Lanczos3), nil
+	}
+	if maxWidth > 0 && maxHeight > 0 {
+		return resize.Resize(maxWidth, maxHeight, img, resize.Lanczos3), nil
+	} else if maxWidth > 0 {
+		return resize.Resize(maxWidth, 0, img, resize.Lanczos3), nil
 	}
+	return resize.Resize(0, maxHeight, img, resize.Lanczos3), nil
+}
 
-	compressionRatio := float64(originalSize-newSize) / float64(originalSize) * 100
+// checkResizeSize 检查缩放（可能放大）后的尺寸，超过 maxImagePixels 时返回 ErrTooLarge
+func checkResizeSize(width, height float64) error {
+	if width*height > maxImagePixels {
+		return newError(ErrTooLarge, nil, "err.resize_too_large", int64(width), int64(height), maxImagePixels)
+	}
+	return nil
+}
 
-	// 生成预览图
-	originalBase64 := ""
-	compressedBase64 := ""
+// outputExtension 返回输出格式对应的扩展名，未知格式使用 fallback
+func outputExtension(format string, fallback string) string {
+	switch format {
+	case "jpeg", "jpg":
+		return ".jpg"
+	case "png":
+		return ".png"
+	case "webp":
+		return ".webp"
+	case "avif":
+		return ".avif"
+	case "gif":
+		return ".gif"
+	default:
+		return fallback
+	}
+}
 
-	// 生成原图预览
-	previewImg := resize.Thumbnail(800, 800, img, resize.Lanczos3)
-	var previewBuf bytes.Buffer
-	jpeg.Encode(&previewBuf, previewImg, &jpeg.Options{Quality: 85})
-	originalBase64 = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(previewBuf.Bytes())
+// sameImageFormat 判断两个格式名是否表示同一格式（jpg 与 jpeg 视为相同）
+func sameImageFormat(a, b string) bool {
+	if a == "jpg" {
+		a = "jpeg"
+	}
+	if b == "jpg" {
+		b = "jpeg"
+	}
+	return a == b
+}
 
-	// 生成压缩后预览
-	if useOriginal {
-		// 使用原文件，预览也用原图
-		compressedBase64 = originalBase64
-	} else if newSize < 500*1024 { // 小于500KB直接使用
-		compressedBase64 = "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(compressedData)
-	} else {
-		// 大图片生成预览
-		compressedPreview := resize.Thumbnail(800, 800, resizedImg, resize.Lanczos3)
-		var compressedPreviewBuf bytes.Buffer
-		jpeg.Encode(&compressedPreviewBuf, compressedPreview, &jpeg.Options{Quality: 85})
-		compressedBase64 = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(compressedPreviewBuf.Bytes())
+// encodeImage 按输出格式编码图片，返回编码后的数据和 MIME 类型，JPEG 和 PNG 使用默认的编码选项
+func encodeImage(ctx context.Context, img image.Image, format string, quality int) ([]byte, string, error) {
+	return encodeImageWith(ctx, img, format, quality, defaultJPEGOptions, PNGEffortOff)
+}
+
+// encodeImageWith 按输出格式、JPEG 编码选项和 PNG 优化级别编码图片
+// 第三方编码器无法中途停止，JPEG 编码和 PNG 量化、优化在逐行处理时检查 ctx
+func encodeImageWith(ctx context.Context, img image.Image, format string, quality int, jo jpegOptions, pngEffort int) ([]byte, string, error) {
+	var buf bytes.Buffer
+	var mimeType string
+	var err error
+
+	switch format {
+	case "jpeg", "jpg":
+		if !isOpaque(img) {
+			img = flattenAlpha(img, defaultBackground) // JPEG 没有透明通道，透明区域合成到白色上
+		}
+		err = encodeJPEG(ctx, &buf, img, quality, jo)
+		mimeType = "image/jpeg"
+	case "png":
+		// 使用类似 TinyPNG 的量化压缩
+		var pngData []byte
+		pngData, _, err = compressPNGLikeTinyPNG(ctx, img, quality, pngEffort)
+		buf.Write(pngData)
+		mimeType = "image/png"
+	case "webp":
+		err = encodeWebp(&buf, img, quality)
+		mimeType = "image/webp"
+	case "avif":
+		err = encodeAvif(&buf, img, quality)
+		mimeType = "image/avif"
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+		mimeType = "image/gif"
+	default:
+		// 默认使用 JPEG
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+		mimeType = "image/jpeg"
 	}
 
-	message := "压缩成功"
-	if useOriginal {
-		message = "已保持原文件（压缩后更大）"
+	if err != nil {
+		return nil, "", err
 	}
+	if err := checkCanceled(ctx); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mimeType, nil
+}
 
-	return CompressResult{
-		Success:          true,
-		Message:          message,
-		OriginalSize:     originalSize,
-		NewSize:          newSize,
-		OutputPath:       outputPath,
-		OriginalBase64:   originalBase64,
-		CompressedBase64: compressedBase64,
-		OriginalWidth:    originalWidth,
-		OriginalHeight:   originalHeight,
-		NewWidth:         newWidth,
-		NewHeight:        newHeight,
-		CompressionRatio: compressionRatio,
+// errInPlaceConvert 原地优化时请求了格式转换（消息按当前语言生成）
+func errInPlaceConvert() error {
+	return newError(ErrInvalidOptions, nil, "err.in_place_convert")
+}
+
+// isOutputFormat 判断是否为可编码的输出格式
+func isOutputFormat(format string) bool {
+	switch format {
+	case "jpeg", "jpg", "png", "webp", "avif", "gif":
+		return true
 	}
+	return false
+}
+
+// inputFormats 支持读取的文件扩展名（不含点）
+var inputFormats = []string{"jpg", "jpeg", "png", "gif", "webp", "avif", "tiff", "tif", "bmp"}
+
+// isSupportedImage 按扩展名判断文件是否为支持的图片
+func isSupportedImage(path string) bool {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, f := range inputFormats {
+		if ext == f {
+			return true
+		}
+	}
+	return false
 }
 
 // GetSupportedFormats 获取支持的格式列表
@@ -279,8 +766,11 @@ func (a *App) GetSupportedFormats() map[string][]string {
 	if webpSupported() {
 		outputFormats = append(outputFormats, "webp")
 	}
+	if avifSupported() {
+		outputFormats = append(outputFormats, "avif")
+	}
 	return map[string][]string{
-		"input":  {"jpg", "jpeg", "png", "gif", "webp", "tiff", "tif", "bmp"},
+		"input":  inputFormats,
 		"output": outputFormats,
 	}
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// 放大后超过 maxImagePixels 时在分配内存前返回 ErrTooLarge，保持宽高比时不放大
+func TestResizeImageLimit(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 20))
+	cases := []struct {
+		width, height uint
+		keepAspect    bool
+		want          image.Point // 零值表示 ErrTooLarge
+	}{
+		{0, 0, false, image.Pt(10, 20)},
+		{30, 40, false, image.Pt(30, 40)},
+		{30, 0, false, image.Pt(30, 60)},
+		{0, 40, false, image.Pt(20, 40)},
+		{60000, 60000, false, image.Point{}},
+		{20000, 0, false, image.Point{}},
+		{0, 30000, false, image.Point{}},
+		{60000, 60000, true, image.Pt(10, 20)},
+		{5, 0, true, image.Pt(5, 10)},
+	}
+	for _, c := range cases {
+		got, err := resizeImage(img, c.width, c.height, c.keepAspect)
+		if c.want == (image.Point{}) {
+			if !errors.Is(err, ErrTooLarge) {
+				t.Errorf("%dx%d keepAspect=%v: want ErrTooLarge, got %v", c.width, c.height, c.keepAspect, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%dx%d keepAspect=%v: %v", c.width, c.height, c.keepAspect, err)
+			continue
+		}
+		if got.Bounds().Size() != c.want {
+			t.Errorf("%dx%d keepAspect=%v: got %v, want %v", c.width, c.height, c.keepAspect, got.Bounds().Size(), c.want)
+		}
+	}
+}
+
+// countdownContext 前 n 次调用 Err 返回 nil，之后一直返回 context.Canceled，用来在每个检查点依次取消
+type countdownContext struct {
+	context.Context
+	n atomic.Int64
+}
+
+func (c *countdownContext) Err() error {
+	if c.n.Add(-1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+// 在每个检查点（解码、编码、无损重写、PNG 优化）取消时都返回 canceled 错误码，不写入输出文件
+func TestCompressImageCanceled(t *testing.T) {
+	testDataDirs(t)
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "photo.jpg")
+	testJPEGFile(t, photo, testPhoto(64, 48))
+	icon := filepath.Join(dir, "icon.png")
+	f, err := os.Create(icon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, testPhoto(48, 32)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cases := []struct {
+		name, input string
+		options     CompressOptions
+	}{
+		{"jpeg", photo, CompressOptions{OutputFormat: "jpeg", JPEGProgressive: true, JPEGQuantTable: QuantTableMozjpeg}},
+		{"jpegLossless", photo, CompressOptions{OutputFormat: "original", JPEGLossless: true}},
+		{"png", icon, CompressOptions{OutputFormat: "original", Quality: 100, PNGEffort: PNGEffortMax}},
+	}
+	a := NewApp()
+	for _, c := range cases {
+		c.options.OutputDir = filepath.Join(dir, c.name)
+		c.options.KeepAspect, c.options.Force = true, true
+		if c.options.Quality == 0 {
+			c.options.Quality = 80
+		}
+		canceled := 0
+		for n := int64(0); ; n++ {
+			ctx := &countdownContext{Context: context.Background()}
+			ctx.n.Store(n)
+			r := a.compressFile(ctx, c.input, c.options, false)
+			if r.Success {
+				break
+			}
+			if r.Code != CodeCanceled {
+				t.Fatalf("%s: cancel after %d checks: %s %s", c.name, n, r.Code, r.Message)
+			}
+			if _, err := os.Stat(c.options.OutputDir); !os.IsNotExist(err) {
+				t.Fatalf("%s: cancel after %d checks left output: %v", c.name, n, err)
+			}
+			canceled++
+		}
+		if canceled < 3 {
+			t.Errorf("%s: only %d cancellation points", c.name, canceled)
+		}
+	}
+}
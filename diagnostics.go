@@ -0,0 +1,122 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// diagnosticsHistoryLimit 诊断包中包含的最近历史记录条数
+const diagnosticsHistoryLimit = 200
+
+// diagnosticsInfo 诊断包中的环境信息
+type diagnosticsInfo struct {
+	Version    string              `json:"version"`
+	GoVersion  string              `json:"goVersion"`
+	OS         string              `json:"os"`
+	Arch       string              `json:"arch"`
+	NumCPU     int                 `json:"numCPU"`
+	Locale     string              `json:"locale"`
+	LogLevel   string              `json:"logLevel"`
+	Formats    map[string][]string `json:"formats"`
+	ExportedAt string              `json:"exportedAt"`
+}
+
+// ExportDiagnostics 选择保存位置并导出诊断包（zip），用于提交问题报告
+// 包含日志文件、版本与平台信息、支持的格式和最近的历史记录
+func (a *App) ExportDiagnostics() ActionResult {
+	path, err := wailsruntime.SaveFileDialog(a.ctx, wailsruntime.SaveDialogOptions{
+		Title:           tr("dialog.export_diagnostics"),
+		DefaultFilename: "squash-diagnostics-" + time.Now().Format("20060102-150405") + ".zip",
+		Filters: []wailsruntime.FileFilter{
+			{DisplayName: tr("filter.zip"), Pattern: "*.zip"},
+		},
+	})
+	if err != nil || path == "" {
+		return ActionResult{Success: false, Message: tr("dialog.canceled")}
+	}
+	if err := a.writeDiagnostics(path); err != nil {
+		logger.Error("export diagnostics failed", "path", path, "error", err)
+		return ActionResult{Success: false, Message: tr("diag.export_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("diag.exported", path)}
+}
+
+// writeDiagnostics 将诊断信息写入 zip 文件
+func (a *App) writeDiagnostics(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	zw := zip.NewWriter(tmp)
+	info := diagnosticsInfo{
+		Version:    appVersion,
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		NumCPU:     runtime.NumCPU(),
+		Locale:     currentLocale(),
+		LogLevel:   a.GetLogLevel(),
+		Formats:    a.GetSupportedFormats(),
+		ExportedAt: time.Now().Format(time.RFC3339),
+	}
+	if err := writeZipJSON(zw, "info.json", info); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writeZipJSON(zw, "history.json", a.GetHistory(diagnosticsHistoryLimit)); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	// 日志文件（当前文件和轮转出的旧文件）
+	if dir, err := logDir(); err == nil {
+		logs, _ := filepath.Glob(filepath.Join(dir, logFileName+"*"))
+		for _, logPath := range logs {
+			data, err := os.ReadFile(logPath)
+			if err != nil {
+				continue
+			}
+			if err := writeZipFile(zw, "logs/"+filepath.Base(logPath), data); err != nil {
+				tmp.Close()
+				return err
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// writeZipJSON 以缩进 JSON 写入 zip 条目
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeZipFile(zw, name, append(data, '\n'))
+}
+
+// writeZipFile 写入 zip 条目
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemFileNames 常见的系统文件/目录，遍历时跳过
+var systemFileNames = map[string]bool{
+	"thumbs.db":                 true,
+	"desktop.ini":               true,
+	"__macosx":                  true,
+	"$recycle.bin":              true,
+	"system volume information": true,
+}
+
+// isHiddenOrSystem 判断文件或目录是否为隐藏/系统文件
+func isHiddenOrSystem(name string) bool {
+	if strings.HasPrefix(name, ".") && name != "." && name != ".." {
+		return true
+	}
+	return systemFileNames[strings.ToLower(name)]
+}
+
+// collectDirectoryImages 递归收集根目录下符合过滤条件的图片，返回相对路径
+func collectDirectoryImages(root string, options DirectoryOptions, skipDir string) ([]string, error) {
+	var files []string
+	writesMinified := options.Compress.OutputDir == "" && options.Compress.NameTemplate == ""
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if !options.IncludeHidden && isHiddenOrSystem(d.Name()) {
+				return filepath.SkipDir
+			}
+			// 输出目录位于源目录内部时不再处理，避免重复压缩
+			if skipDir != "" && sameFilePath(path, skipDir) {
+				return filepath.SkipDir
+			}
+			if matchAnyGlob(options.Exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if !options.IncludeHidden && isHiddenOrSystem(d.Name()) {
+			return nil
+		}
+		if len(options.Include) > 0 {
+			if !matchAnyGlob(options.Include, rel) {
+				return nil
+			}
+		} else if !isSupportedImage(path) {
+			return nil
+		}
+		if matchAnyGlob(options.Exclude, rel) {
+			return nil
+		}
+		// 写回源目录且使用默认模板时，跳过上次生成的 .min 文件
+		if writesMinified && isMinifiedName(d.Name()) {
+			return nil
+		}
+
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+// CompressDirectory 递归压缩文件夹，并在输出目录下保持原有目录结构
+func (a *App) CompressDirectory(root string, options DirectoryOptions) DirectoryResult {
+	ctx, done := a.beginJob(options.JobID)
+	defer done()
+	return a.compressDirectory(ctx, root, options)
+}
+
+// compressDirectory 逐个压缩文件夹中的图片，取消后剩余文件标记为已取消，不再处理
+func (a *App) compressDirectory(ctx context.Context, root string, options DirectoryOptions) DirectoryResult {
+	stat, err := os.Stat(root)
+	if err != nil {
+		return DirectoryResult{Success: false, Message: tr("err.open_folder_detail", err)}
+	}
+	if !stat.IsDir() {
+		return DirectoryResult{Success: false, Message: tr("err.not_folder", root)}
+	}
+
+	outputRoot := options.Compress.OutputDir
+	files, err := collectDirectoryImages(root, options, outputRoot)
+	if err != nil {
+		return DirectoryResult{Success: false, Message: tr("directory.walk_failed", err)}
+	}
+
+	result := DirectoryResult{
+		Root:      root,
+		OutputDir: outputRoot,
+		Total:     len(files),
+		Files:     make([]DirectoryFileResult, 0, len(files)),
+	}
+
+	for i, rel := range files {
+		if err := checkCanceled(ctx); err != nil {
+			r := failResult(err)
+			r.InputPath = filepath.Join(root, rel)
+			result.Canceled++
+			result.Files = append(result.Files, DirectoryFileResult{RelPath: filepath.ToSlash(rel), Result: r})
+			continue
+		}
+
+		a.emit("directory-progress", map[string]interface{}{
+			"current":  i + 1,
+			"total":    len(files),
+			"path":     rel,
+			"progress": i * 100 / len(files),
+		})
+		reportProgress(ctx, i*100/len(files), rel)
+
+		// 镜像相对目录：输出目录为空时写回源文件所在目录
+		fileOptions := options.Compress
+		if outputRoot != "" {
+			fileOptions.OutputDir = filepath.Join(outputRoot, filepath.Dir(rel))
+		}
+
+		r := a.compressFile(ctx, filepath.Join(root, rel), fileOptions, false)
+		switch {
+		case r.Code == CodeCanceled:
+			result.Canceled++
+		case !r.Success:
+			result.Failed++
+		case r.Skipped:
+			result.Skipped++
+		default:
+			result.Succeeded++
+			result.OriginalSize += r.OriginalSize
+			result.NewSize += r.NewSize
+		}
+		result.Files = append(result.Files, DirectoryFileResult{RelPath: filepath.ToSlash(rel), Result: r})
+	}
+
+	a.emit("directory-progress", map[string]interface{}{
+		"current":  len(files),
+		"total":    len(files),
+		"progress": 100,
+	})
+
+	result.Success = result.Failed == 0 && result.Canceled == 0
+	result.Message = tr("directory.summary",
+		result.Total, result.Succeeded, result.Skipped, result.Failed,
+		formatFileSize(result.OriginalSize), formatFileSize(result.NewSize))
+	if result.Canceled > 0 {
+		result.Message += tr("directory.canceled", result.Canceled)
+	}
+
+	if len(options.Reports) > 0 {
+		report := newBatchReport(directoryResults(result))
+		for _, path := range options.Reports {
+			if err := writeBatchReport(path, report); err != nil {
+				result.Success = false
+				result.Message += tr("directory.report_failed", err)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 引擎中的错误类别，可用 errors.Is 判断
+var (
+	ErrRead              = errors.New("read failed")
+	ErrDecode            = errors.New("decode failed")
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrEncode            = errors.New("encode failed")
+	ErrWrite             = errors.New("write failed")
+	ErrTooLarge          = errors.New("image too large")
+	ErrInvalidOptions    = errors.New("invalid options")
+	ErrCanceled          = errors.New("canceled")
+)
+
+// 结果中的错误码，与错误类别一一对应，供脚本判断失败原因（不随界面语言变化）
+const (
+	CodeRead              = "read_failed"
+	CodeDecode            = "decode_failed"
+	CodeUnsupportedFormat = "unsupported_format"
+	CodeEncode            = "encode_failed"
+	CodeWrite             = "write_failed"
+	CodeTooLarge          = "too_large"
+	CodeInvalidOptions    = "invalid_options"
+	CodeCanceled          = "canceled"
+	CodeInternal          = "internal"
+)
+
+// errorCodes 错误类别到错误码的映射
+var errorCodes = []struct {
+	kind error
+	code string
+}{
+	{ErrCanceled, CodeCanceled},
+	{ErrTooLarge, CodeTooLarge},
+	{ErrUnsupportedFormat, CodeUnsupportedFormat},
+	{ErrDecode, CodeDecode},
+	{ErrEncode, CodeEncode},
+	{ErrRead, CodeRead},
+	{ErrWrite, CodeWrite},
+	{ErrInvalidOptions, CodeInvalidOptions},
+}
+
+// opError 带类别的错误：Error 返回给用户看的消息，errors.Is 匹配类别，Unwrap 返回底层错误
+type opError struct {
+	kind error
+	msg  string
+	err  error
+}
+
+func (e *opError) Error() string {
+	if e.err == nil {
+		return e.msg
+	}
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *opError) Is(target error) bool { return target == e.kind }
+
+func (e *opError) Unwrap() error { return e.err }
+
+// newError 创建指定类别的错误，消息按当前语言由 msgID 生成，cause 可以为 nil
+func newError(kind error, cause error, msgID string, args ...interface{}) error {
+	return &opError{kind: kind, msg: tr(msgID, args...), err: cause}
+}
+
+// withKind 为尚未归类的错误指定类别，已归类的错误保持原样
+func withKind(kind error, err error, msgID string) error {
+	var op *opError
+	if errors.As(err, &op) {
+		return err
+	}
+	return &opError{kind: kind, msg: tr(msgID), err: err}
+}
+
+// errorCode 返回错误对应的错误码，无法归类时为 "internal"
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.kind) {
+			return c.code
+		}
+	}
+	return CodeInternal
+}
+
+// failResult 由错误生成失败的压缩结果
+func failResult(err error) CompressResult {
+	return CompressResult{Success: false, Code: errorCode(err), Message: err.Error()}
+}
+
+// gifFailResult 由错误生成失败的 GIF 结果
+func gifFailResult(err error) GifResult {
+	return GifResult{Success: false, Code: errorCode(err), Message: err.Error()}
+}
+
+// responsiveFailResult 由错误生成失败的响应式图片集结果
+func responsiveFailResult(err error) ResponsiveResult {
+	return ResponsiveResult{Success: false, Code: errorCode(err), Message: err.Error()}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// 可保留的元数据类别（与 TinyPNG 的 preserve 选项相同）
+const (
+	PreserveCopyright = "copyright" // 版权信息
+	PreserveCreation  = "creation"  // 拍摄和修改时间
+	PreserveLocation  = "location"  // GPS 位置
+
+	// preserveOrientation 图片方向，影响显示效果，JPEG 无损优化时保留（不对外提供）
+	preserveOrientation = "orientation"
+)
+
+// 保留元数据时使用的 EXIF 标签
+const (
+	exifTagOrientation       = 0x0112
+	exifTagDateTime          = 0x0132
+	exifTagCopyright         = 0x8298
+	exifTagExifIFD           = 0x8769
+	exifTagGPSIFD            = 0x8825
+	exifTagDateTimeOriginal  = 0x9003
+	exifTagDateTimeDigitized = 0x9004
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// byteOrder TIFF 数据的字节序，读写都按原数据的字节序进行
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// exifTypeSizes EXIF 各数据类型的字节数，下标为类型编号
+var exifTypeSizes = [...]uint32{0, 1, 1, 2, 4, 8, 1, 1, 2, 4, 8, 4, 8}
+
+// exifEntry IFD 中的一项，value 为按原字节序保存的原始数据
+type exifEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// jpegExif 返回 JPEG 中 APP1 段的 EXIF 数据（含 "Exif\0\0" 头），没有时返回 nil
+func jpegExif(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return nil
+		}
+		marker := data[i+1]
+		// 扫描数据开始或图片结束后不再有元数据
+		if marker == 0xDA || marker == 0xD9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			return segment
+		}
+		i = end
+	}
+	return nil
+}
+
+// filterExif 从 EXIF 数据中只保留指定类别的标签，重新生成 EXIF 数据，没有可保留的标签时返回 nil
+func filterExif(exif []byte, preserve []string) ([]byte, error) {
+	tiff := bytes.TrimPrefix(exif, exifHeader)
+	if len(tiff) < 8 {
+		return nil, errors.New("exif: short header")
+	}
+	var bo byteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return nil, errors.New("exif: bad byte order")
+	}
+
+	ifd0, err := readIFD(tiff, bo, bo.Uint32(tiff[4:]))
+	if err != nil {
+		return nil, err
+	}
+	var subIFD, gpsIFD []exifEntry
+	for _, e := range ifd0 {
+		switch e.tag {
+		case exifTagExifIFD:
+			subIFD, err = readIFD(tiff, bo, bo.Uint32(e.value))
+		case exifTagGPSIFD:
+			gpsIFD, err = readIFD(tiff, bo, bo.Uint32(e.value))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var keep0, keepSub, keepGPS []exifEntry
+	for _, p := range preserve {
+		switch p {
+		case PreserveCopyright:
+			keep0 = append(keep0, pickEntries(ifd0, exifTagCopyright)...)
+		case PreserveCreation:
+			keep0 = append(keep0, pickEntries(ifd0, exifTagDateTime)...)
+			keepSub = append(keepSub, pickEntries(subIFD, exifTagDateTimeOriginal, exifTagDateTimeDigitized)...)
+		case PreserveLocation:
+			keepGPS = gpsIFD
+		case preserveOrientation:
+			keep0 = append(keep0, pickEntries(ifd0, exifTagOrientation)...)
+		}
+	}
+	if len(keep0)+len(keepSub)+len(keepGPS) == 0 {
+		return nil, nil
+	}
+	return append(append([]byte{}, exifHeader...), buildTIFF(bo, keep0, keepSub, keepGPS)...), nil
+}
+
+// readIFD 读取 offset 处的 IFD，忽略无法识别类型的项
+func readIFD(tiff []byte, bo byteOrder, offset uint32) ([]exifEntry, error) {
+	if uint64(offset)+2 > uint64(len(tiff)) {
+		return nil, errors.New("exif: bad IFD offset")
+	}
+	n := int(bo.Uint16(tiff[offset:]))
+	start := int(offset) + 2
+	if start+n*12 > len(tiff) {
+		return nil, errors.New("exif: truncated IFD")
+	}
+
+	entries := make([]exifEntry, 0, n)
+	for i := 0; i < n; i++ {
+		raw := tiff[start+i*12 : start+i*12+12]
+		e := exifEntry{tag: bo.Uint16(raw), typ: bo.Uint16(raw[2:]), count: bo.Uint32(raw[4:])}
+		if int(e.typ) >= len(exifTypeSizes) || e.typ == 0 {
+			continue
+		}
+		size := uint64(exifTypeSizes[e.typ]) * uint64(e.count)
+		if size <= 4 {
+			e.value = append([]byte{}, raw[8:8+size]...)
+		} else {
+			off := uint64(bo.Uint32(raw[8:]))
+			if off+size > uint64(len(tiff)) {
+				return nil, errors.New("exif: value out of range")
+			}
+			e.value = append([]byte{}, tiff[off:off+size]...)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// pickEntries 取出指定标签的项
+func pickEntries(entries []exifEntry, tags ...uint16) []exifEntry {
+	var picked []exifEntry
+	for _, e := range entries {
+		for _, t := range tags {
+			if e.tag == t {
+				picked = append(picked, e)
+			}
+		}
+	}
+	return picked
+}
+
+// buildTIFF 生成只包含 IFD0 以及可选的 Exif、GPS 子 IFD 的 TIFF 数据
+func buildTIFF(bo byteOrder, ifd0, subIFD, gpsIFD []exifEntry) []byte {
+	ifd0 = append([]exifEntry{}, ifd0...)
+	// 先占位子 IFD 指针，确定各 IFD 位置后再回填
+	pointer := func(tag uint16) int {
+		ifd0 = append(ifd0, exifEntry{tag: tag, typ: 4, count: 1, value: make([]byte, 4)})
+		return len(ifd0) - 1
+	}
+	subPtr, gpsPtr := -1, -1
+	if len(subIFD) > 0 {
+		subPtr = pointer(exifTagExifIFD)
+	}
+	if len(gpsIFD) > 0 {
+		gpsPtr = pointer(exifTagGPSIFD)
+	}
+
+	off0 := uint32(8)
+	offSub := off0 + ifdSize(ifd0)
+	offGPS := offSub + ifdSize(subIFD)
+	if subPtr >= 0 {
+		bo.PutUint32(ifd0[subPtr].value, offSub)
+	}
+	if gpsPtr >= 0 {
+		bo.PutUint32(ifd0[gpsPtr].value, offGPS)
+	}
+
+	buf := make([]byte, 8, offGPS+ifdSize(gpsIFD))
+	if bo == byteOrder(binary.LittleEndian) {
+		copy(buf, "II")
+	} else {
+		copy(buf, "MM")
+	}
+	bo.PutUint16(buf[2:], 42)
+	bo.PutUint32(buf[4:], off0)
+
+	buf = appendIFD(buf, bo, ifd0, off0)
+	if len(subIFD) > 0 {
+		buf = appendIFD(buf, bo, subIFD, offSub)
+	}
+	if len(gpsIFD) > 0 {
+		buf = appendIFD(buf, bo, gpsIFD, offGPS)
+	}
+	return buf
+}
+
+// ifdSize IFD 及其数据区的字节数（数据按偶数字节对齐）
+func ifdSize(entries []exifEntry) uint32 {
+	if len(entries) == 0 {
+		return 0
+	}
+	size := uint32(2 + 12*len(entries) + 4)
+	for _, e := range entries {
+		if n := uint32(len(e.value)); n > 4 {
+			size += n + n%2
+		}
+	}
+	return size
+}
+
+// appendIFD 在 offset 处写入 IFD，超过 4 字节的值写入紧随其后的数据区
+func appendIFD(buf []byte, bo byteOrder, entries []exifEntry, offset uint32) []byte {
+	entries = append([]exifEntry{}, entries...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	dataOff := offset + uint32(2+12*len(entries)+4)
+	var data []byte
+	buf = bo.AppendUint16(buf, uint16(len(entries)))
+	for _, e := range entries {
+		buf = bo.AppendUint16(buf, e.tag)
+		buf = bo.AppendUint16(buf, e.typ)
+		buf = bo.AppendUint32(buf, e.count)
+		if len(e.value) <= 4 {
+			var inline [4]byte
+			copy(inline[:], e.value)
+			buf = append(buf, inline[:]...)
+			continue
+		}
+		buf = bo.AppendUint32(buf, dataOff+uint32(len(data)))
+		data = append(data, e.value...)
+		if len(e.value)%2 == 1 {
+			data = append(data, 0)
+		}
+	}
+	buf = bo.AppendUint32(buf, 0) // 没有下一个 IFD
+	return append(buf, data...)
+}
+
+// insertJPEGExif 在 JPEG 的 SOI 之后插入 APP1 EXIF 段
+func insertJPEGExif(data, exif []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errors.New("exif: not a JPEG")
+	}
+	if len(exif)+2 > 0xFFFF {
+		return nil, errors.New("exif: segment too large")
+	}
+	out := make([]byte, 0, len(data)+len(exif)+4)
+	out = append(out, 0xFF, 0xD8, 0xFF, 0xE1)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(exif)+2))
+	out = append(out, exif...)
+	return append(out, data[2:]...), nil
+}
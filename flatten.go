@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"strings"
+)
+
+// defaultBackground 未指定背景色时透明区域合成到白色上
+var defaultBackground = color.NRGBA{255, 255, 255, 255}
+
+// BackgroundAuto 使用图片边缘最常见的不透明颜色作为背景色
+const BackgroundAuto = "auto"
+
+// formatSupportsAlpha 判断输出格式是否能保存透明通道
+func formatSupportsAlpha(format string) bool {
+	switch format {
+	case "jpeg", "jpg":
+		return false
+	}
+	return true
+}
+
+// validateBackground 检查背景色选项，支持空（白色）、"auto" 和 #rgb / #rrggbb
+func validateBackground(s string) error {
+	if s == "" || s == BackgroundAuto {
+		return nil
+	}
+	if _, ok := parseHexColor(s); !ok {
+		return newError(ErrInvalidOptions, nil, "err.invalid_background", s)
+	}
+	return nil
+}
+
+// backgroundColor 返回合成透明区域使用的背景色，选项应已通过 validateBackground 检查
+func backgroundColor(s string, img image.Image) color.NRGBA {
+	if s == BackgroundAuto {
+		return edgeColor(img)
+	}
+	if c, ok := parseHexColor(s); ok {
+		return c
+	}
+	return defaultBackground
+}
+
+// parseHexColor 解析 #rgb 或 #rrggbb（# 可省略）
+func parseHexColor(s string) (color.NRGBA, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return color.NRGBA{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, true
+}
+
+// hexColor 将颜色格式化为 #rrggbb
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// edgeColor 统计四条边上不透明像素的颜色，返回出现最多的一种；边缘全部透明时返回白色
+func edgeColor(img image.Image) color.NRGBA {
+	b := img.Bounds()
+	counts := make(map[color.NRGBA]int)
+	add := func(x, y int) {
+		c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+		if c.A == 255 {
+			counts[c]++
+		}
+	}
+	for x := b.Min.X; x < b.Max.X; x++ {
+		add(x, b.Min.Y)
+		add(x, b.Max.Y-1)
+	}
+	for y := b.Min.Y + 1; y < b.Max.Y-1; y++ {
+		add(b.Min.X, y)
+		add(b.Max.X-1, y)
+	}
+
+	best, bestCount := defaultBackground, 0
+	for c, n := range counts {
+		// 数量相同时按颜色值比较，保证结果稳定
+		if n > bestCount || (n == bestCount && hexColor(c) < hexColor(best)) {
+			best, bestCount = c, n
+		}
+	}
+	return best
+}
+
+// flattenAlpha 将图片合成到不透明的背景色上
+func flattenAlpha(img image.Image, bg color.Color) image.Image {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+	draw.Draw(out, b, &image.Uniform{bg}, image.Point{}, draw.Src)
+	draw.Draw(out, b, img, b.Min, draw.Over)
+	return out
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// testBordered 边缘为 border（左上角一个像素为 corner），内部为半透明红色
+func testBordered(border, corner color.NRGBA) *image.NRGBA {
+	img := testPNGImage(6, 5, func(x, y int) color.NRGBA {
+		if x == 0 || y == 0 || x == 5 || y == 4 {
+			return border
+		}
+		return color.NRGBA{255, 0, 0, 128}
+	})
+	img.SetNRGBA(0, 0, corner)
+	return img
+}
+
+func TestBackgroundColor(t *testing.T) {
+	green := color.NRGBA{0, 200, 0, 255}
+	bordered := testBordered(green, color.NRGBA{0, 0, 255, 255})
+	transparent := testBordered(color.NRGBA{0, 200, 0, 0}, color.NRGBA{9, 9, 9, 254})
+	cases := []struct {
+		option string
+		img    image.Image
+		want   color.NRGBA
+		valid  bool
+	}{
+		{"", bordered, defaultBackground, true},
+		{"#102030", bordered, color.NRGBA{0x10, 0x20, 0x30, 255}, true},
+		{"102030", bordered, color.NRGBA{0x10, 0x20, 0x30, 255}, true},
+		{" #abc ", bordered, color.NRGBA{0xaa, 0xbb, 0xcc, 255}, true},
+		{BackgroundAuto, bordered, green, true},
+		{BackgroundAuto, transparent, defaultBackground, true}, // 边缘没有不透明像素
+		{BackgroundAuto, image.NewNRGBA(image.Rect(0, 0, 0, 0)), defaultBackground, true},
+		{"#12345", bordered, defaultBackground, false},
+		{"#ggg", bordered, defaultBackground, false},
+		{"red", bordered, defaultBackground, false},
+	}
+	for _, c := range cases {
+		if got := backgroundColor(c.option, c.img); got != c.want {
+			t.Errorf("%q: got %v, want %v", c.option, got, c.want)
+		}
+		err := validateBackground(c.option)
+		if c.valid != (err == nil) || (!c.valid && !errors.Is(err, ErrInvalidOptions)) {
+			t.Errorf("%q: validateBackground: %v", c.option, err)
+		}
+	}
+}
+
+// 合成后完全不透明：不透明像素不变，透明像素为背景色，半透明像素按 alpha 混合
+func TestFlattenAlpha(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(10, 20, 13, 21)) // 起点不为 0
+	src.SetNRGBA(10, 20, color.NRGBA{10, 20, 30, 255})
+	src.SetNRGBA(11, 20, color.NRGBA{10, 20, 30, 0})
+	src.SetNRGBA(12, 20, color.NRGBA{255, 0, 0, 128})
+	bg := color.NRGBA{0, 0, 255, 255}
+
+	out := flattenAlpha(src, bg)
+	if out.Bounds() != src.Bounds() {
+		t.Fatalf("bounds %v, want %v", out.Bounds(), src.Bounds())
+	}
+	want := []color.RGBA{{10, 20, 30, 255}, {0, 0, 255, 255}, {128, 0, 127, 255}}
+	for i, w := range want {
+		got := color.RGBAModel.Convert(out.At(10+i, 20)).(color.RGBA)
+		if got != w {
+			t.Errorf("pixel %d: got %v, want %v", i, got, w)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 import './style.css';
-import {SelectImages, SelectOutputDir, CompressImage, GetImageInfo, CreateGifFromSequence} from '../wailsjs/go/main/App';
+import {SelectImages, SelectOutputDir, CompressImage, GetImageInfo, CreateGifFromSequence, Cancel} from '../wailsjs/go/main/App';
 import {EventsOn} from '../wailsjs/runtime/runtime';
 
 // 状态管理
@@ -12,6 +12,10 @@ let state = {
         maxWidth: 0,
         maxHeight: 0,
         outputFormat: 'original',
+        background: '',
+        jpegProgressive: false,
+        jpegLossless: false,
+        pngEffort: 0,
         keepAspect: true
     },
     gifOptions: {
@@ -23,6 +27,7 @@ let state = {
     },
     isProcessing: false,
     stopRequested: false,  // 停止压缩请求
+    currentJobId: '',      // 正在压缩的任务 ID，停止时用于取消
     currentIndex: -1,
     totalSaved: 0
 };
@@ -108,9 +113,27 @@ function initUI() {
                             <h3>输出格式</h3>
                             <div class="format-buttons" id="formatButtons">
                                 <button class="format-btn active" data-format="original">原格式</button>
+                                <button class="format-btn" data-format="auto" title="按图片内容选择：图形用 PNG，照片用 JPEG">自动</button>
                                 <button class="format-btn" data-format="jpeg">JPEG</button>
                                 <button class="format-btn" data-format="png">PNG</button>
                                 <button class="format-btn" data-format="webp">WebP</button>
+                                <button class="format-btn" data-format="best" title="分别尝试 AVIF、WebP 和 JPEG/PNG，保留最小的结果">最小</button>
+                            </div>
+                            <div class="size-input-group">
+                                <label>透明背景</label>
+                                <input type="text" id="background" placeholder="白色" title="转为 JPEG 时透明区域的颜色：#rrggbb，或 auto 使用边缘颜色">
+                            </div>
+                            <label class="checkbox-label">
+                                <input type="checkbox" id="jpegProgressive">
+                                <span>渐进式 JPEG</span>
+                            </label>
+                            <label class="checkbox-label" title="JPEG 保持原格式且不缩放时不重新编码，只优化熵编码，画质完全不变">
+                                <input type="checkbox" id="jpegLossless">
+                                <span>JPEG 无损优化</span>
+                            </label>
+                            <div class="size-input-group">
+                                <label>PNG 优化</label>
+                                <input type="number" id="pngEffort" min="0" max="3" value="0" title="PNG 无损优化级别：0 关闭，1 快速，2 尝试所有滤波策略，3 额外使用慢速 deflate；质量为 100 时不做有损量化">
                             </div>
                         </div>
 
@@ -650,6 +673,27 @@ function bindEvents() {
         state.options.maxHeight = parseInt(e.target.value) || 0;
     });
 
+    // 透明背景
+    document.getElementById('background').addEventListener('change', (e) => {
+        state.options.background = e.target.value.trim();
+    });
+
+    // 渐进式 JPEG
+    document.getElementById('jpegProgressive').addEventListener('change', (e) => {
+        state.options.jpegProgressive = e.target.checked;
+    });
+
+    // JPEG 无损优化
+    document.getElementById('jpegLossless').addEventListener('change', (e) => {
+        state.options.jpegLossless = e.target.checked;
+    });
+
+    // PNG 优化级别
+    document.getElementById('pngEffort').addEventListener('change', (e) => {
+        state.options.pngEffort = Math.min(3, Math.max(0, parseInt(e.target.value) || 0));
+        e.target.value = state.options.pngEffort;
+    });
+
     // 保持宽高比
     document.getElementById('keepAspect').addEventListener('change', (e) => {
         state.options.keepAspect = e.target.checked;
@@ -913,6 +957,10 @@ async function addFiles(paths) {
 // 停止压缩
 function stopCompression() {
     state.stopRequested = true;
+    // 中止正在压缩的文件，而不是等它完成
+    if (state.currentJobId) {
+        Cancel(state.currentJobId);
+    }
     updateProgress(0, 0, '正在停止...');
 }
 
@@ -950,19 +998,27 @@ async function startCompression() {
         updateFileList();
         updateProgress(processed, total, `正在压缩: ${file.name}`);
 
+        state.currentJobId = `compress-${Date.now()}-${i}`;
         try {
             const result = await CompressImage(file.path, {
                 quality: state.options.quality,
                 maxWidth: state.options.maxWidth,
                 maxHeight: state.options.maxHeight,
                 outputFormat: state.options.outputFormat,
+                background: state.options.background,
+                jpegProgressive: state.options.jpegProgressive,
+                jpegLossless: state.options.jpegLossless,
+                pngEffort: state.options.pngEffort,
                 outputDir: state.outputDir,
-                keepAspect: state.options.keepAspect
+                keepAspect: state.options.keepAspect,
+                jobId: state.currentJobId
             });
 
             if (result.success) {
                 file.status = 'success';
                 file.result = result;
+            } else if (result.code === 'canceled') {
+                file.status = 'processing';  // 停止后统一重置为 pending
             } else {
                 file.status = 'error';
                 console.error(result.message);
@@ -972,6 +1028,7 @@ async function startCompression() {
             console.error(err);
         }
 
+        state.currentJobId = '';
         processed++;
         updateFileList();
         updateStats();
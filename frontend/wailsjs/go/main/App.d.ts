@@ -2,16 +2,86 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function ApplyPreset(arg1:string):Promise<main.PresetResult>;
+
+export function Cancel(arg1:string):Promise<main.ActionResult>;
+
+export function ClearCache():Promise<main.ActionResult>;
+
+export function CompressDirectory(arg1:string,arg2:main.DirectoryOptions):Promise<main.DirectoryResult>;
+
 export function CompressGif(arg1:string,arg2:main.GifCompressOptions):Promise<main.GifResult>;
 
 export function CompressImage(arg1:string,arg2:main.CompressOptions):Promise<main.CompressResult>;
 
 export function CreateGifFromSequence(arg1:Array<string>,arg2:main.GifOptions):Promise<main.GifResult>;
 
+export function DeletePreset(arg1:string):Promise<main.ActionResult>;
+
+export function ExportBatchReport(arg1:string,arg2:Array<main.CompressResult>):Promise<main.ActionResult>;
+
+export function ExportDiagnostics():Promise<main.ActionResult>;
+
+export function ExportHistoryCSV(arg1:string):Promise<main.ActionResult>;
+
+export function ExportPresets(arg1:string,arg2:Array<string>):Promise<main.ActionResult>;
+
+export function GenerateResponsiveSet(arg1:string,arg2:main.ResponsiveSpec):Promise<main.ResponsiveResult>;
+
+export function GetActivePreset():Promise<main.PresetResult>;
+
+export function GetHistory(arg1:number):Promise<Array<main.HistoryEntry>>;
+
 export function GetImageInfo(arg1:string):Promise<main.ImageInfo>;
 
+export function GetJob(arg1:string):Promise<main.Job>;
+
+export function GetLocale():Promise<string>;
+
+export function GetLocales():Promise<Array<string>>;
+
+export function GetLogLevel():Promise<string>;
+
 export function GetSupportedFormats():Promise<Record<string, Array<string>>>;
 
+export function ImportPresets(arg1:string):Promise<main.ActionResult>;
+
+export function ListJobs():Promise<Array<main.Job>>;
+
+export function ListPresets():Promise<Array<main.Preset>>;
+
+export function ListTrash():Promise<Array<main.TrashEntry>>;
+
+export function ListWatches():Promise<Array<main.WatchInfo>>;
+
+export function RestoreTrash(arg1:string):Promise<main.ActionResult>;
+
+export function RevertEntry(arg1:string):Promise<main.ActionResult>;
+
+export function SavePreset(arg1:main.Preset):Promise<main.ActionResult>;
+
+export function SelectFolder():Promise<string>;
+
+export function SelectHistoryExportPath():Promise<string>;
+
 export function SelectImages():Promise<Array<string>>;
 
 export function SelectOutputDir():Promise<string>;
+
+export function SelectPresetExportPath():Promise<string>;
+
+export function SelectPresetFile():Promise<string>;
+
+export function SelectReportExportPath():Promise<string>;
+
+export function SetJobConcurrency(arg1:number):Promise<main.ActionResult>;
+
+export function SetLocale(arg1:string):Promise<main.ActionResult>;
+
+export function SetLogLevel(arg1:string):Promise<main.ActionResult>;
+
+export function StartWatch(arg1:main.WatchOptions):Promise<main.WatchResult>;
+
+export function StopWatch(arg1:string):Promise<main.ActionResult>;
+
+export function SubmitJob(arg1:main.JobRequest):Promise<main.JobSubmitResult>;
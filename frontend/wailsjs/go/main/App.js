@@ -2,6 +2,22 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ApplyPreset(arg1) {
+  return window['go']['main']['App']['ApplyPreset'](arg1);
+}
+
+export function Cancel(arg1) {
+  return window['go']['main']['App']['Cancel'](arg1);
+}
+
+export function ClearCache() {
+  return window['go']['main']['App']['ClearCache']();
+}
+
+export function CompressDirectory(arg1, arg2) {
+  return window['go']['main']['App']['CompressDirectory'](arg1, arg2);
+}
+
 export function CompressGif(arg1, arg2) {
   return window['go']['main']['App']['CompressGif'](arg1, arg2);
 }
@@ -14,14 +30,102 @@ export function CreateGifFromSequence(arg1, arg2) {
   return window['go']['main']['App']['CreateGifFromSequence'](arg1, arg2);
 }
 
+export function DeletePreset(arg1) {
+  return window['go']['main']['App']['DeletePreset'](arg1);
+}
+
+export function ExportBatchReport(arg1, arg2) {
+  return window['go']['main']['App']['ExportBatchReport'](arg1, arg2);
+}
+
+export function ExportDiagnostics() {
+  return window['go']['main']['App']['ExportDiagnostics']();
+}
+
+export function ExportHistoryCSV(arg1) {
+  return window['go']['main']['App']['ExportHistoryCSV'](arg1);
+}
+
+export function ExportPresets(arg1, arg2) {
+  return window['go']['main']['App']['ExportPresets'](arg1, arg2);
+}
+
+export function GenerateResponsiveSet(arg1, arg2) {
+  return window['go']['main']['App']['GenerateResponsiveSet'](arg1, arg2);
+}
+
+export function GetActivePreset() {
+  return window['go']['main']['App']['GetActivePreset']();
+}
+
+export function GetHistory(arg1) {
+  return window['go']['main']['App']['GetHistory'](arg1);
+}
+
 export function GetImageInfo(arg1) {
   return window['go']['main']['App']['GetImageInfo'](arg1);
 }
 
+export function GetJob(arg1) {
+  return window['go']['main']['App']['GetJob'](arg1);
+}
+
+export function GetLocale() {
+  return window['go']['main']['App']['GetLocale']();
+}
+
+export function GetLocales() {
+  return window['go']['main']['App']['GetLocales']();
+}
+
+export function GetLogLevel() {
+  return window['go']['main']['App']['GetLogLevel']();
+}
+
 export function GetSupportedFormats() {
   return window['go']['main']['App']['GetSupportedFormats']();
 }
 
+export function ImportPresets(arg1) {
+  return window['go']['main']['App']['ImportPresets'](arg1);
+}
+
+export function ListJobs() {
+  return window['go']['main']['App']['ListJobs']();
+}
+
+export function ListPresets() {
+  return window['go']['main']['App']['ListPresets']();
+}
+
+export function ListTrash() {
+  return window['go']['main']['App']['ListTrash']();
+}
+
+export function ListWatches() {
+  return window['go']['main']['App']['ListWatches']();
+}
+
+export function RestoreTrash(arg1) {
+  return window['go']['main']['App']['RestoreTrash'](arg1);
+}
+
+export function RevertEntry(arg1) {
+  return window['go']['main']['App']['RevertEntry'](arg1);
+}
+
+export function SavePreset(arg1) {
+  return window['go']['main']['App']['SavePreset'](arg1);
+}
+
+export function SelectFolder() {
+  return window['go']['main']['App']['SelectFolder']();
+}
+
+export function SelectHistoryExportPath() {
+  return window['go']['main']['App']['SelectHistoryExportPath']();
+}
+
 export function SelectImages() {
   return window['go']['main']['App']['SelectImages']();
 }
@@ -29,3 +133,39 @@ export function SelectImages() {
 export function SelectOutputDir() {
   return window['go']['main']['App']['SelectOutputDir']();
 }
+
+export function SelectPresetExportPath() {
+  return window['go']['main']['App']['SelectPresetExportPath']();
+}
+
+export function SelectPresetFile() {
+  return window['go']['main']['App']['SelectPresetFile']();
+}
+
+export function SelectReportExportPath() {
+  return window['go']['main']['App']['SelectReportExportPath']();
+}
+
+export function SetJobConcurrency(arg1) {
+  return window['go']['main']['App']['SetJobConcurrency'](arg1);
+}
+
+export function SetLocale(arg1) {
+  return window['go']['main']['App']['SetLocale'](arg1);
+}
+
+export function SetLogLevel(arg1) {
+  return window['go']['main']['App']['SetLogLevel'](arg1);
+}
+
+export function StartWatch(arg1) {
+  return window['go']['main']['App']['StartWatch'](arg1);
+}
+
+export function StopWatch(arg1) {
+  return window['go']['main']['App']['StopWatch'](arg1);
+}
+
+export function SubmitJob(arg1) {
+  return window['go']['main']['App']['SubmitJob'](arg1);
+}
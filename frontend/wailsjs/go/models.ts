@@ -1,5 +1,19 @@
 export namespace main {
 	
+	export class ActionResult {
+	    success: boolean;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ActionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	    }
+	}
 	export class CompressOptions {
 	    quality: number;
 	    maxWidth: number;
@@ -7,6 +21,20 @@ export namespace main {
 	    outputFormat: string;
 	    outputDir: string;
 	    keepAspect: boolean;
+	    nameTemplate: string;
+	    collision: string;
+	    inPlace: boolean;
+	    backup: string;
+	    acceptFormats: string[];
+	    background: string;
+	    jpegProgressive: boolean;
+	    jpegSubsampling: string;
+	    jpegQuantTable: string;
+	    jpegLossless: boolean;
+	    pngEffort: number;
+	    ignoreProjectConfig: boolean;
+	    force: boolean;
+	    jobId?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new CompressOptions(source);
@@ -20,10 +48,65 @@ export namespace main {
 	        this.outputFormat = source["outputFormat"];
 	        this.outputDir = source["outputDir"];
 	        this.keepAspect = source["keepAspect"];
+	        this.nameTemplate = source["nameTemplate"];
+	        this.collision = source["collision"];
+	        this.inPlace = source["inPlace"];
+	        this.backup = source["backup"];
+	        this.acceptFormats = source["acceptFormats"];
+	        this.background = source["background"];
+	        this.jpegProgressive = source["jpegProgressive"];
+	        this.jpegSubsampling = source["jpegSubsampling"];
+	        this.jpegQuantTable = source["jpegQuantTable"];
+	        this.jpegLossless = source["jpegLossless"];
+	        this.pngEffort = source["pngEffort"];
+	        this.ignoreProjectConfig = source["ignoreProjectConfig"];
+	        this.force = source["force"];
+	        this.jobId = source["jobId"];
+	    }
+	}
+	export class FormatDecision {
+	    mode: string;
+	    format: string;
+	    reason: string;
+	    uniqueColors: number;
+	    hasAlpha: boolean;
+	    flatRatio: number;
+	    edgeRatio: number;
+	    noiseRatio: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormatDecision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.format = source["format"];
+	        this.reason = source["reason"];
+	        this.uniqueColors = source["uniqueColors"];
+	        this.hasAlpha = source["hasAlpha"];
+	        this.flatRatio = source["flatRatio"];
+	        this.edgeRatio = source["edgeRatio"];
+	        this.noiseRatio = source["noiseRatio"];
+	    }
+	}
+	export class FormatCandidate {
+	    format: string;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FormatCandidate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.size = source["size"];
 	    }
 	}
 	export class CompressResult {
 	    success: boolean;
+	    code: string;
 	    message: string;
 	    originalSize: number;
 	    newSize: number;
@@ -35,6 +118,21 @@ export namespace main {
 	    newWidth: number;
 	    newHeight: number;
 	    compressionRatio: number;
+	    skipped: boolean;
+	    backupPath: string;
+	    projectConfig: string;
+	    appliedRules: string[];
+	    inputHash: string;
+	    cached: boolean;
+	    inputPath: string;
+	    inputFormat: string;
+	    outputFormat: string;
+	    quality: number;
+	    durationMs: number;
+	    warnings: string[];
+	    formatCandidates?: FormatCandidate[];
+	    formatDecision?: FormatDecision;
+	    lossless: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new CompressResult(source);
@@ -43,6 +141,7 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.success = source["success"];
+	        this.code = source["code"];
 	        this.message = source["message"];
 	        this.originalSize = source["originalSize"];
 	        this.newSize = source["newSize"];
@@ -54,14 +153,176 @@ export namespace main {
 	        this.newWidth = source["newWidth"];
 	        this.newHeight = source["newHeight"];
 	        this.compressionRatio = source["compressionRatio"];
+	        this.skipped = source["skipped"];
+	        this.backupPath = source["backupPath"];
+	        this.projectConfig = source["projectConfig"];
+	        this.appliedRules = source["appliedRules"];
+	        this.inputHash = source["inputHash"];
+	        this.cached = source["cached"];
+	        this.inputPath = source["inputPath"];
+	        this.inputFormat = source["inputFormat"];
+	        this.outputFormat = source["outputFormat"];
+	        this.quality = source["quality"];
+	        this.durationMs = source["durationMs"];
+	        this.warnings = source["warnings"];
+	        this.formatCandidates = this.convertValues(source["formatCandidates"], FormatCandidate);
+	        this.formatDecision = this.convertValues(source["formatDecision"], FormatDecision);
+	        this.lossless = source["lossless"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DirectoryFileResult {
+	    relPath: string;
+	    result: CompressResult;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryFileResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.relPath = source["relPath"];
+	        this.result = this.convertValues(source["result"], CompressResult);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	export class DirectoryOptions {
+	    compress: CompressOptions;
+	    include: string[];
+	    exclude: string[];
+	    includeHidden: boolean;
+	    reports: string[];
+	    jobId?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.compress = this.convertValues(source["compress"], CompressOptions);
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	        this.includeHidden = source["includeHidden"];
+	        this.reports = source["reports"];
+	        this.jobId = source["jobId"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DirectoryResult {
+	    success: boolean;
+	    message: string;
+	    root: string;
+	    outputDir: string;
+	    total: number;
+	    succeeded: number;
+	    failed: number;
+	    skipped: number;
+	    canceled: number;
+	    originalSize: number;
+	    newSize: number;
+	    files: DirectoryFileResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DirectoryResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.root = source["root"];
+	        this.outputDir = source["outputDir"];
+	        this.total = source["total"];
+	        this.succeeded = source["succeeded"];
+	        this.failed = source["failed"];
+	        this.skipped = source["skipped"];
+	        this.canceled = source["canceled"];
+	        this.originalSize = source["originalSize"];
+	        this.newSize = source["newSize"];
+	        this.files = this.convertValues(source["files"], DirectoryFileResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
 	export class GifCompressOptions {
 	    maxWidth: number;
 	    maxHeight: number;
 	    colors: number;
 	    lossy: number;
 	    outputDir: string;
+	    nameTemplate: string;
+	    collision: string;
+	    jobId?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new GifCompressOptions(source);
@@ -74,6 +335,9 @@ export namespace main {
 	        this.colors = source["colors"];
 	        this.lossy = source["lossy"];
 	        this.outputDir = source["outputDir"];
+	        this.nameTemplate = source["nameTemplate"];
+	        this.collision = source["collision"];
+	        this.jobId = source["jobId"];
 	    }
 	}
 	export class GifOptions {
@@ -83,6 +347,8 @@ export namespace main {
 	    maxHeight: number;
 	    outputDir: string;
 	    outputName: string;
+	    collision: string;
+	    jobId?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new GifOptions(source);
@@ -96,10 +362,13 @@ export namespace main {
 	        this.maxHeight = source["maxHeight"];
 	        this.outputDir = source["outputDir"];
 	        this.outputName = source["outputName"];
+	        this.collision = source["collision"];
+	        this.jobId = source["jobId"];
 	    }
 	}
 	export class GifResult {
 	    success: boolean;
+	    code: string;
 	    message: string;
 	    outputPath: string;
 	    fileSize: number;
@@ -107,6 +376,9 @@ export namespace main {
 	    width: number;
 	    height: number;
 	    preview: string;
+	    skipped: boolean;
+	    inputHash: string;
+	    warnings: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new GifResult(source);
@@ -115,6 +387,7 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.success = source["success"];
+	        this.code = source["code"];
 	        this.message = source["message"];
 	        this.outputPath = source["outputPath"];
 	        this.fileSize = source["fileSize"];
@@ -122,6 +395,47 @@ export namespace main {
 	        this.width = source["width"];
 	        this.height = source["height"];
 	        this.preview = source["preview"];
+	        this.skipped = source["skipped"];
+	        this.inputHash = source["inputHash"];
+	        this.warnings = source["warnings"];
+	    }
+	}
+	export class HistoryEntry {
+	    id: string;
+	    time: string;
+	    kind: string;
+	    inputPath: string;
+	    inputHash: string;
+	    options: any;
+	    outputPath: string;
+	    outputHash: string;
+	    backupPath: string;
+	    originalSize: number;
+	    newSize: number;
+	    success: boolean;
+	    message: string;
+	    reverted: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = source["time"];
+	        this.kind = source["kind"];
+	        this.inputPath = source["inputPath"];
+	        this.inputHash = source["inputHash"];
+	        this.options = source["options"];
+	        this.outputPath = source["outputPath"];
+	        this.outputHash = source["outputHash"];
+	        this.backupPath = source["backupPath"];
+	        this.originalSize = source["originalSize"];
+	        this.newSize = source["newSize"];
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.reverted = source["reverted"];
 	    }
 	}
 	export class ImageInfo {
@@ -132,6 +446,8 @@ export namespace main {
 	    height: number;
 	    format: string;
 	    preview: string;
+	    code: string;
+	    message: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ImageInfo(source);
@@ -146,7 +462,427 @@ export namespace main {
 	        this.height = source["height"];
 	        this.format = source["format"];
 	        this.preview = source["preview"];
+	        this.code = source["code"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ResponsiveVariant {
+	    format: string;
+	    mimeType: string;
+	    path: string;
+	    url: string;
+	    width: number;
+	    height: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResponsiveVariant(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.mimeType = source["mimeType"];
+	        this.path = source["path"];
+	        this.url = source["url"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.size = source["size"];
+	    }
+	}
+	export class ResponsiveResult {
+	    success: boolean;
+	    code: string;
+	    message: string;
+	    originalSize: number;
+	    originalWidth: number;
+	    originalHeight: number;
+	    totalSize: number;
+	    variants: ResponsiveVariant[];
+	    html: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResponsiveResult(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.code = source["code"];
+	        this.message = source["message"];
+	        this.originalSize = source["originalSize"];
+	        this.originalWidth = source["originalWidth"];
+	        this.originalHeight = source["originalHeight"];
+	        this.totalSize = source["totalSize"];
+	        this.variants = this.convertValues(source["variants"], ResponsiveVariant);
+	        this.html = source["html"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ResponsiveSpec {
+	    widths: number[];
+	    formats: string[];
+	    quality: number;
+	    outputDir: string;
+	    nameTemplate: string;
+	    collision: string;
+	    urlPrefix: string;
+	    sizes: string;
+	    alt: string;
+	    allowUpscale: boolean;
+	    jobId?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ResponsiveSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.widths = source["widths"];
+	        this.formats = source["formats"];
+	        this.quality = source["quality"];
+	        this.outputDir = source["outputDir"];
+	        this.nameTemplate = source["nameTemplate"];
+	        this.collision = source["collision"];
+	        this.urlPrefix = source["urlPrefix"];
+	        this.sizes = source["sizes"];
+	        this.alt = source["alt"];
+	        this.allowUpscale = source["allowUpscale"];
+	        this.jobId = source["jobId"];
+	    }
+	}
+	export class JobRequest {
+	    kind: string;
+	    priority: number;
+	    paths: string[];
+	    compress?: CompressOptions;
+	    directory?: DirectoryOptions;
+	    gif?: GifOptions;
+	    gifCompress?: GifCompressOptions;
+	    responsive?: ResponsiveSpec;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.priority = source["priority"];
+	        this.paths = source["paths"];
+	        this.compress = this.convertValues(source["compress"], CompressOptions);
+	        this.directory = this.convertValues(source["directory"], DirectoryOptions);
+	        this.gif = this.convertValues(source["gif"], GifOptions);
+	        this.gifCompress = this.convertValues(source["gifCompress"], GifCompressOptions);
+	        this.responsive = this.convertValues(source["responsive"], ResponsiveSpec);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Job {
+	    id: string;
+	    kind: string;
+	    status: string;
+	    priority: number;
+	    progress: number;
+	    message: string;
+	    code: string;
+	    createdAt: string;
+	    startedAt: string;
+	    finishedAt: string;
+	    request: JobRequest;
+	    compress?: CompressResult[];
+	    directory?: DirectoryResult;
+	    gif?: GifResult;
+	    responsive?: ResponsiveResult;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.progress = source["progress"];
+	        this.message = source["message"];
+	        this.code = source["code"];
+	        this.createdAt = source["createdAt"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.request = this.convertValues(source["request"], JobRequest);
+	        this.compress = this.convertValues(source["compress"], CompressResult);
+	        this.directory = this.convertValues(source["directory"], DirectoryResult);
+	        this.gif = this.convertValues(source["gif"], GifResult);
+	        this.responsive = this.convertValues(source["responsive"], ResponsiveResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class JobSubmitResult {
+	    success: boolean;
+	    code: string;
+	    message: string;
+	    jobId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobSubmitResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.code = source["code"];
+	        this.message = source["message"];
+	        this.jobId = source["jobId"];
+	    }
+	}
+	export class Preset {
+	    name: string;
+	    description?: string;
+	    compress?: CompressOptions;
+	    gif?: GifOptions;
+	    gifCompress?: GifCompressOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.compress = this.convertValues(source["compress"], CompressOptions);
+	        this.gif = this.convertValues(source["gif"], GifOptions);
+	        this.gifCompress = this.convertValues(source["gifCompress"], GifCompressOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PresetResult {
+	    success: boolean;
+	    message: string;
+	    preset: Preset;
+	
+	    static createFrom(source: any = {}) {
+	        return new PresetResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.preset = this.convertValues(source["preset"], Preset);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	export class TrashEntry {
+	    id: string;
+	    originalPath: string;
+	    trashedPath: string;
+	    size: number;
+	    trashedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrashEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.originalPath = source["originalPath"];
+	        this.trashedPath = source["trashedPath"];
+	        this.size = source["size"];
+	        this.trashedAt = source["trashedAt"];
+	    }
+	}
+	export class WatchInfo {
+	    id: string;
+	    dir: string;
+	    outputDir: string;
+	    processed: number;
+	    failed: number;
+	    startedAt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WatchInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.dir = source["dir"];
+	        this.outputDir = source["outputDir"];
+	        this.processed = source["processed"];
+	        this.failed = source["failed"];
+	        this.startedAt = source["startedAt"];
+	    }
+	}
+	export class WatchOptions {
+	    dir: string;
+	    compress: CompressOptions;
+	    preset: string;
+	    recursive: boolean;
+	    debounceMs: number;
+	    mode: string;
+	    include: string[];
+	    exclude: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WatchOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.compress = this.convertValues(source["compress"], CompressOptions);
+	        this.preset = source["preset"];
+	        this.recursive = source["recursive"];
+	        this.debounceMs = source["debounceMs"];
+	        this.mode = source["mode"];
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WatchResult {
+	    success: boolean;
+	    message: string;
+	    watch: WatchInfo;
+	
+	    static createFrom(source: any = {}) {
+	        return new WatchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.watch = this.convertValues(source["watch"], WatchInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// 原地优化的备份方式
+const (
+	BackupNone  = "none"  // 不备份（默认）
+	BackupOrig  = "orig"  // 在源文件旁保留 .orig 备份
+	BackupTrash = "trash" // 放入可恢复的回收站目录
+)
+
+// appDataDir 返回应用数据目录（位于用户配置目录下），不存在时创建
+func appDataDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "squash")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// writeFileAtomic 原子写入文件
+// 先写入同目录下的临时文件并 fsync，再重命名覆盖目标，崩溃时不会留下截断的文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir 刷新目录项，确保重命名落盘（部分平台不支持，忽略错误）
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+// origBackupPath 返回保存 original 的备份路径，exists 表示该备份已存在
+// 已有的 .orig 不会被覆盖（仍是最初的原始文件）；内容与 original 不同时依次使用 .orig.1、.orig.2……，
+// 撤销时恢复的总是这次原地优化前的内容
+func origBackupPath(path string, original []byte) (backupPath string, exists bool, err error) {
+	want := hashBytes(original)
+	for n := 0; ; n++ {
+		backupPath = path + ".orig"
+		if n > 0 {
+			backupPath += "." + strconv.Itoa(n)
+		}
+		hash, err := hashFile(backupPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			return backupPath, false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		if hash == want {
+			return backupPath, true, nil
+		}
+	}
+}
+
+// replaceFileInPlace 原地替换文件内容
+// 保留原文件的权限和修改时间，按 backup 方式保留原始内容，返回备份位置
+func replaceFileInPlace(path string, data, original []byte, backup string) (string, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := ""
+	switch backup {
+	case "", BackupNone:
+	case BackupOrig:
+		var exists bool
+		backupPath, exists, err = origBackupPath(path, original)
+		if err != nil {
+			return "", newError(ErrWrite, err, "err.backup")
+		}
+		if exists {
+			break
+		}
+		if err := writeFileAtomic(backupPath, original, stat.Mode().Perm()); err != nil {
+			return "", newError(ErrWrite, err, "err.backup")
+		}
+		if err := os.Chtimes(backupPath, stat.ModTime(), stat.ModTime()); err != nil {
+			return "", newError(ErrWrite, err, "err.backup")
+		}
+	case BackupTrash:
+		entry, err := moveToTrash(path, original, stat)
+		if err != nil {
+			return "", newError(ErrWrite, err, "err.backup")
+		}
+		backupPath = entry.TrashedPath
+	default:
+		return "", newError(ErrInvalidOptions, nil, "err.unknown_backup", backup)
+	}
+
+	if err := writeFileAtomic(path, data, stat.Mode().Perm()); err != nil {
+		return backupPath, err
+	}
+	// 恢复修改时间，避免同步工具误判为新文件
+	if err := os.Chtimes(path, stat.ModTime(), stat.ModTime()); err != nil {
+		return backupPath, err
+	}
+	return backupPath, nil
+}
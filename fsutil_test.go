@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testDataDirs 把配置和缓存目录指向临时目录，避免测试读写用户数据
+func testDataDirs(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+}
+
+// testMtime 测试文件的修改时间
+var testMtime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testFile 写入文件并设置修改时间，返回路径
+func testFile(t *testing.T, path string, data string, mtime time.Time) string {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testFileContent 断言文件内容
+func testFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s: got %q, want %q", filepath.Base(path), got, want)
+	}
+}
+
+// 替换后保留权限和修改时间；.orig 只在内容与这次的原始内容相同时复用，否则写入 .orig.N
+func TestReplaceFileInPlace(t *testing.T) {
+	testDataDirs(t)
+	dir := t.TempDir()
+	mtime := testMtime
+	path := filepath.Join(dir, "photo.jpg")
+
+	steps := []struct {
+		original, data, backup string
+		wantBackup             string // 相对 dir，空表示没有备份
+	}{
+		{"v0", "o0", BackupNone, ""},
+		{"v0", "o0", BackupOrig, "photo.jpg.orig"},
+		{"v1", "o1", BackupOrig, "photo.jpg.orig.1"}, // 已有的 .orig 是 v0，不能当作 v1 的备份
+		{"v2", "o2", BackupOrig, "photo.jpg.orig.2"},
+		{"v0", "o0", BackupOrig, "photo.jpg.orig"}, // 内容相同时复用
+		{"v1", "o1", BackupOrig, "photo.jpg.orig.1"},
+	}
+	for i, s := range steps {
+		testFile(t, path, s.original, mtime)
+		backupPath, err := replaceFileInPlace(path, []byte(s.data), []byte(s.original), s.backup)
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		want := ""
+		if s.wantBackup != "" {
+			want = filepath.Join(dir, s.wantBackup)
+		}
+		if backupPath != want {
+			t.Fatalf("step %d: backup %q, want %q", i, backupPath, want)
+		}
+		testFileContent(t, path, s.data)
+		if want != "" {
+			testFileContent(t, want, s.original)
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !stat.ModTime().Equal(mtime) || stat.Mode().Perm() != 0640 {
+			t.Errorf("step %d: mode %v mtime %v", i, stat.Mode().Perm(), stat.ModTime())
+		}
+	}
+	testFileContent(t, filepath.Join(dir, "photo.jpg.orig"), "v0")
+
+	// 放入回收站时返回回收站中的路径
+	testFile(t, path, "v3", mtime)
+	backupPath, err := replaceFileInPlace(path, []byte("o3"), []byte("v3"), BackupTrash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backupPath == "" || filepath.Dir(backupPath) == dir {
+		t.Errorf("trash backup %q", backupPath)
+	}
+	testFileContent(t, backupPath, "v3")
+
+	if _, err := replaceFileInPlace(path, []byte("x"), []byte("o3"), "copy"); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("unknown backup: want ErrInvalidOptions, got %v", err)
+	}
+	testFileContent(t, path, "o3")
+}
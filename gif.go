@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"image"
@@ -13,15 +14,36 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/nfnt/resize"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // CreateGifFromSequence 从序列帧创建 GIF
 func (a *App) CreateGifFromSequence(imagePaths []string, options GifOptions) GifResult {
+	ctx, done := a.beginJob(options.JobID)
+	defer done()
+	return a.runGifSequence(ctx, imagePaths, options)
+}
+
+// runGifSequence 从序列帧创建 GIF 并记录日志和历史
+func (a *App) runGifSequence(ctx context.Context, imagePaths []string, options GifOptions) GifResult {
+	start := time.Now()
+	result := createGifFromSequence(ctx, imagePaths, options)
+	logGifResult("gif sequence", sequenceInputPath(imagePaths), result, start)
+	if !result.Skipped && result.Code != CodeCanceled {
+		if err := recordGifHistory(HistoryKindGifSequence, sequenceInputPath(imagePaths), options, result); err != nil {
+			logger.Warn("history write failed", "error", err)
+			result.Warnings = append(result.Warnings, tr("history.write_failed", err))
+		}
+	}
+	return result
+}
+
+// createGifFromSequence 读取序列帧并编码为 GIF，每读取或处理一帧前检查 ctx
+func createGifFromSequence(ctx context.Context, imagePaths []string, options GifOptions) GifResult {
 	if len(imagePaths) < 2 {
-		return GifResult{Success: false, Message: "至少需要 2 张图片来创建 GIF"}
+		return gifFailResult(newError(ErrInvalidOptions, nil, "gif.need_two_frames"))
 	}
 
 	// 排序文件路径（按文件名自然排序）
@@ -29,32 +51,88 @@ func (a *App) CreateGifFromSequence(imagePaths []string, options GifOptions) Gif
 
 	// 读取所有图片
 	var frames []image.Image
-	var firstWidth, firstHeight int
-
 	for i, path := range sortedPaths {
+		if err := checkCanceled(ctx); err != nil {
+			return gifFailResult(err)
+		}
+		reportProgress(ctx, i*50/len(sortedPaths), tr("gif.progress.reading", i+1, len(sortedPaths)))
 		data, err := os.ReadFile(path)
 		if err != nil {
-			return GifResult{Success: false, Message: fmt.Sprintf("无法读取文件 %s: %v", filepath.Base(path), err)}
+			return gifFailResult(newError(ErrRead, err, "err.read_file_named", filepath.Base(path)))
 		}
 
 		img, _, err := decodeImage(data, path)
 		if err != nil {
-			return GifResult{Success: false, Message: fmt.Sprintf("无法解码图片 %s: %v", filepath.Base(path), err)}
+			return gifFailResult(fmt.Errorf("%s: %w", filepath.Base(path), err))
 		}
+		frames = append(frames, img)
+	}
+
+	gifImg, encoded, err := encodeGifSequence(ctx, frames, options)
+	if err != nil {
+		return gifFailResult(err)
+	}
+	outWidth, outHeight := gifImg.Config.Width, gifImg.Config.Height
+
+	// 生成输出路径
+	outputName := options.OutputName
+	if outputName == "" {
+		outputName = "animation"
+	}
+	outputPath, skip, err := resolveOutputPath(filepath.Join(options.OutputDir, outputName+".gif"), "", options.Collision)
+	if err != nil {
+		return gifFailResult(err)
+	}
+	if skip {
+		return GifResult{Success: true, Skipped: true, Message: tr("compress.target_exists_skipped"), OutputPath: outputPath}
+	}
 
-		// 记录第一张图的尺寸作为基准
-		if i == 0 {
-			bounds := img.Bounds()
-			firstWidth = bounds.Dx()
-			firstHeight = bounds.Dy()
+	// 保存
+	err = writeFileAtomic(outputPath, encoded, 0644)
+	if err != nil {
+		return gifFailResult(newError(ErrWrite, err, "err.save"))
+	}
+
+	// 生成预览（小尺寸的 GIF base64）
+	var warnings []string
+	previewBase64 := ""
+	if len(encoded) < 2*1024*1024 { // 小于 2MB 直接使用
+		previewBase64 = "data:image/gif;base64," + base64.StdEncoding.EncodeToString(encoded)
+	} else {
+		// 大文件生成缩略预览
+		previewGif := createPreviewGif(gifImg, 200)
+		var previewBuf bytes.Buffer
+		if err := gif.EncodeAll(&previewBuf, previewGif); err != nil {
+			warnings = append(warnings, tr("preview.failed_detail", err))
+		} else {
+			previewBase64 = "data:image/gif;base64," + base64.StdEncoding.EncodeToString(previewBuf.Bytes())
 		}
+	}
 
-		frames = append(frames, img)
+	return GifResult{
+		Success:    true,
+		Message:    tr("gif.created"),
+		OutputPath: outputPath,
+		FileSize:   int64(len(encoded)),
+		FrameCount: len(frames),
+		Width:      int(outWidth),
+		Height:     int(outHeight),
+		Preview:    previewBase64,
+		Warnings:   warnings,
+	}
+}
+
+// encodeGifSequence 将已解码的序列帧缩放、量化并编码为 GIF，返回 GIF 结构（用于生成预览）和编码后的数据
+// 每处理一帧前检查 ctx
+func encodeGifSequence(ctx context.Context, frames []image.Image, options GifOptions) (*gif.GIF, []byte, error) {
+	if len(frames) < 2 {
+		return nil, nil, newError(ErrInvalidOptions, nil, "gif.need_two_frames")
 	}
 
-	// 确定输出尺寸
-	outWidth := uint(firstWidth)
-	outHeight := uint(firstHeight)
+	// 以第一帧的尺寸为基准确定输出尺寸
+	bounds := frames[0].Bounds()
+	outWidth := uint(bounds.Dx())
+	outHeight := uint(bounds.Dy())
 
 	if options.MaxWidth > 0 && outWidth > options.MaxWidth {
 		ratio := float64(options.MaxWidth) / float64(outWidth)
@@ -82,7 +160,12 @@ func (a *App) CreateGifFromSequence(imagePaths []string, options GifOptions) Gif
 	palette := generatePalette(frames[0])
 
 	// 处理每一帧
-	for _, frame := range frames {
+	for i, frame := range frames {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, nil, err
+		}
+		reportProgress(ctx, 50+i*45/len(frames), tr("gif.progress.frame", i+1, len(frames)))
+
 		// 调整尺寸
 		resizedFrame := resize.Resize(outWidth, outHeight, frame, resize.Lanczos3)
 
@@ -100,82 +183,148 @@ func (a *App) CreateGifFromSequence(imagePaths []string, options GifOptions) Gif
 	}
 
 	// 设置 GIF 配置
-	if len(gifImg.Image) > 0 {
-		gifImg.Config = image.Config{
-			Width:      int(outWidth),
-			Height:     int(outHeight),
-			ColorModel: palette,
+	gifImg.Config = image.Config{
+		Width:      int(outWidth),
+		Height:     int(outHeight),
+		ColorModel: palette,
+	}
+
+	// 编码
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, gifImg); err != nil {
+		return nil, nil, newError(ErrEncode, err, "gif.encode_failed")
+	}
+	if err := checkCanceled(ctx); err != nil {
+		return nil, nil, err
+	}
+	return gifImg, buf.Bytes(), nil
+}
+
+// CompressGif 压缩 GIF 文件（带进度回调）
+func (a *App) CompressGif(gifPath string, options GifCompressOptions) GifResult {
+	ctx, done := a.beginJob(options.JobID)
+	defer done()
+	return a.runGifCompress(ctx, gifPath, options)
+}
+
+// runGifCompress 压缩 GIF 并记录日志和历史
+func (a *App) runGifCompress(ctx context.Context, gifPath string, options GifCompressOptions) GifResult {
+	start := time.Now()
+	result := a.compressGif(ctx, gifPath, options)
+	logGifResult("gif compress", gifPath, result, start)
+	if !result.Skipped && result.Code != CodeCanceled {
+		if err := recordGifHistory(HistoryKindGif, gifPath, options, result); err != nil {
+			logger.Warn("history write failed", "error", err)
+			result.Warnings = append(result.Warnings, tr("history.write_failed", err))
 		}
 	}
+	return result
+}
+
+// logGifResult 记录 GIF 生成或压缩的结果
+func logGifResult(op, inputPath string, r GifResult, start time.Time) {
+	if !r.Success {
+		logger.Warn(op+" failed", "path", inputPath, "code", r.Code, "error", r.Message, since(start))
+		return
+	}
+	logger.Info(op, "path", inputPath, "output", r.OutputPath, "skipped", r.Skipped, "frames", r.FrameCount,
+		"width", r.Width, "height", r.Height, "size", r.FileSize, since(start))
+}
+
+// compressGif 重新量化并编码 GIF 的每一帧，每处理一帧前检查 ctx
+func (a *App) compressGif(ctx context.Context, gifPath string, options GifCompressOptions) GifResult {
+	// 读取 GIF 文件
+	data, err := os.ReadFile(gifPath)
+	if err != nil {
+		return gifFailResult(newError(ErrRead, err, "err.read_file"))
+	}
+
+	originalSize := int64(len(data))
+
+	newGif, encoded, err := a.recompressGif(ctx, data, options)
+	if err != nil {
+		return gifFailResult(err)
+	}
+	newWidth, newHeight := newGif.Config.Width, newGif.Config.Height
 
 	// 生成输出路径
-	outputName := options.OutputName
-	if outputName == "" {
-		outputName = "animation"
+	outputDir := options.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Dir(gifPath)
 	}
-	outputPath := filepath.Join(options.OutputDir, outputName+".gif")
 
-	// 编码并保存
-	var buf bytes.Buffer
-	err := gif.EncodeAll(&buf, gifImg)
+	nameTemplate := options.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = "{name}_compressed.{ext}"
+	}
+	baseName := strings.TrimSuffix(filepath.Base(gifPath), filepath.Ext(gifPath))
+	outputPath := filepath.Join(outputDir, renderNameTemplate(nameTemplate, nameVars{
+		Name:   baseName,
+		Ext:    "gif",
+		Width:  newWidth,
+		Height: newHeight,
+		Data:   encoded,
+	}))
+
+	outputPath, skip, err := resolveOutputPath(outputPath, gifPath, options.Collision)
 	if err != nil {
-		return GifResult{Success: false, Message: fmt.Sprintf("GIF 编码失败: %v", err)}
+		return gifFailResult(err)
+	}
+	if skip {
+		return GifResult{Success: true, Skipped: true, Message: tr("compress.target_exists_skipped"), OutputPath: outputPath}
 	}
 
-	err = os.WriteFile(outputPath, buf.Bytes(), 0644)
+	// 保存
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return gifFailResult(newError(ErrWrite, err, "err.create_output_dir"))
+	}
+
+	err = writeFileAtomic(outputPath, encoded, 0644)
 	if err != nil {
-		return GifResult{Success: false, Message: fmt.Sprintf("保存失败: %v", err)}
+		return gifFailResult(newError(ErrWrite, err, "err.save"))
 	}
 
-	// 生成预览（小尺寸的 GIF base64）
+	newSize := int64(len(encoded))
+
+	// 发送进度：完成
+	a.gifProgress(ctx, "done", 100, tr("gif.progress.done"))
+
+	// 生成预览
 	previewBase64 := ""
-	if buf.Len() < 2*1024*1024 { // 小于 2MB 直接使用
-		previewBase64 = "data:image/gif;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
-	} else {
-		// 大文件生成缩略预览
-		previewGif := createPreviewGif(gifImg, 200)
-		var previewBuf bytes.Buffer
-		gif.EncodeAll(&previewBuf, previewGif)
-		previewBase64 = "data:image/gif;base64," + base64.StdEncoding.EncodeToString(previewBuf.Bytes())
+	if len(encoded) < 2*1024*1024 {
+		previewBase64 = "data:image/gif;base64," + base64.StdEncoding.EncodeToString(encoded)
 	}
 
 	return GifResult{
 		Success:    true,
-		Message:    "GIF 创建成功",
+		Message:    tr("gif.compressed", formatFileSize(originalSize), formatFileSize(newSize), float64(originalSize-newSize)/float64(originalSize)*100),
 		OutputPath: outputPath,
-		FileSize:   int64(buf.Len()),
-		FrameCount: len(frames),
-		Width:      int(outWidth),
-		Height:     int(outHeight),
+		FileSize:   newSize,
+		FrameCount: len(newGif.Image),
+		Width:      newWidth,
+		Height:     newHeight,
 		Preview:    previewBase64,
+		InputHash:  hashBytes(data),
 	}
 }
 
-// CompressGif 压缩 GIF 文件（带进度回调）
-func (a *App) CompressGif(gifPath string, options GifCompressOptions) GifResult {
-	// 读取 GIF 文件
-	data, err := os.ReadFile(gifPath)
-	if err != nil {
-		return GifResult{Success: false, Message: fmt.Sprintf("无法读取文件: %v", err)}
-	}
-
-	originalSize := int64(len(data))
-
+// recompressGif 解码 GIF，按选项缩放并减少颜色后重新编码，返回新的 GIF 结构和编码后的数据
+// 每处理一帧前检查 ctx，进度通过 gif-compress-progress 事件发送
+func (a *App) recompressGif(ctx context.Context, data []byte, options GifCompressOptions) (*gif.GIF, []byte, error) {
 	// 发送进度：解码中
-	runtime.EventsEmit(a.ctx, "gif-compress-progress", map[string]interface{}{
-		"stage":    "decoding",
-		"progress": 0,
-		"message":  "正在解码 GIF...",
-	})
+	a.gifProgress(ctx, "decoding", 0, tr("gif.progress.decoding"))
 
 	// 解码 GIF
+	if config, err := gif.DecodeConfig(bytes.NewReader(data)); err == nil && config.Width*config.Height > maxImagePixels {
+		return nil, nil, newError(ErrTooLarge, nil, "gif.too_large", config.Width, config.Height)
+	}
 	gifImg, err := gif.DecodeAll(bytes.NewReader(data))
 	if err != nil {
-		return GifResult{Success: false, Message: fmt.Sprintf("无法解码 GIF: %v", err)}
+		return nil, nil, newError(ErrDecode, err, "gif.decode_failed")
 	}
 
 	if len(gifImg.Image) == 0 {
-		return GifResult{Success: false, Message: "GIF 文件没有帧"}
+		return nil, nil, newError(ErrDecode, nil, "gif.no_frames")
 	}
 
 	totalFrames := len(gifImg.Image)
@@ -210,11 +359,7 @@ func (a *App) CompressGif(gifPath string, options GifCompressOptions) GifResult
 	}
 
 	// 发送进度：生成调色板
-	runtime.EventsEmit(a.ctx, "gif-compress-progress", map[string]interface{}{
-		"stage":    "palette",
-		"progress": 5,
-		"message":  "正在生成调色板...",
-	})
+	a.gifProgress(ctx, "palette", 5, tr("gif.progress.palette"))
 
 	// 生成优化的调色板（使用快速版本）
 	palette := generateFastPalette(gifImg.Image[0], colors)
@@ -231,13 +376,14 @@ func (a *App) CompressGif(gifPath string, options GifCompressOptions) GifResult
 
 	// 处理每一帧
 	for i, frame := range gifImg.Image {
+		if err := checkCanceled(ctx); err != nil {
+			a.gifProgress(ctx, "canceled", 0, tr("job.canceled"))
+			return nil, nil, err
+		}
+
 		// 发送进度
 		progress := 10 + (i * 80 / totalFrames)
-		runtime.EventsEmit(a.ctx, "gif-compress-progress", map[string]interface{}{
-			"stage":    "processing",
-			"progress": progress,
-			"message":  fmt.Sprintf("正在处理帧 %d/%d...", i+1, totalFrames),
-		})
+		a.gifProgress(ctx, "processing", progress, tr("gif.progress.frame", i+1, totalFrames))
 
 		var processedFrame image.Image = frame
 
@@ -259,58 +405,28 @@ func (a *App) CompressGif(gifPath string, options GifCompressOptions) GifResult
 	}
 
 	// 发送进度：编码中
-	runtime.EventsEmit(a.ctx, "gif-compress-progress", map[string]interface{}{
-		"stage":    "encoding",
-		"progress": 90,
-		"message":  "正在编码 GIF...",
-	})
+	a.gifProgress(ctx, "encoding", 90, tr("gif.progress.encoding"))
 
-	// 生成输出路径
-	outputDir := options.OutputDir
-	if outputDir == "" {
-		outputDir = filepath.Dir(gifPath)
-	}
-
-	baseName := strings.TrimSuffix(filepath.Base(gifPath), filepath.Ext(gifPath))
-	outputPath := filepath.Join(outputDir, baseName+"_compressed.gif")
-
-	// 编码并保存
+	// 编码
 	var buf bytes.Buffer
-	err = gif.EncodeAll(&buf, newGif)
-	if err != nil {
-		return GifResult{Success: false, Message: fmt.Sprintf("GIF 编码失败: %v", err)}
+	if err := gif.EncodeAll(&buf, newGif); err != nil {
+		return nil, nil, newError(ErrEncode, err, "gif.encode_failed")
 	}
-
-	err = os.WriteFile(outputPath, buf.Bytes(), 0644)
-	if err != nil {
-		return GifResult{Success: false, Message: fmt.Sprintf("保存失败: %v", err)}
+	if err := checkCanceled(ctx); err != nil {
+		a.gifProgress(ctx, "canceled", 0, tr("job.canceled"))
+		return nil, nil, err
 	}
+	return newGif, buf.Bytes(), nil
+}
 
-	newSize := int64(buf.Len())
-
-	// 发送进度：完成
-	runtime.EventsEmit(a.ctx, "gif-compress-progress", map[string]interface{}{
-		"stage":    "done",
-		"progress": 100,
-		"message":  "压缩完成!",
+// gifProgress 发送 GIF 压缩进度事件，在队列中执行时同时更新任务进度
+func (a *App) gifProgress(ctx context.Context, stage string, progress int, message string) {
+	a.emit("gif-compress-progress", map[string]interface{}{
+		"stage":    stage,
+		"progress": progress,
+		"message":  message,
 	})
-
-	// 生成预览
-	previewBase64 := ""
-	if buf.Len() < 2*1024*1024 {
-		previewBase64 = "data:image/gif;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
-	}
-
-	return GifResult{
-		Success:    true,
-		Message:    fmt.Sprintf("压缩完成！原始: %s → 压缩后: %s (节省 %.1f%%)", formatFileSize(originalSize), formatFileSize(newSize), float64(originalSize-newSize)/float64(originalSize)*100),
-		OutputPath: outputPath,
-		FileSize:   newSize,
-		FrameCount: len(newGif.Image),
-		Width:      int(newWidth),
-		Height:     int(newHeight),
-		Preview:    previewBase64,
-	}
+	reportProgress(ctx, progress, message)
 }
 
 // generatePalette 从图像生成 256 色调色板
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// matchGlob 判断相对路径是否匹配 glob 模式
+// 支持 * ? [..] 以及跨目录的 **；不含 "/" 的模式只匹配文件名（如 "*.png"）
+func matchGlob(pattern, relPath string) bool {
+	pattern = filepath.ToSlash(strings.TrimPrefix(pattern, "./"))
+	relPath = filepath.ToSlash(relPath)
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(relPath))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"))
+}
+
+// matchSegments 逐段匹配，"**" 可匹配零个或多个目录
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		parts = parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// matchAnyGlob 判断路径是否匹配任意一个模式
+func matchAnyGlob(patterns []string, relPath string) bool {
+	for _, p := range patterns {
+		if matchGlob(p, relPath) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.png", "logo.png", true},
+		{"*.png", "icons/a/logo.png", true}, // 不含 "/" 时只匹配文件名
+		{"*.png", "logo.jpg", false},
+		{"icons/*.png", "icons/logo.png", true},
+		{"icons/*.png", "icons/sub/logo.png", false},
+		{"icons/**/*.png", "icons/logo.png", true}, // ** 可以匹配零个目录
+		{"icons/**/*.png", "icons/a/b/logo.png", true},
+		{"icons/**/*.png", "other/icons/logo.png", false},
+		{"icons/**", "icons/a/b/logo.png", true},
+		{"**/thumb-?.jpg", "a/b/thumb-1.jpg", true},
+		{"**/thumb-?.jpg", "a/b/thumb-12.jpg", false},
+		{"photos/[ab]*/*.jpg", "photos/album/x.jpg", true},
+		{"photos/[ab]*/*.jpg", "photos/cats/x.jpg", false},
+		{"./photos/*.jpg", "photos/x.jpg", true},
+		{"photos/*.jpg", filepath.Join("photos", "x.jpg"), true},
+		{"photos", "photos/x.jpg", false},
+	}
+	for _, c := range cases {
+		if got := matchGlob(c.pattern, c.path); got != c.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+	if !matchAnyGlob([]string{"*.gif", "icons/**"}, "icons/a.png") || matchAnyGlob(nil, "a.png") {
+		t.Error("matchAnyGlob")
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"image-compressor/squashpb"
+)
+
+// grpcService gRPC 接口（proto/squash/v1/squash.proto），与 HTTP API 共用请求体大小上限和处理槽位
+// 请求的截止时间通过 ctx 传入引擎，到期后与取消操作相同
+type grpcService struct {
+	squashpb.UnimplementedSquashServer
+	api *apiServer
+}
+
+// newGRPCServer 创建提供 Squash 服务的 gRPC 服务器
+func newGRPCServer(api *apiServer) *grpc.Server {
+	// 消息中带有完整的图片数据，上限与 HTTP 请求体相同，并为其他字段留出余量
+	maxMsg := int(api.maxBody) + 1<<20
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(maxMsg),
+		grpc.MaxSendMsgSize(maxMsg),
+		grpc.ChainUnaryInterceptor(logUnaryRPC),
+		grpc.ChainStreamInterceptor(logStreamRPC),
+	)
+	squashpb.RegisterSquashServer(srv, &grpcService{api: api})
+	return srv
+}
+
+// logUnaryRPC 记录 gRPC 调用日志，与 HTTP 访问日志对应
+func logUnaryRPC(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logger.Info("grpc", "method", info.FullMethod, "code", status.Code(err).String(), since(start))
+	return resp, err
+}
+
+func logStreamRPC(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logger.Info("grpc", "method", info.FullMethod, "code", status.Code(err).String(), since(start))
+	return err
+}
+
+// Compress 压缩一张图片
+func (g *grpcService) Compress(ctx context.Context, req *squashpb.CompressRequest) (*squashpb.CompressResponse, error) {
+	return g.compress(ctx, req.GetName(), req.GetData(), req.GetOptions())
+}
+
+// CompressStream 接收分块上传的图片后压缩，累计大小超过上限时中止
+func (g *grpcService) CompressStream(stream squashpb.Squash_CompressStreamServer) error {
+	var name string
+	var options *squashpb.CompressOptions
+	var data []byte
+	for first := true; ; first = false {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			name, options = chunk.GetName(), chunk.GetOptions()
+		}
+		if int64(len(data)+len(chunk.GetData())) > g.api.maxBody {
+			return grpcError(stream.Context(), newError(ErrTooLarge, nil, "server.body_too_large", formatFileSize(g.api.maxBody)))
+		}
+		data = append(data, chunk.GetData()...)
+	}
+
+	resp, err := g.compress(stream.Context(), name, data, options)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// compress 按选项压缩图片数据
+func (g *grpcService) compress(ctx context.Context, name string, data []byte, o *squashpb.CompressOptions) (*squashpb.CompressResponse, error) {
+	if len(data) == 0 {
+		return nil, grpcError(ctx, newError(ErrInvalidOptions, nil, "server.no_file"))
+	}
+	if name == "" {
+		name = "image" + sniffExtension(data)
+	}
+	options := CompressOptions{
+		Quality:       int(o.GetQuality()),
+		MaxWidth:      uint(o.GetMaxWidth()),
+		MaxHeight:     uint(o.GetMaxHeight()),
+		OutputFormat:  o.GetOutputFormat(),
+		KeepAspect:    o.GetKeepAspect(),
+		Force:         o.GetForce(),
+		AcceptFormats: o.GetAcceptFormats(),
+		Background:    o.GetBackground(),
+
+		JPEGProgressive: o.GetJpegProgressive(),
+		JPEGSubsampling: o.GetJpegSubsampling(),
+		JPEGQuantTable:  o.GetJpegQuantTable(),
+		JPEGLossless:    o.GetJpegLossless(),
+		PNGEffort:       int(o.GetPngEffort()),
+	}
+	if options.Quality == 0 {
+		options.Quality = 80
+	}
+	if options.OutputFormat == "" {
+		options.OutputFormat = "original"
+	}
+	if options.Quality < 1 || options.Quality > 100 {
+		return nil, grpcError(ctx, newError(ErrInvalidOptions, nil, "server.invalid_quality", options.Quality))
+	}
+
+	if err := g.api.acquire(ctx); err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	defer g.api.release()
+
+	start := time.Now()
+	enc, cached, err := compressBytes(ctx, data, name, options)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	originalSize, newSize := int64(len(data)), int64(len(enc.Data))
+	result := &squashpb.CompressResult{
+		OriginalSize:     originalSize,
+		NewSize:          newSize,
+		OriginalWidth:    int32(enc.OriginalWidth),
+		OriginalHeight:   int32(enc.OriginalHeight),
+		NewWidth:         int32(enc.NewWidth),
+		NewHeight:        int32(enc.NewHeight),
+		CompressionRatio: float64(originalSize-newSize) / float64(originalSize) * 100,
+		Cached:           cached,
+		InputHash:        hashBytes(data),
+		InputFormat:      enc.InputFormat,
+		OutputFormat:     enc.Format,
+		Quality:          int32(options.Quality),
+		DurationMs:       time.Since(start).Milliseconds(),
+		Lossless:         enc.Lossless,
+	}
+	for _, c := range enc.Candidates {
+		result.FormatCandidates = append(result.FormatCandidates, &squashpb.FormatCandidate{Format: c.Format, Size: c.Size})
+	}
+	if d := describeFormatDecision(enc.Decision); d != nil {
+		result.FormatDecision = &squashpb.FormatDecision{
+			Mode:         d.Mode,
+			Format:       d.Format,
+			Reason:       d.Reason,
+			UniqueColors: int32(d.UniqueColors),
+			HasAlpha:     d.HasAlpha,
+			FlatRatio:    d.FlatRatio,
+			EdgeRatio:    d.EdgeRatio,
+			NoiseRatio:   d.NoiseRatio,
+		}
+	}
+	if enc.UseOriginal {
+		result.Warnings = append(result.Warnings, tr("compress.warn_kept_original"))
+	}
+	if enc.Flattened != "" {
+		result.Warnings = append(result.Warnings, tr("compress.warn_alpha_flattened", enc.Flattened))
+	}
+
+	ext := filepath.Ext(name)
+	return &squashpb.CompressResponse{
+		Result:   result,
+		Data:     enc.Data,
+		MimeType: enc.MimeType,
+		Name:     strings.TrimSuffix(name, ext) + outputExtension(enc.Format, ext),
+	}, nil
+}
+
+// CreateAnimation 由序列帧生成 GIF
+func (g *grpcService) CreateAnimation(ctx context.Context, req *squashpb.CreateAnimationRequest) (*squashpb.CreateAnimationResponse, error) {
+	if len(req.GetFrames()) == 0 {
+		return nil, grpcError(ctx, newError(ErrInvalidOptions, nil, "server.no_file"))
+	}
+	uploads := make([]upload, 0, len(req.GetFrames()))
+	for i, f := range req.GetFrames() {
+		name := f.GetName()
+		if name == "" {
+			name = fmt.Sprintf("frame%06d", i) // 没有文件名时保持请求中的顺序
+		}
+		uploads = append(uploads, upload{name: name, data: f.GetData(), size: int64(len(f.GetData()))})
+	}
+	o := req.GetOptions()
+	options := GifOptions{
+		FrameDelay: int(o.GetFrameDelay()),
+		LoopCount:  int(o.GetLoopCount()),
+		MaxWidth:   uint(o.GetMaxWidth()),
+		MaxHeight:  uint(o.GetMaxHeight()),
+	}
+
+	if err := g.api.acquire(ctx); err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	defer g.api.release()
+
+	frames, _, err := decodeFrames(ctx, uploads)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	gifImg, encoded, err := encodeGifSequence(ctx, frames, options)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return &squashpb.CreateAnimationResponse{
+		Data:       encoded,
+		FileSize:   int64(len(encoded)),
+		FrameCount: int32(len(gifImg.Image)),
+		Width:      int32(gifImg.Config.Width),
+		Height:     int32(gifImg.Config.Height),
+	}, nil
+}
+
+// Inspect 读取图片信息，需要时生成预览缩略图
+func (g *grpcService) Inspect(ctx context.Context, req *squashpb.InspectRequest) (*squashpb.ImageInfo, error) {
+	data := req.GetData()
+	if len(data) == 0 {
+		return nil, grpcError(ctx, newError(ErrInvalidOptions, nil, "server.no_file"))
+	}
+	name := req.GetName()
+	if name == "" {
+		name = "image" + sniffExtension(data)
+	}
+
+	img, format, err := decodeImage(data, name)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	b := img.Bounds()
+	info := &squashpb.ImageInfo{
+		Name:   name,
+		Size:   int64(len(data)),
+		Width:  int32(b.Dx()),
+		Height: int32(b.Dy()),
+		Format: format,
+	}
+	if req.GetPreview() {
+		if info.Preview, err = jpegPreview(img, 200, 80); err != nil {
+			return nil, grpcError(ctx, err)
+		}
+	}
+	return info, nil
+}
+
+// grpcError 将引擎错误转换为 gRPC 状态错误，错误码放在 ErrorInfo.reason 中
+func grpcError(ctx context.Context, err error) error {
+	code := errorCode(err)
+	c := codes.Internal
+	switch code {
+	case CodeInvalidOptions, CodeDecode, CodeUnsupportedFormat, CodeRead:
+		c = codes.InvalidArgument
+	case CodeTooLarge:
+		c = codes.ResourceExhausted
+	case CodeCanceled:
+		c = codes.Canceled
+		// 客户端超时后 gRPC 可能以取消的方式结束 ctx，按截止时间区分
+		if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+			c = codes.DeadlineExceeded
+		}
+	}
+	st := status.New(c, err.Error())
+	if detailed, derr := st.WithDetails(&errdetails.ErrorInfo{Reason: code, Domain: "squash"}); derr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 历史记录类型
+const (
+	HistoryKindImage       = "image"
+	HistoryKindGif         = "gif"
+	HistoryKindGifSequence = "gif-sequence"
+)
+
+// historyLine 历史日志中的一行：新增记录或撤销记录（日志只追加，不修改）
+type historyLine struct {
+	Op    string        `json:"op"` // "add" 或 "revert"
+	Entry *HistoryEntry `json:"entry,omitempty"`
+	ID    string        `json:"id,omitempty"` // 撤销的记录 ID
+	Time  string        `json:"time,omitempty"`
+}
+
+// historyMu 保护历史日志的读写
+var historyMu sync.Mutex
+
+// historyPath 返回历史日志路径
+func historyPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.jsonl"), nil
+}
+
+// appendHistoryLine 向历史日志追加一行
+func appendHistoryLine(line historyLine) error {
+	path, err := historyPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// loadHistory 读取全部历史记录（按时间顺序），并应用撤销记录
+func loadHistory() ([]HistoryEntry, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []HistoryEntry{}
+	index := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line historyLine
+		// 跳过损坏的行（例如崩溃时写了一半）
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		switch line.Op {
+		case "add":
+			if line.Entry != nil {
+				index[line.Entry.ID] = len(entries)
+				entries = append(entries, *line.Entry)
+			}
+		case "revert":
+			if i, ok := index[line.ID]; ok {
+				entries[i].Reverted = true
+			}
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// newHistoryID 生成历史记录 ID
+func newHistoryID() string {
+	return newTimeID()
+}
+
+// recordCompressHistory 记录图片压缩结果
+func recordCompressHistory(inputPath string, options CompressOptions, result CompressResult) error {
+	entry := HistoryEntry{
+		ID:           newHistoryID(),
+		Time:         time.Now().Format(time.RFC3339),
+		Kind:         HistoryKindImage,
+		InputPath:    absPath(inputPath),
+		InputHash:    result.InputHash,
+		Options:      options,
+		OutputPath:   absPath(result.OutputPath),
+		BackupPath:   result.BackupPath,
+		OriginalSize: result.OriginalSize,
+		NewSize:      result.NewSize,
+		Success:      result.Success,
+		Message:      result.Message,
+	}
+	if result.Success {
+		hash, err := hashFile(result.OutputPath)
+		if err != nil {
+			return err
+		}
+		entry.OutputHash = hash
+		markProduced(entry.InputHash, entry.OutputHash)
+	}
+	return appendHistoryLine(historyLine{Op: "add", Entry: &entry})
+}
+
+// recordGifHistory 记录 GIF 生成或压缩结果
+func recordGifHistory(kind, inputPath string, options interface{}, result GifResult) error {
+	entry := HistoryEntry{
+		ID:         newHistoryID(),
+		Time:       time.Now().Format(time.RFC3339),
+		Kind:       kind,
+		InputPath:  absPath(inputPath),
+		InputHash:  result.InputHash,
+		Options:    options,
+		OutputPath: absPath(result.OutputPath),
+		NewSize:    result.FileSize,
+		Success:    result.Success,
+		Message:    result.Message,
+	}
+	if kind == HistoryKindGif {
+		if stat, err := os.Stat(inputPath); err == nil {
+			entry.OriginalSize = stat.Size()
+		}
+	}
+	if result.Success {
+		hash, err := hashFile(result.OutputPath)
+		if err != nil {
+			return err
+		}
+		entry.OutputHash = hash
+		markProduced(entry.InputHash, entry.OutputHash)
+	}
+	return appendHistoryLine(historyLine{Op: "add", Entry: &entry})
+}
+
+// sequenceInputPath 序列帧的输入路径记录为所在目录
+func sequenceInputPath(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	return filepath.Dir(paths[0])
+}
+
+// absPath 返回绝对路径，失败时原样返回
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// GetHistory 获取最近的历史记录（最新的在前），limit <= 0 时返回全部
+func (a *App) GetHistory(limit int) []HistoryEntry {
+	entries, err := loadHistory()
+	if err != nil {
+		return []HistoryEntry{}
+	}
+
+	// 倒序：最新的在前
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// RevertEntry 撤销一次压缩
+// 原地优化且保留了原始文件时，从备份恢复源文件；否则删除生成的输出文件（仅当输出未被修改）
+func (a *App) RevertEntry(id string) ActionResult {
+	entries, err := loadHistory()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("history.read_failed", err)}
+	}
+
+	var entry *HistoryEntry
+	for i := range entries {
+		if entries[i].ID == id {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return ActionResult{Success: false, Message: tr("history.not_found", id)}
+	}
+	if entry.Reverted {
+		return ActionResult{Success: false, Message: tr("history.already_reverted")}
+	}
+	if !entry.Success {
+		return ActionResult{Success: false, Message: tr("history.failed_entry")}
+	}
+
+	// 当前输出与记录不一致时拒绝撤销，避免覆盖或删除用户之后的修改
+	if hash, err := hashFile(entry.OutputPath); err != nil || hash != entry.OutputHash {
+		return ActionResult{Success: false, Message: tr("history.output_changed")}
+	}
+
+	inPlace := sameFilePath(entry.InputPath, entry.OutputPath)
+	switch {
+	case inPlace && entry.BackupPath == "":
+		return ActionResult{Success: false, Message: tr("history.no_backup")}
+	case inPlace:
+		if err := a.restoreBackup(entry.BackupPath, entry.InputPath); err != nil {
+			return ActionResult{Success: false, Message: tr("restore.failed", err)}
+		}
+	default:
+		if err := os.Remove(entry.OutputPath); err != nil {
+			return ActionResult{Success: false, Message: tr("history.remove_failed", err)}
+		}
+	}
+
+	if err := appendHistoryLine(historyLine{Op: "revert", ID: id, Time: time.Now().Format(time.RFC3339)}); err != nil {
+		return ActionResult{Success: false, Message: tr("history.write_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("history.reverted")}
+}
+
+// restoreBackup 从 .orig 备份或回收站恢复原地优化前的文件
+func (a *App) restoreBackup(backupPath, originalPath string) error {
+	for _, entry := range a.ListTrash() {
+		if entry.TrashedPath == backupPath {
+			_, err := restoreFromTrash(entry.ID)
+			return err
+		}
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(backupPath)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(originalPath, data, stat.Mode().Perm()); err != nil {
+		return err
+	}
+	os.Chtimes(originalPath, stat.ModTime(), stat.ModTime())
+	return os.Remove(backupPath)
+}
+
+// ExportHistoryCSV 将历史记录导出为 CSV，用于统计节省的字节数
+func (a *App) ExportHistoryCSV(path string) ActionResult {
+	entries, err := loadHistory()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("history.read_failed", err)}
+	}
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Write([]string{"id", "time", "kind", "input_path", "input_hash", "output_path",
+		"original_size", "new_size", "saved_bytes", "success", "reverted", "message"})
+
+	var saved int64
+	for _, e := range entries {
+		savedBytes := int64(0)
+		if e.Success && !e.Reverted && e.OriginalSize > 0 {
+			savedBytes = e.OriginalSize - e.NewSize
+		}
+		saved += savedBytes
+		w.Write([]string{
+			e.ID, e.Time, e.Kind, e.InputPath, e.InputHash, e.OutputPath,
+			strconv.FormatInt(e.OriginalSize, 10),
+			strconv.FormatInt(e.NewSize, 10),
+			strconv.FormatInt(savedBytes, 10),
+			strconv.FormatBool(e.Success),
+			strconv.FormatBool(e.Reverted),
+			e.Message,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return ActionResult{Success: false, Message: tr("export.failed", err)}
+	}
+
+	if err := writeFileAtomic(path, []byte(sb.String()), 0644); err != nil {
+		return ActionResult{Success: false, Message: tr("export.failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("history.exported", len(entries), formatFileSize(saved))}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testJPEGFile 写入高质量 JPEG（以质量 60 重新压缩后一定更小），返回文件内容
+func testJPEGFile(t *testing.T, path string, img image.Image) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// testCompressEntry 压缩文件并返回对应的历史记录 ID
+func testCompressEntry(t *testing.T, a *App, path string, options CompressOptions) string {
+	t.Helper()
+	options.Quality, options.OutputFormat, options.KeepAspect = 60, "original", true
+	if r := a.CompressImage(path, options); !r.Success || r.Skipped || r.NewSize >= r.OriginalSize {
+		t.Fatalf("compress %s: %+v", filepath.Base(path), r.Message)
+	}
+	return a.GetHistory(1)[0].ID
+}
+
+// 撤销：原地优化从这次的备份恢复，其他情况删除输出；输出被改动、已撤销或没有备份时拒绝
+func TestRevertEntry(t *testing.T) {
+	testDataDirs(t)
+	a := NewApp()
+	dir := t.TempDir()
+
+	// 写到其他位置：撤销时删除输出
+	photo := filepath.Join(dir, "photo.jpg")
+	v0 := testJPEGFile(t, photo, testPhoto(64, 48))
+	id := testCompressEntry(t, a, photo, CompressOptions{})
+	output := filepath.Join(dir, "photo.min.jpg")
+	if r := a.RevertEntry(id); !r.Success {
+		t.Fatalf("revert output: %s", r.Message)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("output still exists: %v", err)
+	}
+	testFileContent(t, photo, v0)
+	if r := a.RevertEntry(id); r.Success || r.Message != tr("history.already_reverted") {
+		t.Errorf("second revert: %+v", r)
+	}
+
+	// 输出被改动后拒绝撤销
+	id = testCompressEntry(t, a, photo, CompressOptions{})
+	os.WriteFile(output, []byte("edited"), 0644)
+	if r := a.RevertEntry(id); r.Success || r.Message != tr("history.output_changed") {
+		t.Errorf("changed output: %+v", r)
+	}
+
+	// 原地优化两次（之间文件被换成新内容）：各自撤销到这次优化前的内容
+	first := testCompressEntry(t, a, photo, CompressOptions{InPlace: true, Backup: BackupOrig})
+	if r := a.RevertEntry(first); !r.Success {
+		t.Fatalf("revert in place: %s", r.Message)
+	}
+	testFileContent(t, photo, v0)
+	testCompressEntry(t, a, photo, CompressOptions{InPlace: true, Backup: BackupOrig})
+	v1 := testJPEGFile(t, photo, testPhoto(48, 64))
+	second := testCompressEntry(t, a, photo, CompressOptions{InPlace: true, Backup: BackupOrig})
+	if r := a.RevertEntry(second); !r.Success {
+		t.Fatalf("revert second in place: %s", r.Message)
+	}
+	testFileContent(t, photo, v1)
+	testFileContent(t, photo+".orig", v0)
+	if _, err := os.Stat(photo + ".orig.1"); !os.IsNotExist(err) {
+		t.Errorf(".orig.1 still exists: %v", err)
+	}
+
+	// 放入回收站的备份同样可以恢复
+	trashed := filepath.Join(dir, "trashed.jpg")
+	v2 := testJPEGFile(t, trashed, testPhoto(40, 40))
+	id = testCompressEntry(t, a, trashed, CompressOptions{InPlace: true, Backup: BackupTrash})
+	if r := a.RevertEntry(id); !r.Success {
+		t.Fatalf("revert from trash: %s", r.Message)
+	}
+	testFileContent(t, trashed, v2)
+
+	// 没有备份的原地优化不能撤销
+	id = testCompressEntry(t, a, trashed, CompressOptions{InPlace: true})
+	if r := a.RevertEntry(id); r.Success || r.Message != tr("history.no_backup") {
+		t.Errorf("no backup: %+v", r)
+	}
+	if r := a.RevertEntry("missing"); r.Success {
+		t.Errorf("missing entry: %+v", r)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// 支持的界面语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEn   = "en"
+)
+
+// catalogs 各语言的消息表
+var catalogs = map[string]map[string]string{
+	LocaleZhCN: messagesZhCN,
+	LocaleEn:   messagesEn,
+}
+
+// 当前语言，首次使用时由设置或系统语言决定
+var (
+	localeMu   sync.RWMutex
+	localeOnce sync.Once
+	locale     string
+)
+
+// currentLocale 返回当前界面语言
+func currentLocale() string {
+	localeOnce.Do(func() {
+		l := loadSettings().Locale
+		if l == "" {
+			l = systemLocale()
+		}
+		localeMu.Lock()
+		locale = normalizeLocale(l)
+		localeMu.Unlock()
+	})
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	return locale
+}
+
+// setLocale 切换界面语言（不持久化）
+func setLocale(l string) {
+	currentLocale()
+	localeMu.Lock()
+	locale = l
+	localeMu.Unlock()
+}
+
+// normalizeLocale 将系统语言标识（如 zh_CN.UTF-8、zh-Hans、en-US）归为支持的语言
+// 中文和未知语言使用简体中文，其余语言使用英文
+func normalizeLocale(l string) string {
+	l = strings.ToLower(strings.TrimSpace(l))
+	if l == "" || strings.HasPrefix(l, "zh") {
+		return LocaleZhCN
+	}
+	return LocaleEn
+}
+
+// tr 返回当前语言的消息，带参数时按格式化字符串处理
+// 当前语言缺少的消息回退到简体中文，仍缺少时返回消息 ID
+func tr(id string, args ...interface{}) string {
+	msg, ok := catalogs[currentLocale()][id]
+	if !ok {
+		msg, ok = messagesZhCN[id]
+	}
+	if !ok {
+		msg = id
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// GetLocale 返回当前界面语言
+func (a *App) GetLocale() string {
+	return currentLocale()
+}
+
+// GetLocales 返回支持的界面语言
+func (a *App) GetLocales() []string {
+	return []string{LocaleZhCN, LocaleEn}
+}
+
+// SetLocale 切换界面语言并保存，之后的消息和窗口标题使用新语言
+func (a *App) SetLocale(l string) ActionResult {
+	if _, ok := catalogs[l]; !ok {
+		return ActionResult{Success: false, Message: tr("locale.unsupported", l)}
+	}
+	setLocale(l)
+	if err := updateSettings(func(s *appSettings) { s.Locale = l }); err != nil {
+		return ActionResult{Success: false, Message: tr("settings.save_failed", err)}
+	}
+	if a.ctx != nil {
+		runtime.WindowSetTitle(a.ctx, tr("app.title"))
+	}
+	a.emit("locale-changed", l)
+	return ActionResult{Success: true, Message: tr("locale.changed")}
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imageProxy 按 URL 中的处理选项实时缩放和转换本地图片（与 imgproxy 的 URL 格式兼容的子集），
+// 如 /img/rs:fit:800:600/q:75/f:webp/photos/hero.jpg，结果缓存在磁盘上并通过 ETag/Last-Modified 校验
+type imageProxy struct {
+	api  *apiServer
+	root *os.Root // 图片根目录，路径不能逃出根目录（包括符号链接）
+}
+
+// 缩放方式
+const (
+	ProxyResizeFit   = "fit"   // 等比缩放到框内（默认）
+	ProxyResizeFill  = "fill"  // 等比缩放并居中裁剪到给定尺寸
+	ProxyResizeForce = "force" // 拉伸到给定尺寸
+	ProxyResizeAuto  = "auto"  // 原图与目标方向相同时为 fill，否则为 fit
+)
+
+// proxyOptions URL 中的处理选项
+type proxyOptions struct {
+	ResizeType string `json:"rt"`
+	Width      uint   `json:"w"`
+	Height     uint   `json:"h"`
+	Quality    int    `json:"q"`
+	Format     string `json:"f"`                // 为空时保持原格式
+	Accept     string `json:"accept,omitempty"` // f:best 时由 Accept 头得到的可接受格式
+}
+
+// proxyPrefix 图片 URL 处理的路径前缀，与 /output、/formats 等接口分开，根目录下的任何路径都可访问
+const proxyPrefix = "/img/"
+
+// enableProxy 在 GET /img/<选项>/<路径> 上提供 dir 中的图片
+func (s *apiServer) enableProxy(dir string) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return newError(ErrRead, err, "proxy.root_failed", dir)
+	}
+	p := &imageProxy{api: s, root: root}
+	s.mux.HandleFunc("GET "+proxyPrefix+"{path...}", p.handle)
+	return nil
+}
+
+// parseProxyPath 解析 URL 路径中的处理选项和图片路径
+// 选项为 名称:参数 形式的路径段，直到第一个不是选项的段；路径末尾的 @格式 等同于 f:格式，
+// @ 后面不是输出格式时（如 logo@2x.png）是文件名的一部分
+func parseProxyPath(urlPath string) (proxyOptions, string, error) {
+	opts := proxyOptions{ResizeType: ProxyResizeFit, Quality: 80}
+	segments := strings.Split(strings.TrimPrefix(urlPath, "/"), "/")
+
+	i := 0
+	for ; i < len(segments); i++ {
+		name, value, ok := strings.Cut(segments[i], ":")
+		if !ok || !isProxyOption(name) {
+			break
+		}
+		if err := opts.set(name, strings.Split(value, ":")); err != nil {
+			return opts, "", err
+		}
+	}
+
+	rel := strings.Join(segments[i:], "/")
+	if at := strings.LastIndex(rel, "@"); at > strings.LastIndex(rel, "/") && isProxyFormat(rel[at+1:]) {
+		if err := opts.set("f", []string{rel[at+1:]}); err != nil {
+			return opts, "", err
+		}
+		rel = rel[:at]
+	}
+	// 两边都指定时 force/fill 会按这个尺寸分配内存，解析时就拒绝过大的目标
+	if err := checkResizeSize(float64(opts.Width), float64(opts.Height)); err != nil {
+		return opts, "", err
+	}
+	rel = path.Clean("/" + rel)[1:]
+	if rel == "" {
+		return opts, "", newError(ErrInvalidOptions, nil, "proxy.no_path")
+	}
+	return opts, rel, nil
+}
+
+// isProxyFormat 判断 @ 后缀是否为输出格式
+func isProxyFormat(suffix string) bool {
+	f := strings.ToLower(suffix)
+	return isOutputFormat(f) || f == "best"
+}
+
+// isProxyOption 判断路径段是否为处理选项
+func isProxyOption(name string) bool {
+	switch name {
+	case "rs", "resize", "s", "size", "rt", "resizing_type", "w", "width", "h", "height", "q", "quality", "f", "format", "ext":
+		return true
+	}
+	return false
+}
+
+// set 设置一个处理选项，args 为冒号分隔的参数，空参数保持原值
+func (o *proxyOptions) set(name string, args []string) error {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	var err error
+	switch name {
+	case "rs", "resize":
+		err = o.setResizeType(arg(0))
+		if err == nil {
+			err = o.setSize(arg(1), arg(2))
+		}
+	case "s", "size":
+		err = o.setSize(arg(0), arg(1))
+	case "rt", "resizing_type":
+		err = o.setResizeType(arg(0))
+	case "w", "width":
+		err = o.setSize(arg(0), "")
+	case "h", "height":
+		err = o.setSize("", arg(0))
+	case "q", "quality":
+		q, perr := strconv.Atoi(arg(0))
+		if perr != nil || q < 1 || q > 100 {
+			return newError(ErrInvalidOptions, nil, "proxy.bad_option", name, strings.Join(args, ":"))
+		}
+		o.Quality = q
+	case "f", "format", "ext":
+		f := strings.ToLower(arg(0))
+		if f == "jpg" {
+			f = "jpeg"
+		}
+		if !isOutputFormat(f) && f != "best" {
+			return newError(ErrUnsupportedFormat, nil, "err.unsupported_output", arg(0))
+		}
+		o.Format = f
+	}
+	if err != nil {
+		return newError(ErrInvalidOptions, nil, "proxy.bad_option", name, strings.Join(args, ":"))
+	}
+	return nil
+}
+
+func (o *proxyOptions) setResizeType(t string) error {
+	switch t {
+	case "":
+	case ProxyResizeFit, ProxyResizeFill, ProxyResizeForce, ProxyResizeAuto:
+		o.ResizeType = t
+	default:
+		return errors.New("bad resizing type")
+	}
+	return nil
+}
+
+func (o *proxyOptions) setSize(w, h string) error {
+	for _, v := range []struct {
+		s   string
+		dst *uint
+	}{{w, &o.Width}, {h, &o.Height}} {
+		if v.s == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(v.s, 10, 32)
+		if err != nil {
+			return err
+		}
+		*v.dst = uint(n)
+	}
+	return nil
+}
+
+// proxyCacheKey 由图片路径、大小、修改时间和处理选项计算缓存键，源文件变化后自动失效
+func proxyCacheKey(rel string, info fs.FileInfo, opts proxyOptions) string {
+	data, _ := json.Marshal(struct {
+		Version int          `json:"v"`
+		Path    string       `json:"path"`
+		Size    int64        `json:"size"`
+		ModTime int64        `json:"mtime"`
+		Options proxyOptions `json:"options"`
+	}{cacheVersion, rel, info.Size(), info.ModTime().UnixNano(), opts})
+	return hashBytes(append([]byte("proxy:"), data...))
+}
+
+// handle 返回处理后的图片，客户端缓存仍然有效时返回 304
+func (p *imageProxy) handle(w http.ResponseWriter, r *http.Request) {
+	opts, rel, err := parseProxyPath(r.PathValue("path"))
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	if opts.Format == "best" {
+		// 结果随 Accept 头变化，可接受的格式参与缓存键和 ETag
+		opts.Accept = acceptKey(CompressOptions{OutputFormat: "best", AcceptFormats: parseAcceptHeader(r.Header.Get("Accept"))})
+		w.Header().Add("Vary", "Accept")
+	}
+	info, err := p.root.Stat(rel)
+	if err == nil && info.IsDir() {
+		err = fs.ErrNotExist
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			writeJSON(w, http.StatusNotFound, apiError{Code: CodeRead, Message: tr("proxy.not_found", rel)})
+			return
+		}
+		writeAPIError(w, newError(ErrRead, err, "err.read_file"))
+		return
+	}
+
+	key := proxyCacheKey(rel, info, opts)
+	etag := `"` + key[:32] + `"`
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache") // 开发服务器：每次都向服务端校验，源文件修改后立即生效
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		h.Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	enc, hit := loadCompressCache(key, nil)
+	if hit && enc.UseOriginal {
+		if enc.Data, err = p.root.ReadFile(rel); err != nil {
+			hit = false
+		}
+	}
+	if !hit {
+		if err := p.api.acquire(r.Context()); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		enc, err = p.render(r.Context(), rel, opts)
+		p.api.release()
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		if err := saveCompressCache(key, enc); err != nil {
+			logger.Warn("cache write failed", "path", rel, "error", err)
+		}
+	}
+	logger.Debug("proxy", "path", rel, "options", opts, "cached", hit, "size", len(enc.Data))
+
+	h.Set("Content-Type", enc.MimeType)
+	h.Set("X-Cache", map[bool]string{true: "HIT", false: "MISS"}[hit])
+	h.Set("X-Width", strconv.Itoa(enc.NewWidth))
+	h.Set("X-Height", strconv.Itoa(enc.NewHeight))
+	http.ServeContent(w, r, "", info.ModTime(), bytes.NewReader(enc.Data))
+}
+
+// render 读取图片，按选项缩放后编码（与 CompressImage 使用相同的缩放和编码流程）
+func (p *imageProxy) render(ctx context.Context, rel string, opts proxyOptions) (encodedImage, error) {
+	start := time.Now()
+	data, err := p.root.ReadFile(rel)
+	if err != nil {
+		return encodedImage{}, newError(ErrRead, err, "err.read_file")
+	}
+	img, format, err := decodeImage(data, rel)
+	if err != nil {
+		return encodedImage{}, err
+	}
+	if err := checkCanceled(ctx); err != nil {
+		return encodedImage{}, err
+	}
+
+	b := img.Bounds()
+	resizeType := opts.ResizeType
+	if resizeType == ProxyResizeAuto {
+		resizeType = ProxyResizeFit
+		if opts.Width > 0 && opts.Height > 0 && (opts.Width >= opts.Height) == (b.Dx() >= b.Dy()) {
+			resizeType = ProxyResizeFill
+		}
+	}
+	resized := img
+	switch {
+	case resizeType == ProxyResizeFill && opts.Width > 0 && opts.Height > 0:
+		if resized, err = tinifyResize(img, "cover", int(opts.Width), int(opts.Height)); err != nil {
+			return encodedImage{}, err
+		}
+	case resizeType == ProxyResizeForce:
+		resized, err = resizeImage(img, opts.Width, opts.Height, false)
+	default:
+		resized, err = resizeImage(img, opts.Width, opts.Height, true)
+	}
+	if err != nil {
+		return encodedImage{}, err
+	}
+
+	outputFormat := opts.Format
+	if outputFormat == "" {
+		outputFormat = format
+	}
+	var encoded []byte
+	var mimeType string
+	var candidates []FormatCandidate
+	if outputFormat == "best" {
+		var accept []string
+		if opts.Accept != "" {
+			accept = strings.Split(opts.Accept, ",")
+		}
+		outputFormat, encoded, mimeType, candidates, err = encodeBestFormat(ctx, resized, format, accept, opts.Quality, defaultBackground, defaultJPEGOptions, PNGEffortOff)
+	} else {
+		if !isOutputFormat(outputFormat) {
+			return encodedImage{}, newError(ErrUnsupportedFormat, nil, "err.unsupported_output", outputFormat)
+		}
+		encoded, mimeType, err = encodeImage(ctx, resized, outputFormat, opts.Quality)
+	}
+	if err != nil {
+		return encodedImage{}, withKind(ErrEncode, err, "err.compress")
+	}
+
+	nb := resized.Bounds()
+	enc := encodedImage{
+		Data:           encoded,
+		InputFormat:    format,
+		Format:         outputFormat,
+		MimeType:       mimeType,
+		OriginalWidth:  b.Dx(),
+		OriginalHeight: b.Dy(),
+		NewWidth:       nb.Dx(),
+		NewHeight:      nb.Dy(),
+		Candidates:     candidates,
+	}
+	// 与压缩相同：格式和尺寸不变但结果更大时使用原文件
+	if sameImageFormat(outputFormat, format) && nb.Dx() == b.Dx() && nb.Dy() == b.Dy() && len(encoded) >= len(data) {
+		enc.Data, enc.UseOriginal = data, true
+	}
+	logger.Debug("proxy render", "path", rel, "format", outputFormat, "width", enc.NewWidth, "height", enc.NewHeight, since(start))
+	return enc, nil
+}
+
+// etagMatches 判断 If-None-Match 是否包含指定的 ETag
+func etagMatches(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == "*" || v == etag {
+			return true
+		}
+	}
+	return false
+}
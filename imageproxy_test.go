@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseProxyPath(t *testing.T) {
+	cases := []struct {
+		path string
+		want proxyOptions
+		rel  string
+		err  error
+	}{
+		{"/photo.jpg", proxyOptions{ResizeType: ProxyResizeFit, Quality: 80}, "photo.jpg", nil},
+		{"/rs:fill:300:200/q:60/a/b.png", proxyOptions{ResizeType: ProxyResizeFill, Width: 300, Height: 200, Quality: 60}, "a/b.png", nil},
+		{"/w:100/h:50/rt:force/x.png", proxyOptions{ResizeType: ProxyResizeForce, Width: 100, Height: 50, Quality: 80}, "x.png", nil},
+		{"/rs:fit:300/x.png", proxyOptions{ResizeType: ProxyResizeFit, Width: 300, Quality: 80}, "x.png", nil},
+		{"/s:10:20/f:jpg/x.png", proxyOptions{ResizeType: ProxyResizeFit, Width: 10, Height: 20, Quality: 80, Format: "jpeg"}, "x.png", nil},
+		{"/x.png@webp", proxyOptions{ResizeType: ProxyResizeFit, Quality: 80, Format: "webp"}, "x.png", nil},
+		{"/x.png@best", proxyOptions{ResizeType: ProxyResizeFit, Quality: 80, Format: "best"}, "x.png", nil},
+		{"/logo@2x.png", proxyOptions{ResizeType: ProxyResizeFit, Quality: 80}, "logo@2x.png", nil},
+		{"/w:100/../../etc/passwd", proxyOptions{ResizeType: ProxyResizeFit, Width: 100, Quality: 80}, "etc/passwd", nil},
+		{"/q:0/x.png", proxyOptions{}, "", ErrInvalidOptions},
+		{"/q:101/x.png", proxyOptions{}, "", ErrInvalidOptions},
+		{"/rs:stretch:10:10/x.png", proxyOptions{}, "", ErrInvalidOptions},
+		{"/w:-1/x.png", proxyOptions{}, "", ErrInvalidOptions},
+		{"/f:tiff/x.png", proxyOptions{}, "", ErrUnsupportedFormat},
+		{"/w:100", proxyOptions{}, "", ErrInvalidOptions},
+		{"/rs:force:100000:100000/x.png", proxyOptions{}, "", ErrTooLarge},
+		{"/rs:fill:16385:16384/x.png", proxyOptions{}, "", ErrTooLarge},
+		{"/rs:fill:16384:16384/x.png", proxyOptions{ResizeType: ProxyResizeFill, Width: 16384, Height: 16384, Quality: 80}, "x.png", nil},
+	}
+	for _, c := range cases {
+		opts, rel, err := parseProxyPath(c.path)
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Errorf("%s: want %v, got %v", c.path, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.path, err)
+			continue
+		}
+		if opts != c.want || rel != c.rel {
+			t.Errorf("%s: got %+v %q, want %+v %q", c.path, opts, rel, c.want, c.rel)
+		}
+	}
+}
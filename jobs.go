@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// beginJob 为一次操作创建可取消的 context，jobID 不为空时可通过 Cancel 取消
+// 操作结束后必须调用返回的 done
+func (a *App) beginJob(jobID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if jobID == "" {
+		return ctx, cancel
+	}
+
+	a.jobsMu.Lock()
+	if a.jobs == nil {
+		a.jobs = make(map[string]context.CancelFunc)
+	}
+	a.jobs[jobID] = cancel
+	a.jobsMu.Unlock()
+
+	return ctx, func() {
+		a.jobsMu.Lock()
+		delete(a.jobs, jobID)
+		a.jobsMu.Unlock()
+		cancel()
+	}
+}
+
+// Cancel 取消正在执行的操作（jobID 由调用方在选项中指定）或队列中的任务
+// 操作会在下一个检查点（帧、行或文件之间）停止，并返回错误码为 "canceled" 的结果
+func (a *App) Cancel(jobID string) ActionResult {
+	a.jobsMu.Lock()
+	cancel, ok := a.jobs[jobID]
+	a.jobsMu.Unlock()
+	if ok {
+		cancel()
+	} else if !a.queue.cancel(jobID) {
+		return ActionResult{Success: false, Message: tr("job.not_found", jobID)}
+	}
+	logger.Info("job cancel requested", "job", jobID)
+	return ActionResult{Success: true, Message: tr("job.cancel_requested")}
+}
+
+// cancelAllJobs 取消所有正在执行的操作（应用退出时）
+func (a *App) cancelAllJobs() {
+	a.jobsMu.Lock()
+	defer a.jobsMu.Unlock()
+	for _, cancel := range a.jobs {
+		cancel()
+	}
+}
+
+// checkCanceled 检查 context 是否已取消，已取消时返回 ErrCanceled 类别的错误
+func checkCanceled(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	// 用户主动取消时不附带底层错误，超时等其他原因保留原因
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return newError(ErrCanceled, nil, "job.canceled")
+	}
+	return newError(ErrCanceled, ctx.Err(), "job.canceled")
+}
+
+// progressKey 进度回调在 context 中的键
+type progressKey struct{}
+
+// withProgress 返回带进度回调的 context，任务队列用它收集各操作的进度
+func withProgress(ctx context.Context, fn func(progress int, message string)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress 报告操作进度（0-100），没有进度回调时忽略
+func reportProgress(ctx context.Context, progress int, message string) {
+	if fn, ok := ctx.Value(progressKey{}).(func(int, string)); ok {
+		fn(progress, message)
+	}
+}
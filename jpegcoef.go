@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math/bits"
+)
+
+// jpegZigzag 之字形顺序第 k 个系数在 8x8 块（行优先）中的位置
+var jpegZigzag = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// jpegComponent 一个颜色分量的量化 DCT 系数
+type jpegComponent struct {
+	id     byte
+	h, v   int // 采样因子
+	tq     int // 量化表编号
+	bw, bh int // 存储的块数（按 MCU 补齐）
+	coefs  []int16
+}
+
+// block 返回第 (bx, by) 块的 64 个系数（行优先的自然顺序）
+func (c *jpegComponent) block(bx, by int) []int16 {
+	i := (by*c.bw + bx) * 64
+	return c.coefs[i : i+64]
+}
+
+// jpegCoefficients 与熵编码无关的 JPEG 数据：尺寸、量化表和各分量的量化系数
+// 编码器由像素生成，无损优化时由原文件解码得到，写出时重新生成 Huffman 表
+type jpegCoefficients struct {
+	width, height int
+	comps         []jpegComponent
+	quant         [4][64]uint16 // 量化表（自然顺序）
+	hmax, vmax    int
+}
+
+// mcus 返回 MCU 的列数和行数
+func (c *jpegCoefficients) mcus() (int, int) {
+	return ceilDiv(c.width, 8*c.hmax), ceilDiv(c.height, 8*c.vmax)
+}
+
+// scanBlocks 返回只包含一个分量的扫描中该分量的块数（不按 MCU 补齐）
+func (c *jpegCoefficients) scanBlocks(ci int) (int, int) {
+	comp := &c.comps[ci]
+	return ceilDiv(ceilDiv(c.width*comp.h, c.hmax), 8), ceilDiv(ceilDiv(c.height*comp.v, c.vmax), 8)
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// jpegScan 一次扫描：参与的分量、频谱范围 [ss, se] 和逐次逼近位 ah/al（编码器只使用频谱选择，ah/al 为 0）
+type jpegScan struct {
+	comps          []int
+	ss, se, ah, al int
+}
+
+// jpegScripts 返回扫描脚本：顺序模式为一次包含所有系数的扫描；
+// 渐进模式先扫描所有分量的 DC，再把每个分量的 AC 按频段分开扫描（只用频谱选择，不用逐次逼近），
+// 高频段大多为零，可以用 EOB 游程跨块编码，实测比 libjpeg 的 jpeg_simple_progression 更小
+func jpegScripts(ncomp int, progressive bool) []jpegScan {
+	all := make([]int, ncomp)
+	for i := range all {
+		all[i] = i
+	}
+	if !progressive {
+		return []jpegScan{{comps: all, ss: 0, se: 63}}
+	}
+	scans := []jpegScan{{comps: all, ss: 0, se: 0}}
+	for ci := 0; ci < ncomp; ci++ {
+		if ci == 0 {
+			scans = append(scans, jpegScan{comps: []int{ci}, ss: 1, se: 2}, jpegScan{comps: []int{ci}, ss: 3, se: 8}, jpegScan{comps: []int{ci}, ss: 9, se: 63})
+		} else {
+			scans = append(scans, jpegScan{comps: []int{ci}, ss: 1, se: 2}, jpegScan{comps: []int{ci}, ss: 3, se: 63})
+		}
+	}
+	return scans
+}
+
+// writeJPEGCoefficients 写出 JPEG 文件：每次扫描先统计符号频率生成最优 Huffman 表，再编码
+// markers 为 SOI 之后原样写出的段（含标记），如 APPn
+func writeJPEGCoefficients(w io.Writer, c *jpegCoefficients, progressive bool, markers [][]byte) error {
+	bw := bufio.NewWriter(w)
+	bw.Write([]byte{0xFF, 0xD8})
+	for _, m := range markers {
+		bw.Write(m)
+	}
+	writeDQT(bw, c)
+	writeSOF(bw, c, progressive)
+
+	for _, scan := range jpegScripts(len(c.comps), progressive) {
+		counter := &scanCoder{c: c, scan: scan, counting: true}
+		counter.encode()
+
+		sc := &scanCoder{c: c, scan: scan, out: bw}
+		var dht []byte
+		for class := 0; class < 2; class++ {
+			for slot := 0; slot < 2; slot++ {
+				if !counter.used[class][slot] {
+					continue
+				}
+				counts, values := optimalHuffman(counter.freq[class][slot][:])
+				sc.codes[class][slot] = huffmanCodes(counts, values)
+				dht = append(dht, byte(class<<4|slot))
+				dht = append(dht, counts[1:]...)
+				dht = append(dht, values...)
+			}
+		}
+		if len(dht) > 0 {
+			writeSegment(bw, 0xC4, dht)
+		}
+		writeSOS(bw, c, scan)
+		sc.encode()
+	}
+	bw.Write([]byte{0xFF, 0xD9})
+	return bw.Flush()
+}
+
+// writeSegment 写出带长度的标记段
+func writeSegment(w *bufio.Writer, marker byte, payload []byte) {
+	w.Write([]byte{0xFF, marker})
+	binary.Write(w, binary.BigEndian, uint16(len(payload)+2))
+	w.Write(payload)
+}
+
+func writeDQT(w *bufio.Writer, c *jpegCoefficients) {
+	var used [4]bool
+	for _, comp := range c.comps {
+		used[comp.tq] = true
+	}
+	var p []byte
+	for tq, ok := range used {
+		if !ok {
+			continue
+		}
+		wide := false
+		for _, q := range c.quant[tq] {
+			wide = wide || q > 255
+		}
+		if wide {
+			p = append(p, byte(0x10|tq))
+			for _, z := range jpegZigzag {
+				p = binary.BigEndian.AppendUint16(p, c.quant[tq][z])
+			}
+		} else {
+			p = append(p, byte(tq))
+			for _, z := range jpegZigzag {
+				p = append(p, byte(c.quant[tq][z]))
+			}
+		}
+	}
+	writeSegment(w, 0xDB, p)
+}
+
+func writeSOF(w *bufio.Writer, c *jpegCoefficients, progressive bool) {
+	marker := byte(0xC0)
+	for _, comp := range c.comps {
+		for _, q := range c.quant[comp.tq] {
+			if q > 255 {
+				marker = 0xC1 // 16 位量化表只能用于扩展顺序模式
+			}
+		}
+	}
+	if progressive {
+		marker = 0xC2
+	}
+	p := []byte{8, byte(c.height >> 8), byte(c.height), byte(c.width >> 8), byte(c.width), byte(len(c.comps))}
+	for _, comp := range c.comps {
+		p = append(p, comp.id, byte(comp.h<<4|comp.v), byte(comp.tq))
+	}
+	writeSegment(w, marker, p)
+}
+
+func writeSOS(w *bufio.Writer, c *jpegCoefficients, scan jpegScan) {
+	p := []byte{byte(len(scan.comps))}
+	for _, ci := range scan.comps {
+		slot := huffmanSlot(ci)
+		p = append(p, c.comps[ci].id, byte(slot<<4|slot))
+	}
+	p = append(p, byte(scan.ss), byte(scan.se), byte(scan.ah<<4|scan.al))
+	writeSegment(w, 0xDA, p)
+}
+
+// huffmanSlot 第一个分量（亮度）使用 0 号 Huffman 表，其余分量共用 1 号表
+func huffmanSlot(ci int) int {
+	return min(ci, 1)
+}
+
+type huffmanCode struct {
+	code uint16
+	size uint8
+}
+
+// scanCoder 编码一次扫描；counting 时只统计符号频率，用于生成最优 Huffman 表
+type scanCoder struct {
+	c        *jpegCoefficients
+	scan     jpegScan
+	counting bool
+	freq     [2][2][257]int // [DC/AC][表][符号]
+	used     [2][2]bool
+	codes    [2][2][256]huffmanCode
+
+	out    *bufio.Writer
+	acc    uint32
+	nacc   uint
+	lastDC [4]int
+
+	eobrun int
+}
+
+func (s *scanCoder) encode() {
+	scan := s.scan
+	if len(scan.comps) > 1 {
+		mx, my := s.c.mcus()
+		for y := 0; y < my; y++ {
+			for x := 0; x < mx; x++ {
+				for _, ci := range scan.comps {
+					comp := &s.c.comps[ci]
+					for v := 0; v < comp.v; v++ {
+						for h := 0; h < comp.h; h++ {
+							s.encodeBlock(ci, comp.block(x*comp.h+h, y*comp.v+v))
+						}
+					}
+				}
+			}
+		}
+	} else {
+		ci := scan.comps[0]
+		comp := &s.c.comps[ci]
+		nbw, nbh := s.c.scanBlocks(ci)
+		for by := 0; by < nbh; by++ {
+			for bx := 0; bx < nbw; bx++ {
+				s.encodeBlock(ci, comp.block(bx, by))
+			}
+		}
+	}
+	s.emitEOBRun()
+	s.flush()
+}
+
+func (s *scanCoder) encodeBlock(ci int, b []int16) {
+	scan := s.scan
+	if scan.ss > 0 {
+		s.encodeACBand(ci, b)
+		return
+	}
+	dc := int(b[0]) >> scan.al
+	s.emitDC(ci, dc-s.lastDC[ci])
+	s.lastDC[ci] = dc
+	if scan.se > 0 {
+		s.encodeSequentialAC(ci, b)
+	}
+}
+
+// encodeSequentialAC 顺序模式的 AC 系数
+func (s *scanCoder) encodeSequentialAC(ci int, b []int16) {
+	slot := huffmanSlot(ci)
+	r := 0
+	for k := 1; k < 64; k++ {
+		v := int(b[jpegZigzag[k]])
+		if v == 0 {
+			r++
+			continue
+		}
+		for r > 15 {
+			s.emitSymbol(1, slot, 0xF0)
+			r -= 16
+		}
+		s.emitValue(1, slot, r<<4, v)
+		r = 0
+	}
+	if r > 0 {
+		s.emitSymbol(1, slot, 0x00)
+	}
+}
+
+// encodeACBand 渐进模式的一个 AC 频段，该频段全零的块累计为 EOB 游程
+func (s *scanCoder) encodeACBand(ci int, b []int16) {
+	slot := huffmanSlot(ci)
+	r := 0
+	for k := s.scan.ss; k <= s.scan.se; k++ {
+		v := int(b[jpegZigzag[k]])
+		// 点变换作用于绝对值
+		neg := v < 0
+		if neg {
+			v = -v
+		}
+		v >>= s.scan.al
+		if v == 0 {
+			r++
+			continue
+		}
+		s.emitEOBRun()
+		for r > 15 {
+			s.emitSymbol(1, slot, 0xF0)
+			r -= 16
+		}
+		if neg {
+			v = -v
+		}
+		s.emitValue(1, slot, r<<4, v)
+		r = 0
+	}
+	if r > 0 {
+		s.eobrun++
+		if s.eobrun == 0x7FFF {
+			s.emitEOBRun()
+		}
+	}
+}
+
+// emitEOBRun 输出累计的 EOB 游程
+func (s *scanCoder) emitEOBRun() {
+	if s.eobrun == 0 {
+		return
+	}
+	n := bits.Len(uint(s.eobrun)) - 1
+	s.emitSymbol(1, huffmanSlot(s.scan.comps[0]), byte(n<<4))
+	if n > 0 {
+		s.emitBits(uint32(s.eobrun), uint(n))
+	}
+	s.eobrun = 0
+}
+
+// emitDC 输出 DC 差值
+func (s *scanCoder) emitDC(ci, diff int) {
+	s.emitValue(0, huffmanSlot(ci), 0, diff)
+}
+
+// emitValue 输出符号 run|size 和 size 位的附加值（负数为反码）
+func (s *scanCoder) emitValue(class, slot, run, v int) {
+	a := v
+	if a < 0 {
+		a = -a
+		v--
+	}
+	n := bits.Len(uint(a))
+	s.emitSymbol(class, slot, byte(run|n))
+	if n > 0 {
+		s.emitBits(uint32(v)&(1<<n-1), uint(n))
+	}
+}
+
+func (s *scanCoder) emitSymbol(class, slot int, sym byte) {
+	if s.counting {
+		s.freq[class][slot][sym]++
+		s.used[class][slot] = true
+		return
+	}
+	c := s.codes[class][slot][sym]
+	s.emitBits(uint32(c.code), uint(c.size))
+}
+
+func (s *scanCoder) emitBits(v uint32, n uint) {
+	if s.counting {
+		return
+	}
+	s.acc = s.acc<<n | v&(1<<n-1)
+	s.nacc += n
+	for s.nacc >= 8 {
+		b := byte(s.acc >> (s.nacc - 8))
+		s.out.WriteByte(b)
+		if b == 0xFF {
+			s.out.WriteByte(0x00)
+		}
+		s.nacc -= 8
+	}
+}
+
+// flush 用 1 填充最后一个字节
+func (s *scanCoder) flush() {
+	if s.nacc > 0 {
+		s.emitBits(0x7F, 8-s.nacc)
+	}
+}
+
+// optimalHuffman 由符号频率生成码长不超过 16 的 Huffman 表（JPEG 标准附录 K.2，与 libjpeg 相同），
+// 返回各码长的码字数（下标 1-16）和按码长排序的符号
+func optimalHuffman(freq []int) ([]byte, []byte) {
+	var f [257]int
+	copy(f[:], freq)
+	f[256] = 1 // 保留一个码字，保证没有全 1 的码字
+
+	var codesize [257]int
+	var others [257]int
+	for i := range others {
+		others[i] = -1
+	}
+	for {
+		c1, c2 := -1, -1
+		v := int(^uint(0) >> 1)
+		for i := 0; i <= 256; i++ {
+			if f[i] != 0 && f[i] <= v {
+				v, c1 = f[i], i
+			}
+		}
+		v = int(^uint(0) >> 1)
+		for i := 0; i <= 256; i++ {
+			if f[i] != 0 && f[i] <= v && i != c1 {
+				v, c2 = f[i], i
+			}
+		}
+		if c2 < 0 {
+			break
+		}
+		f[c1] += f[c2]
+		f[c2] = 0
+		codesize[c1]++
+		for others[c1] >= 0 {
+			c1 = others[c1]
+			codesize[c1]++
+		}
+		others[c1] = c2
+		codesize[c2]++
+		for others[c2] >= 0 {
+			c2 = others[c2]
+			codesize[c2]++
+		}
+	}
+
+	var counts [33]int
+	for i := 0; i <= 256; i++ {
+		if codesize[i] > 0 {
+			counts[codesize[i]]++
+		}
+	}
+	// 将超过 16 位的码字移到较短的码长
+	for i := 32; i > 16; i-- {
+		for counts[i] > 0 {
+			j := i - 2
+			for counts[j] == 0 {
+				j--
+			}
+			counts[i] -= 2
+			counts[i-1]++
+			counts[j+1] += 2
+			counts[j]--
+		}
+	}
+	i := 16
+	for counts[i] == 0 {
+		i--
+	}
+	counts[i]-- // 去掉保留的码字
+
+	out := make([]byte, 17)
+	for i := 1; i <= 16; i++ {
+		out[i] = byte(counts[i])
+	}
+	var values []byte
+	for size := 1; size <= 32; size++ {
+		for sym := 0; sym < 256; sym++ {
+			if codesize[sym] == size {
+				values = append(values, byte(sym))
+			}
+		}
+	}
+	return out, values
+}
+
+// huffmanCodes 由码长分布和符号生成规范 Huffman 码字
+func huffmanCodes(counts, values []byte) [256]huffmanCode {
+	var codes [256]huffmanCode
+	code, k := uint16(0), 0
+	for size := 1; size <= 16; size++ {
+		for i := 0; i < int(counts[size]); i++ {
+			codes[values[k]] = huffmanCode{code: code, size: uint8(size)}
+			code++
+			k++
+		}
+		code <<= 1
+	}
+	return codes
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testSizes 覆盖不足一个块、不是 8/16 整数倍和跨多个 MCU 的尺寸
+var testSizes = [][2]int{{1, 1}, {7, 9}, {33, 17}, {64, 48}}
+
+// testPhoto 生成带渐变、边缘和噪点的测试图片，结果固定
+func testPhoto(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	seed := uint32(w*131 + h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			seed = seed*1664525 + 1013904223
+			noise := int(seed>>24) % 24
+			// 亮度上有硬边缘，色度平滑变化（抽样后仍应接近原图）
+			edge := 0
+			if (x/5+y/3)%2 == 0 {
+				edge = 60
+			}
+			r := x*160/max(w, 1) + edge + noise
+			g := y*160/max(h, 1) + edge + noise
+			b := 80 + edge + noise
+			img.Set(x, y, color.RGBA{uint8(r), uint8(g), uint8(b), 255})
+		}
+	}
+	return img
+}
+
+// testGray 生成灰度测试图片
+func testGray(w, h int) *image.Gray {
+	src := testPhoto(w, h)
+	img := image.NewGray(src.Bounds())
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, src.At(x, y))
+		}
+	}
+	return img
+}
+
+// testJPEGOptions 各种扫描方式和抽样方式的组合
+func testJPEGOptions() map[string]jpegOptions {
+	cases := make(map[string]jpegOptions)
+	for _, progressive := range []bool{false, true} {
+		for _, sub := range []string{Subsampling444, Subsampling422, Subsampling420} {
+			o := defaultJPEGOptions
+			o.progressive, o.subsampling = progressive, sub
+			cases[fmt.Sprintf("progressive=%v/%s", progressive, sub)] = o
+		}
+	}
+	return cases
+}
+
+// sameCoefficients 比较两组系数中实际编码的块（补齐 MCU 的块在单分量扫描中不编码）
+func sameCoefficients(t *testing.T, want, got *jpegCoefficients) {
+	t.Helper()
+	if want.width != got.width || want.height != got.height || len(want.comps) != len(got.comps) {
+		t.Fatalf("header: want %dx%d/%d, got %dx%d/%d", want.width, want.height, len(want.comps), got.width, got.height, len(got.comps))
+	}
+	for ci := range want.comps {
+		wc, gc := &want.comps[ci], &got.comps[ci]
+		if wc.h != gc.h || wc.v != gc.v || want.quant[wc.tq] != got.quant[gc.tq] {
+			t.Fatalf("component %d: sampling or quantization differs", ci)
+		}
+		bw, bh := want.scanBlocks(ci)
+		for by := 0; by < bh; by++ {
+			for bx := 0; bx < bw; bx++ {
+				if !slices.Equal(wc.block(bx, by), gc.block(bx, by)) {
+					t.Fatalf("component %d block (%d,%d): want %v, got %v", ci, bx, by, wc.block(bx, by), gc.block(bx, by))
+				}
+			}
+		}
+	}
+}
+
+// 编码结果能被标准库解码，重新解析出的系数与编码时完全相同
+func TestJPEGEncodeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for name, o := range testJPEGOptions() {
+		for _, size := range testSizes {
+			for _, img := range []image.Image{testPhoto(size[0], size[1]), testGray(size[0], size[1])} {
+				t.Run(fmt.Sprintf("%s/%T/%dx%d", name, img, size[0], size[1]), func(t *testing.T) {
+					want, err := jpegForwardDCT(ctx, img, 85, o)
+					if err != nil {
+						t.Fatal(err)
+					}
+					var buf bytes.Buffer
+					if err := writeJPEGCoefficients(&buf, want, o.progressive, nil); err != nil {
+						t.Fatal(err)
+					}
+
+					decoded, err := jpeg.Decode(bytes.NewReader(buf.Bytes()))
+					if err != nil {
+						t.Fatalf("image/jpeg: %v", err)
+					}
+					if decoded.Bounds() != img.Bounds() {
+						t.Fatalf("bounds: want %v, got %v", img.Bounds(), decoded.Bounds())
+					}
+					if psnr := testPSNR(img, decoded); psnr < 28 {
+						t.Errorf("PSNR %.1f dB is too low", psnr)
+					}
+
+					got, _, err := decodeJPEGCoefficients(ctx, buf.Bytes())
+					if err != nil {
+						t.Fatal(err)
+					}
+					sameCoefficients(t, want, got)
+				})
+			}
+		}
+	}
+}
+
+// testPSNR 按 RGB 计算峰值信噪比
+func testPSNR(a, b image.Image) float64 {
+	var sum float64
+	n := 0
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			ar, ag, ab, _ := a.At(x, y).RGBA()
+			br, bg, bb, _ := b.At(x, y).RGBA()
+			for _, d := range []float64{float64(ar>>8) - float64(br>>8), float64(ag>>8) - float64(bg>>8), float64(ab>>8) - float64(bb>>8)} {
+				sum += d * d
+				n++
+			}
+		}
+	}
+	if sum == 0 {
+		return 100
+	}
+	return 10 * math.Log10(255*255/(sum/float64(n)))
+}
+
+// 频率悬殊（未限制时码长超过 16）的符号生成的码表仍不超过 16 位，且满足 Kraft 不等式
+func TestOptimalHuffmanLimit(t *testing.T) {
+	freq := make([]int, 256)
+	for i, f := 0, 1; i < 40; i++ {
+		freq[i] = f
+		f = min(f*2+1, 1<<24)
+	}
+	counts, values := optimalHuffman(freq)
+	total, kraft := 0, 0
+	for length, n := range counts {
+		if n > 0 && (length < 1 || length > 16) {
+			t.Fatalf("%d codes of length %d", n, length)
+		}
+		total += int(n)
+		kraft += int(n) << (16 - min(length, 16))
+	}
+	if total != 40 || len(values) != 40 {
+		t.Fatalf("want 40 symbols, got counts %d values %d", total, len(values))
+	}
+	if kraft >= 1<<16 {
+		t.Fatalf("code is over-subscribed or has an all-ones code: %d", kraft)
+	}
+}
+
+// testQuantValues 生成 n 个从 start 开始递增的量化值（空格和逗号混合分隔）
+func testQuantValues(n, start int) (string, []uint16) {
+	var sb strings.Builder
+	var values []uint16
+	for i := 0; i < n; i++ {
+		v := start + i
+		values = append(values, uint16(v))
+		if i > 0 {
+			sb.WriteString([]string{",", ", ", " ", "\n"}[i%4])
+		}
+		sb.WriteString(strconv.Itoa(v))
+	}
+	return sb.String(), values
+}
+
+func TestParseJPEGOptions(t *testing.T) {
+	table64, values64 := testQuantValues(64, 1)
+	table128, values128 := testQuantValues(128, 2)
+	cases := []struct {
+		options      CompressOptions
+		subsampling  string
+		luma, chroma []uint16
+		progressive  bool
+		err          string      // 无效时的消息 ID
+		errArg       interface{} // 消息参数
+	}{
+		{CompressOptions{}, Subsampling420, jpegStdLuminance[:], jpegStdChrominance[:], false, "", nil},
+		{CompressOptions{JPEGProgressive: true, JPEGSubsampling: "4:4:4"}, Subsampling444, jpegStdLuminance[:], jpegStdChrominance[:], true, "", nil},
+		{CompressOptions{JPEGSubsampling: "422", JPEGQuantTable: QuantTableMozjpeg}, Subsampling422, jpegRobidoux[:], jpegRobidoux[:], false, "", nil},
+		{CompressOptions{JPEGQuantTable: " " + table64 + "\n"}, Subsampling420, values64, values64, false, "", nil},
+		{CompressOptions{JPEGQuantTable: table128}, Subsampling420, values128[:64], values128[64:], false, "", nil},
+		{CompressOptions{JPEGSubsampling: "411"}, "", nil, nil, false, "err.invalid_subsampling", "411"},
+		{CompressOptions{JPEGSubsampling: "4:2:1"}, "", nil, nil, false, "err.invalid_subsampling", "4:2:1"},
+		{CompressOptions{JPEGQuantTable: "libjpeg"}, "", nil, nil, false, "err.invalid_quant_table", 1},
+		{CompressOptions{JPEGQuantTable: table64 + ",1"}, "", nil, nil, false, "err.invalid_quant_table", 65},
+		{CompressOptions{JPEGQuantTable: table128[:strings.LastIndexAny(table128, ", \n")]}, "", nil, nil, false, "err.invalid_quant_table", 127},
+		{CompressOptions{JPEGQuantTable: "0" + table64[1:]}, "", nil, nil, false, "err.invalid_quant_value", "0"},
+		{CompressOptions{JPEGQuantTable: strings.Replace(table64, "64", "256", 1)}, "", nil, nil, false, "err.invalid_quant_value", "256"},
+		{CompressOptions{JPEGQuantTable: strings.Replace(table64, "2", "x", 1)}, "", nil, nil, false, "err.invalid_quant_value", "x"},
+	}
+	for i, c := range cases {
+		o, err := parseJPEGOptions(c.options)
+		if c.err != "" {
+			want := newError(ErrInvalidOptions, nil, c.err, c.errArg)
+			if !errors.Is(err, ErrInvalidOptions) || err.Error() != want.Error() {
+				t.Errorf("case %d: want %q, got %v", i, want, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+		if o.subsampling != c.subsampling || o.progressive != c.progressive || !slices.Equal(o.luma[:], c.luma) || !slices.Equal(o.chroma[:], c.chroma) {
+			t.Errorf("case %d: got %+v", i, o)
+		}
+	}
+}
+
+// 128 个数的自定义表：亮度和色度分别写入文件（质量 50 时不缩放）
+func TestJPEGCustomQuantTable(t *testing.T) {
+	table, values := testQuantValues(128, 3)
+	o, err := parseJPEGOptions(CompressOptions{JPEGQuantTable: table, JPEGSubsampling: Subsampling444})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := encodeJPEG(context.Background(), &buf, testPhoto(33, 17), 50, o); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("image/jpeg: %v", err)
+	}
+	c, _, err := decodeJPEGCoefficients(context.Background(), buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.comps) != 3 || c.comps[0].tq == c.comps[1].tq || c.comps[1].tq != c.comps[2].tq {
+		t.Fatalf("components %+v", c.comps)
+	}
+	if luma, chroma := c.quant[c.comps[0].tq], c.quant[c.comps[1].tq]; !slices.Equal(luma[:], values[:64]) || !slices.Equal(chroma[:], values[64:]) {
+		t.Errorf("quantization tables in file: luma %v chroma %v", luma, chroma)
+	}
+}
@@ -0,0 +1,637 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// jpegHuffmanTable 解码用的规范 Huffman 表
+type jpegHuffmanTable struct {
+	maxcode [18]int32 // 码长为 l 的最大码字，没有时为 -1
+	valptr  [17]int32 // 码长为 l 的第一个码字在 values 中的下标
+	mincode [17]int32
+	values  []byte
+}
+
+// jpegDecoder 只做熵解码的 JPEG 解码器：得到量化后的 DCT 系数，不做反量化和 IDCT
+// 支持顺序（C0/C1）和渐进（C2）Huffman 编码的 8 位 JPEG，包括重启标记和逐次逼近
+type jpegDecoder struct {
+	ctx  context.Context
+	data []byte
+	pos  int
+
+	c           *jpegCoefficients
+	progressive bool
+	quant       [4][64]uint16
+	quantSet    [4]bool
+	latched     bool // 第一次扫描开始时确定各分量使用的量化表
+	huff        [2][4]*jpegHuffmanTable
+	restart     int
+	markers     [][]byte // 文件中的 APPn 和 COM 段（含标记）
+
+	// 当前扫描
+	scan   jpegScan
+	td, ta [4]int
+	acc    uint32
+	nacc   uint
+	lastDC [4]int
+	eobrun int
+}
+
+var errJPEGTruncated = errors.New("jpeg: unexpected end of data")
+
+// decodeJPEGCoefficients 解析 JPEG 文件，返回量化系数和 APPn/COM 段
+func decodeJPEGCoefficients(ctx context.Context, data []byte) (*jpegCoefficients, [][]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, nil, errors.New("jpeg: missing SOI marker")
+	}
+	d := &jpegDecoder{ctx: ctx, data: data, pos: 2}
+	scans := 0
+	for {
+		marker, err := d.nextMarker()
+		if err != nil {
+			return nil, nil, err
+		}
+		if marker == 0xD9 {
+			break
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			continue // 不带长度的标记
+		}
+		if d.pos+2 > len(data) {
+			return nil, nil, errJPEGTruncated
+		}
+		length := int(binary.BigEndian.Uint16(data[d.pos:]))
+		if length < 2 || d.pos+length > len(data) {
+			return nil, nil, errJPEGTruncated
+		}
+		segment := data[d.pos+2 : d.pos+length]
+		start := d.pos - 2
+		d.pos += length
+
+		switch {
+		case marker == 0xC0 || marker == 0xC1 || marker == 0xC2:
+			if d.c != nil {
+				return nil, nil, errors.New("jpeg: multiple SOF markers")
+			}
+			d.progressive = marker == 0xC2
+			err = d.parseSOF(segment)
+		case marker == 0xC4:
+			err = d.parseDHT(segment)
+		case marker == 0xDB:
+			err = d.parseDQT(segment)
+		case marker == 0xDD:
+			if len(segment) != 2 {
+				return nil, nil, errors.New("jpeg: bad DRI length")
+			}
+			d.restart = int(binary.BigEndian.Uint16(segment))
+		case marker == 0xDA:
+			err = d.decodeScan(segment)
+			scans++
+		case marker >= 0xE0 && marker <= 0xEF, marker == 0xFE:
+			d.markers = append(d.markers, data[start:d.pos])
+		case marker == 0xDC:
+			err = errors.New("jpeg: DNL marker is not supported")
+		case marker >= 0xC3 && marker <= 0xCF:
+			// 无损、分层和算术编码
+			err = fmt.Errorf("jpeg: unsupported SOF marker 0x%02X", marker)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if d.c == nil || scans == 0 {
+		return nil, nil, errors.New("jpeg: no image data")
+	}
+	return d.c, d.markers, nil
+}
+
+// nextMarker 跳到下一个标记（跳过填充的 0xFF），返回标记类型
+func (d *jpegDecoder) nextMarker() (byte, error) {
+	for {
+		if d.pos+2 > len(d.data) {
+			return 0, errJPEGTruncated
+		}
+		if d.data[d.pos] != 0xFF {
+			return 0, fmt.Errorf("jpeg: expected marker at offset %d", d.pos)
+		}
+		d.pos++
+		for d.pos < len(d.data) && d.data[d.pos] == 0xFF {
+			d.pos++
+		}
+		if d.pos >= len(d.data) {
+			return 0, errJPEGTruncated
+		}
+		marker := d.data[d.pos]
+		d.pos++
+		if marker != 0x00 {
+			return marker, nil
+		}
+	}
+}
+
+func (d *jpegDecoder) parseSOF(p []byte) error {
+	if len(p) < 6 {
+		return errors.New("jpeg: bad SOF length")
+	}
+	if p[0] != 8 {
+		return fmt.Errorf("jpeg: unsupported sample precision %d", p[0])
+	}
+	c := &jpegCoefficients{
+		height: int(binary.BigEndian.Uint16(p[1:])),
+		width:  int(binary.BigEndian.Uint16(p[3:])),
+		hmax:   1,
+		vmax:   1,
+	}
+	n := int(p[5])
+	if c.width == 0 || c.height == 0 {
+		return errors.New("jpeg: image size is not defined in SOF")
+	}
+	// 重写时所有分量的 DC 在一次扫描中交织编码，最多 4 个分量、每个 MCU 最多 10 个块
+	if n < 1 || n > 4 || len(p) != 6+3*n {
+		return fmt.Errorf("jpeg: unsupported component count %d", n)
+	}
+	blocks := 0
+	for i := 0; i < n; i++ {
+		q := p[6+3*i:]
+		comp := jpegComponent{id: q[0], h: int(q[1] >> 4), v: int(q[1] & 15), tq: int(q[2])}
+		if comp.h < 1 || comp.h > 4 || comp.v < 1 || comp.v > 4 || comp.tq > 3 {
+			return errors.New("jpeg: bad component parameters")
+		}
+		for _, prev := range c.comps {
+			if prev.id == comp.id {
+				return errors.New("jpeg: duplicate component id")
+			}
+		}
+		c.hmax, c.vmax = max(c.hmax, comp.h), max(c.vmax, comp.v)
+		blocks += comp.h * comp.v
+		c.comps = append(c.comps, comp)
+	}
+	if n > 1 && blocks > 10 {
+		return errors.New("jpeg: too many blocks per MCU")
+	}
+	mx, my := c.mcus()
+	for i := range c.comps {
+		comp := &c.comps[i]
+		comp.bw, comp.bh = mx*comp.h, my*comp.v
+		comp.coefs = make([]int16, comp.bw*comp.bh*64)
+	}
+	d.c = c
+	return nil
+}
+
+func (d *jpegDecoder) parseDQT(p []byte) error {
+	for len(p) > 0 {
+		pq, tq := p[0]>>4, int(p[0]&15)
+		if pq > 1 || tq > 3 {
+			return errors.New("jpeg: bad DQT table")
+		}
+		size := 64 << pq
+		if len(p) < 1+size {
+			return errors.New("jpeg: bad DQT length")
+		}
+		for k := 0; k < 64; k++ {
+			v := uint16(p[1+k])
+			if pq == 1 {
+				v = binary.BigEndian.Uint16(p[1+2*k:])
+			}
+			if v == 0 {
+				return errors.New("jpeg: zero quantization value")
+			}
+			d.quant[tq][jpegZigzag[k]] = v
+		}
+		d.quantSet[tq] = true
+		p = p[1+size:]
+	}
+	return nil
+}
+
+func (d *jpegDecoder) parseDHT(p []byte) error {
+	for len(p) > 0 {
+		if len(p) < 17 {
+			return errors.New("jpeg: bad DHT length")
+		}
+		class, th := int(p[0]>>4), int(p[0]&15)
+		if class > 1 || th > 3 {
+			return errors.New("jpeg: bad DHT table")
+		}
+		t := &jpegHuffmanTable{}
+		total := 0
+		for l := 1; l <= 16; l++ {
+			total += int(p[l])
+		}
+		if total > 256 || len(p) < 17+total {
+			return errors.New("jpeg: bad DHT length")
+		}
+		t.values = append([]byte{}, p[17:17+total]...)
+		code, k := int32(0), int32(0)
+		for l := 1; l <= 16; l++ {
+			n := int32(p[l])
+			t.valptr[l] = k
+			t.mincode[l] = code
+			code += n
+			k += n
+			t.maxcode[l] = -1
+			if n > 0 {
+				t.maxcode[l] = code - 1
+			}
+			if code > 1<<l {
+				return errors.New("jpeg: bad Huffman code lengths")
+			}
+			code <<= 1
+		}
+		t.maxcode[17] = 0x7FFFFFFF
+		d.huff[class][th] = t
+		p = p[17+total:]
+	}
+	return nil
+}
+
+// decodeScan 解析 SOS 段并解码随后的熵编码数据
+func (d *jpegDecoder) decodeScan(p []byte) error {
+	c := d.c
+	if c == nil {
+		return errors.New("jpeg: SOS before SOF")
+	}
+	if !d.latched {
+		for _, comp := range c.comps {
+			if !d.quantSet[comp.tq] {
+				return errors.New("jpeg: missing quantization table")
+			}
+		}
+		c.quant = d.quant
+		d.latched = true
+	}
+	if len(p) < 1 {
+		return errors.New("jpeg: bad SOS length")
+	}
+	ns := int(p[0])
+	if ns < 1 || ns > len(c.comps) || len(p) != 4+2*ns {
+		return errors.New("jpeg: bad SOS length")
+	}
+	scan := jpegScan{}
+	for i := 0; i < ns; i++ {
+		id, tables := p[1+2*i], p[2+2*i]
+		ci := -1
+		for j, comp := range c.comps {
+			if comp.id == id {
+				ci = j
+			}
+		}
+		if ci < 0 {
+			return errors.New("jpeg: unknown component in SOS")
+		}
+		scan.comps = append(scan.comps, ci)
+		d.td[ci], d.ta[ci] = int(tables>>4), int(tables&15)
+		if d.td[ci] > 3 || d.ta[ci] > 3 {
+			return errors.New("jpeg: bad Huffman table selector")
+		}
+	}
+	q := p[1+2*ns:]
+	scan.ss, scan.se, scan.ah, scan.al = int(q[0]), int(q[1]), int(q[2]>>4), int(q[2]&15)
+	if d.progressive {
+		if scan.ss > scan.se || scan.se > 63 || (scan.ss == 0 && scan.se != 0) || (scan.ss > 0 && ns != 1) || scan.al > 13 {
+			return errors.New("jpeg: bad progressive scan parameters")
+		}
+	} else {
+		scan.ss, scan.se, scan.ah, scan.al = 0, 63, 0, 0
+	}
+	for _, ci := range scan.comps {
+		if (scan.ss == 0 && scan.ah == 0 && d.huff[0][d.td[ci]] == nil) || (scan.se > 0 && d.huff[1][d.ta[ci]] == nil) {
+			return errors.New("jpeg: missing Huffman table")
+		}
+	}
+	d.scan = scan
+	d.acc, d.nacc, d.eobrun = 0, 0, 0
+	d.lastDC = [4]int{}
+
+	// 交织扫描按 MCU 解码，单分量扫描按块解码（每块为一个 MCU）
+	mx, my := c.mcus()
+	if ns == 1 {
+		mx, my = c.scanBlocks(scan.comps[0])
+	}
+	mcu := 0
+	for y := 0; y < my; y++ {
+		if err := checkCanceled(d.ctx); err != nil {
+			return err
+		}
+		for x := 0; x < mx; x++ {
+			if d.restart > 0 && mcu > 0 && mcu%d.restart == 0 {
+				if err := d.readRestart(); err != nil {
+					return err
+				}
+			}
+			mcu++
+			if ns == 1 {
+				ci := scan.comps[0]
+				if err := d.decodeBlock(ci, c.comps[ci].block(x, y)); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, ci := range scan.comps {
+				comp := &c.comps[ci]
+				for v := 0; v < comp.v; v++ {
+					for h := 0; h < comp.h; h++ {
+						if err := d.decodeBlock(ci, comp.block(x*comp.h+h, y*comp.v+v)); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	return d.skipEntropyData()
+}
+
+// readRestart 丢弃剩余的位，读取 RSTn 标记并重置 DC 预测和 EOB 游程
+func (d *jpegDecoder) readRestart() error {
+	d.acc, d.nacc, d.eobrun = 0, 0, 0
+	d.lastDC = [4]int{}
+	for d.pos+1 < len(d.data) {
+		if d.data[d.pos] == 0xFF && d.data[d.pos+1] >= 0xD0 && d.data[d.pos+1] <= 0xD7 {
+			d.pos += 2
+			return nil
+		}
+		d.pos++
+	}
+	return errJPEGTruncated
+}
+
+// skipEntropyData 跳到扫描数据之后的下一个标记（不是 RSTn 和填充字节）
+func (d *jpegDecoder) skipEntropyData() error {
+	for d.pos+1 < len(d.data) {
+		if d.data[d.pos] == 0xFF {
+			next := d.data[d.pos+1]
+			if next != 0x00 && next != 0xFF && (next < 0xD0 || next > 0xD7) {
+				return nil
+			}
+			if next == 0xFF {
+				d.pos++
+				continue
+			}
+		}
+		d.pos++
+	}
+	return errJPEGTruncated
+}
+
+// decodeBlock 按当前扫描的类型解码一个块
+func (d *jpegDecoder) decodeBlock(ci int, b []int16) error {
+	scan := d.scan
+	switch {
+	case scan.ss == 0 && scan.ah > 0:
+		// DC 逐次逼近：每块一位
+		bit, err := d.readBits(1)
+		if err != nil {
+			return err
+		}
+		if bit != 0 {
+			b[0] |= 1 << scan.al
+		}
+		return nil
+	case scan.ss == 0:
+		t, err := d.decodeSymbol(d.huff[0][d.td[ci]])
+		if err != nil {
+			return err
+		}
+		if t > 11 {
+			return errors.New("jpeg: bad DC difference")
+		}
+		diff, err := d.receiveExtend(int(t))
+		if err != nil {
+			return err
+		}
+		d.lastDC[ci] += diff
+		b[0] = int16(d.lastDC[ci] << scan.al)
+		if scan.se > 0 {
+			return d.decodeSequentialAC(ci, b)
+		}
+		return nil
+	case scan.ah == 0:
+		return d.decodeACFirst(ci, b)
+	default:
+		return d.decodeACRefine(ci, b)
+	}
+}
+
+func (d *jpegDecoder) decodeSequentialAC(ci int, b []int16) error {
+	t := d.huff[1][d.ta[ci]]
+	for k := 1; k < 64; k++ {
+		rs, err := d.decodeSymbol(t)
+		if err != nil {
+			return err
+		}
+		r, s := int(rs>>4), int(rs&15)
+		if s == 0 {
+			if r != 15 {
+				return nil
+			}
+			k += 15
+			continue
+		}
+		k += r
+		if k > 63 {
+			return errors.New("jpeg: AC coefficient index out of range")
+		}
+		v, err := d.receiveExtend(s)
+		if err != nil {
+			return err
+		}
+		b[jpegZigzag[k]] = int16(v)
+	}
+	return nil
+}
+
+// decodeACFirst 渐进模式 AC 频段的第一次扫描
+func (d *jpegDecoder) decodeACFirst(ci int, b []int16) error {
+	if d.eobrun > 0 {
+		d.eobrun--
+		return nil
+	}
+	t := d.huff[1][d.ta[ci]]
+	for k := d.scan.ss; k <= d.scan.se; k++ {
+		rs, err := d.decodeSymbol(t)
+		if err != nil {
+			return err
+		}
+		r, s := int(rs>>4), int(rs&15)
+		if s == 0 {
+			if r != 15 {
+				d.eobrun = 1<<r - 1
+				if r > 0 {
+					extra, err := d.readBits(uint(r))
+					if err != nil {
+						return err
+					}
+					d.eobrun += int(extra)
+				}
+				return nil
+			}
+			k += 15
+			continue
+		}
+		k += r
+		if k > d.scan.se {
+			return errors.New("jpeg: AC coefficient index out of range")
+		}
+		v, err := d.receiveExtend(s)
+		if err != nil {
+			return err
+		}
+		b[jpegZigzag[k]] = int16(v << d.scan.al)
+	}
+	return nil
+}
+
+// decodeACRefine 渐进模式 AC 频段的逐次逼近扫描（与 libjpeg 的 decode_mcu_AC_refine 相同）
+func (d *jpegDecoder) decodeACRefine(ci int, b []int16) error {
+	p1, m1 := int16(1)<<d.scan.al, int16(-1)<<d.scan.al
+	refine := func(z int) error {
+		bit, err := d.readBits(1)
+		if err != nil {
+			return err
+		}
+		if bit != 0 && b[z]&p1 == 0 {
+			if b[z] >= 0 {
+				b[z] += p1
+			} else {
+				b[z] += m1
+			}
+		}
+		return nil
+	}
+
+	k := d.scan.ss
+	if d.eobrun == 0 {
+		t := d.huff[1][d.ta[ci]]
+		for ; k <= d.scan.se; k++ {
+			rs, err := d.decodeSymbol(t)
+			if err != nil {
+				return err
+			}
+			r, s := int(rs>>4), int(rs&15)
+			var v int16
+			if s != 0 {
+				if s != 1 {
+					return errors.New("jpeg: bad refinement value")
+				}
+				bit, err := d.readBits(1)
+				if err != nil {
+					return err
+				}
+				v = m1
+				if bit != 0 {
+					v = p1
+				}
+			} else if r != 15 {
+				d.eobrun = 1 << r
+				if r > 0 {
+					extra, err := d.readBits(uint(r))
+					if err != nil {
+						return err
+					}
+					d.eobrun += int(extra)
+				}
+				break
+			}
+			// 跳过 r 个零系数，途中的非零系数读取修正位
+			for ; k <= d.scan.se; k++ {
+				z := jpegZigzag[k]
+				if b[z] != 0 {
+					if err := refine(z); err != nil {
+						return err
+					}
+				} else {
+					if r == 0 {
+						break
+					}
+					r--
+				}
+			}
+			if v != 0 {
+				if k > d.scan.se {
+					return errors.New("jpeg: AC coefficient index out of range")
+				}
+				b[jpegZigzag[k]] = v
+			}
+		}
+	}
+	if d.eobrun > 0 {
+		for ; k <= d.scan.se; k++ {
+			if z := jpegZigzag[k]; b[z] != 0 {
+				if err := refine(z); err != nil {
+					return err
+				}
+			}
+		}
+		d.eobrun--
+	}
+	return nil
+}
+
+// fillBits 读入一个字节；遇到标记时不再前进，补 0
+func (d *jpegDecoder) fillBits() {
+	var v byte
+	if d.pos < len(d.data) {
+		v = d.data[d.pos]
+		if v == 0xFF {
+			if d.pos+1 < len(d.data) && d.data[d.pos+1] == 0x00 {
+				d.pos += 2
+			} else {
+				v = 0
+			}
+		} else {
+			d.pos++
+		}
+	}
+	d.acc = d.acc<<8 | uint32(v)
+	d.nacc += 8
+}
+
+func (d *jpegDecoder) readBits(n uint) (int, error) {
+	for d.nacc < n {
+		if d.pos >= len(d.data) {
+			return 0, errJPEGTruncated
+		}
+		d.fillBits()
+	}
+	d.nacc -= n
+	return int(d.acc>>d.nacc) & (1<<n - 1), nil
+}
+
+func (d *jpegDecoder) decodeSymbol(t *jpegHuffmanTable) (byte, error) {
+	code := int32(0)
+	for l := 1; l <= 16; l++ {
+		bit, err := d.readBits(1)
+		if err != nil {
+			return 0, err
+		}
+		code = code<<1 | int32(bit)
+		if t.maxcode[l] >= 0 && code <= t.maxcode[l] {
+			i := t.valptr[l] + code - t.mincode[l]
+			if int(i) >= len(t.values) {
+				break
+			}
+			return t.values[i], nil
+		}
+	}
+	return 0, errors.New("jpeg: bad Huffman code")
+}
+
+// receiveExtend 读取 s 位附加值并还原为有符号数
+func (d *jpegDecoder) receiveExtend(s int) (int, error) {
+	if s == 0 {
+		return 0, nil
+	}
+	v, err := d.readBits(uint(s))
+	if err != nil {
+		return 0, err
+	}
+	if v < 1<<(s-1) {
+		v += -1<<s + 1
+	}
+	return v, nil
+}
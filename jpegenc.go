@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 色度抽样方式
+const (
+	Subsampling444 = "444" // 不抽样
+	Subsampling422 = "422" // 水平方向减半
+	Subsampling420 = "420" // 水平和垂直方向都减半（默认）
+)
+
+// 量化表
+const (
+	QuantTableStandard = "standard" // JPEG 标准附录 K 的表（与 libjpeg 和标准库相同，默认）
+	QuantTableMozjpeg  = "mozjpeg"  // N. Robidoux 为 ImageMagick 调整的表，mozjpeg 的默认表
+)
+
+// jpegStdLuminance JPEG 标准附录 K 的亮度量化表（自然顺序，质量 50）
+var jpegStdLuminance = [64]uint16{
+	16, 11, 10, 16, 24, 40, 51, 61,
+	12, 12, 14, 19, 26, 58, 60, 55,
+	14, 13, 16, 24, 40, 57, 69, 56,
+	14, 17, 22, 29, 51, 87, 80, 62,
+	18, 22, 37, 56, 68, 109, 103, 77,
+	24, 35, 55, 64, 81, 104, 113, 92,
+	49, 64, 78, 87, 103, 121, 120, 101,
+	72, 92, 95, 98, 112, 100, 103, 99,
+}
+
+// jpegStdChrominance JPEG 标准附录 K 的色度量化表
+var jpegStdChrominance = [64]uint16{
+	17, 18, 24, 47, 99, 99, 99, 99,
+	18, 21, 26, 66, 99, 99, 99, 99,
+	24, 26, 56, 99, 99, 99, 99, 99,
+	47, 66, 99, 99, 99, 99, 99, 99,
+	99, 99, 99, 99, 99, 99, 99, 99,
+	99, 99, 99, 99, 99, 99, 99, 99,
+	99, 99, 99, 99, 99, 99, 99, 99,
+	99, 99, 99, 99, 99, 99, 99, 99,
+}
+
+// jpegRobidoux mozjpeg 默认使用的量化表，亮度和色度相同
+var jpegRobidoux = [64]uint16{
+	16, 16, 16, 18, 25, 37, 56, 85,
+	16, 17, 20, 27, 34, 40, 53, 75,
+	16, 20, 24, 31, 43, 62, 91, 135,
+	18, 27, 31, 40, 53, 74, 106, 156,
+	25, 34, 43, 53, 69, 94, 131, 189,
+	37, 40, 62, 74, 94, 124, 169, 238,
+	56, 53, 91, 106, 131, 169, 226, 311,
+	85, 75, 135, 156, 189, 238, 311, 418,
+}
+
+// jpegOptions JPEG 编码选项
+type jpegOptions struct {
+	progressive bool
+	subsampling string
+	luma        [64]uint16 // 质量 50 时的量化表（自然顺序）
+	chroma      [64]uint16
+}
+
+// defaultJPEGOptions 默认的 JPEG 编码选项：顺序模式、4:2:0 抽样和标准量化表
+var defaultJPEGOptions = jpegOptions{subsampling: Subsampling420, luma: jpegStdLuminance, chroma: jpegStdChrominance}
+
+// parseJPEGOptions 由压缩选项生成 JPEG 编码选项，选项无效时返回 ErrInvalidOptions
+func parseJPEGOptions(options CompressOptions) (jpegOptions, error) {
+	o := defaultJPEGOptions
+	o.progressive = options.JPEGProgressive
+
+	switch options.JPEGSubsampling {
+	case "", Subsampling420, "4:2:0":
+		o.subsampling = Subsampling420
+	case Subsampling422, "4:2:2":
+		o.subsampling = Subsampling422
+	case Subsampling444, "4:4:4":
+		o.subsampling = Subsampling444
+	default:
+		return o, newError(ErrInvalidOptions, nil, "err.invalid_subsampling", options.JPEGSubsampling)
+	}
+
+	switch table := strings.TrimSpace(options.JPEGQuantTable); table {
+	case "", QuantTableStandard:
+	case QuantTableMozjpeg:
+		o.luma, o.chroma = jpegRobidoux, jpegRobidoux
+	default:
+		// 自定义表：64 个数（亮度和色度共用）或 128 个数（先亮度后色度），自然顺序
+		fields := strings.FieldsFunc(table, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' })
+		if len(fields) != 64 && len(fields) != 128 {
+			return o, newError(ErrInvalidOptions, nil, "err.invalid_quant_table", len(fields))
+		}
+		var values [128]uint16
+		for i, f := range fields {
+			v, err := strconv.ParseUint(f, 10, 16)
+			if err != nil || v < 1 || v > 255 {
+				return o, newError(ErrInvalidOptions, nil, "err.invalid_quant_value", f)
+			}
+			values[i] = uint16(v)
+		}
+		copy(o.luma[:], values[:64])
+		o.chroma = o.luma
+		if len(fields) == 128 {
+			copy(o.chroma[:], values[64:])
+		}
+	}
+	return o, nil
+}
+
+// scaleQuantTable 按质量缩放量化表（与 libjpeg 的 jpeg_quality_scaling 相同），结果限制在 1-255
+func scaleQuantTable(base [64]uint16, quality int) [64]uint16 {
+	quality = min(max(quality, 1), 100)
+	scale := 200 - quality*2
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	var out [64]uint16
+	for i, b := range base {
+		v := (int(b)*scale + 50) / 100
+		out[i] = uint16(min(max(v, 1), 255))
+	}
+	return out
+}
+
+// encodeJPEG 纯 Go 的 JPEG 编码器：支持渐进模式、色度抽样方式和自定义量化表，
+// 每次扫描都使用按实际符号频率生成的最优 Huffman 表
+func encodeJPEG(ctx context.Context, w io.Writer, img image.Image, quality int, o jpegOptions) error {
+	coefs, err := jpegForwardDCT(ctx, img, quality, o)
+	if err != nil {
+		return err
+	}
+	return writeJPEGCoefficients(w, coefs, o.progressive, nil)
+}
+
+// jpegDCTMatrix 8 点 DCT 的系数矩阵 c[u][x] = C(u)/2 * cos((2x+1)uπ/16)
+var jpegDCTMatrix = func() (m [8][8]float32) {
+	for u := 0; u < 8; u++ {
+		cu := 0.5
+		if u == 0 {
+			cu = 0.5 / math.Sqrt2
+		}
+		for x := 0; x < 8; x++ {
+			m[u][x] = float32(cu * math.Cos(float64(2*x+1)*float64(u)*math.Pi/16))
+		}
+	}
+	return m
+}()
+
+// isGrayImage 判断图片是否为灰度图（只编码一个分量）
+func isGrayImage(img image.Image) bool {
+	switch img.(type) {
+	case *image.Gray, *image.Gray16:
+		return true
+	}
+	return false
+}
+
+// jpegForwardDCT 将图片转换为 YCbCr（灰度图只有 Y），按抽样方式下采样后做 DCT 并量化
+// 按 MCU 行处理，只保留量化后的系数
+func jpegForwardDCT(ctx context.Context, img image.Image, quality int, o jpegOptions) (*jpegCoefficients, error) {
+	b := img.Bounds()
+	c := &jpegCoefficients{width: b.Dx(), height: b.Dy(), hmax: 1, vmax: 1}
+	c.quant[0] = scaleQuantTable(o.luma, quality)
+	c.quant[1] = scaleQuantTable(o.chroma, quality)
+
+	gray := isGrayImage(img)
+	if gray {
+		c.comps = []jpegComponent{{id: 1, h: 1, v: 1}}
+	} else {
+		switch o.subsampling {
+		case Subsampling422:
+			c.hmax = 2
+		case Subsampling444:
+		default:
+			c.hmax, c.vmax = 2, 2
+		}
+		c.comps = []jpegComponent{{id: 1, h: c.hmax, v: c.vmax}, {id: 2, h: 1, v: 1, tq: 1}, {id: 3, h: 1, v: 1, tq: 1}}
+	}
+	mx, my := c.mcus()
+	for i := range c.comps {
+		comp := &c.comps[i]
+		comp.bw, comp.bh = mx*comp.h, my*comp.v
+		comp.coefs = make([]int16, comp.bw*comp.bh*64)
+	}
+
+	// 一个 MCU 行的全分辨率 Y/Cb/Cr，超出图片的部分复制边缘像素
+	rowW, rowH := mx*8*c.hmax, 8*c.vmax
+	planes := make([][]float32, len(c.comps))
+	for i := range planes {
+		planes[i] = make([]float32, rowW*rowH)
+	}
+	var block [64]float32
+	for my0 := 0; my0 < my; my0++ {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
+		for y := 0; y < rowH; y++ {
+			sy := b.Min.Y + min(my0*rowH+y, c.height-1)
+			for x := 0; x < rowW; x++ {
+				sx := b.Min.X + min(x, c.width-1)
+				i := y*rowW + x
+				if gray {
+					planes[0][i] = float32(color.GrayModel.Convert(img.At(sx, sy)).(color.Gray).Y)
+					continue
+				}
+				planes[0][i], planes[1][i], planes[2][i] = pixelYCbCr(img, sx, sy)
+			}
+		}
+
+		for ci := range c.comps {
+			comp := &c.comps[ci]
+			// 分量相对于全分辨率的缩小倍数
+			sx, sy := c.hmax/comp.h, c.vmax/comp.v
+			for bv := 0; bv < comp.v; bv++ {
+				for bx := 0; bx < comp.bw; bx++ {
+					for y := 0; y < 8; y++ {
+						for x := 0; x < 8; x++ {
+							px, py := (bx*8+x)*sx, (bv*8+y)*sy
+							var sum float32
+							for dy := 0; dy < sy; dy++ {
+								for dx := 0; dx < sx; dx++ {
+									sum += planes[ci][(py+dy)*rowW+px+dx]
+								}
+							}
+							block[y*8+x] = sum/float32(sx*sy) - 128
+						}
+					}
+					fdctQuantize(&block, &c.quant[comp.tq], comp.block(bx, my0*comp.v+bv))
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// pixelYCbCr 返回像素的 Y、Cb、Cr（JFIF 公式，0-255）
+func pixelYCbCr(img image.Image, x, y int) (float32, float32, float32) {
+	if ycc, ok := img.(*image.YCbCr); ok {
+		yi, ci := ycc.YOffset(x, y), ycc.COffset(x, y)
+		return float32(ycc.Y[yi]), float32(ycc.Cb[ci]), float32(ycc.Cr[ci])
+	}
+	var r, g, bl float32
+	if rgba, ok := img.(*image.RGBA); ok {
+		i := rgba.PixOffset(x, y)
+		r, g, bl = float32(rgba.Pix[i]), float32(rgba.Pix[i+1]), float32(rgba.Pix[i+2])
+	} else {
+		r16, g16, b16, _ := img.At(x, y).RGBA()
+		r, g, bl = float32(r16)/257, float32(g16)/257, float32(b16)/257
+	}
+	return 0.299*r + 0.587*g + 0.114*bl,
+		-0.168736*r - 0.331264*g + 0.5*bl + 128,
+		0.5*r - 0.418688*g - 0.081312*bl + 128
+}
+
+// fdctQuantize 对 8x8 块做二维 DCT 并按量化表量化（四舍五入）
+func fdctQuantize(block *[64]float32, q *[64]uint16, out []int16) {
+	var tmp [64]float32
+	m := &jpegDCTMatrix
+	// 行变换
+	for y := 0; y < 8; y++ {
+		row := block[y*8 : y*8+8]
+		for u := 0; u < 8; u++ {
+			var s float32
+			for x := 0; x < 8; x++ {
+				s += m[u][x] * row[x]
+			}
+			tmp[y*8+u] = s
+		}
+	}
+	// 列变换
+	for u := 0; u < 8; u++ {
+		for v := 0; v < 8; v++ {
+			var s float32
+			for y := 0; y < 8; y++ {
+				s += m[v][y] * tmp[y*8+u]
+			}
+			i := v*8 + u
+			out[i] = int16(math.Round(float64(s) / float64(q[i])))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+)
+
+// optimizeJPEGLossless 不经过像素的 JPEG 无损优化（与 jpegtran -optimize -progressive -copy none 相当）：
+// 解码出量化后的 DCT 系数，用最优 Huffman 表重新熵编码并转为渐进式，去掉不影响显示的标记段，
+// 解码后的像素与原图完全相同。小图的渐进式可能反而更大，此时使用优化后的顺序模式
+func optimizeJPEGLossless(ctx context.Context, data []byte) ([]byte, error) {
+	c, markers, err := decodeJPEGCoefficients(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	keep := essentialJPEGMarkers(markers)
+	if err := checkCanceled(ctx); err != nil {
+		return nil, err
+	}
+
+	var progressive, baseline bytes.Buffer
+	if err := writeJPEGCoefficients(&progressive, c, true, keep); err != nil {
+		return nil, err
+	}
+	if err := writeJPEGCoefficients(&baseline, c, false, keep); err != nil {
+		return nil, err
+	}
+	if baseline.Len() < progressive.Len() {
+		return baseline.Bytes(), nil
+	}
+	return progressive.Bytes(), nil
+}
+
+// essentialJPEGMarkers 从原文件的 APPn/COM 段中只保留影响显示的部分：
+// ICC 颜色配置（APP2）、Adobe 颜色变换（APP14）和 EXIF 中的方向标签，其余（JFIF、XMP、注释等）全部去掉
+func essentialJPEGMarkers(markers [][]byte) [][]byte {
+	var keep [][]byte
+	for _, m := range markers {
+		payload := m[4:]
+		switch m[1] {
+		case 0xE2:
+			if bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00")) {
+				keep = append(keep, m)
+			}
+		case 0xEE:
+			if bytes.HasPrefix(payload, []byte("Adobe")) {
+				keep = append(keep, m)
+			}
+		case 0xE1:
+			if !bytes.HasPrefix(payload, exifHeader) {
+				continue
+			}
+			// EXIF 无法解析时去掉，与其余元数据相同
+			exif, err := filterExif(payload, []string{preserveOrientation})
+			if err != nil || exif == nil {
+				continue
+			}
+			segment := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(len(exif)+2))
+			keep = append(keep, append(segment, exif...))
+		}
+	}
+	return keep
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testJPEGInputs 标准库编码的顺序模式文件，以及本项目编码器生成的渐进式和各种抽样方式的文件
+func testJPEGInputs(t *testing.T) map[string][]byte {
+	t.Helper()
+	inputs := make(map[string][]byte)
+	for _, size := range testSizes {
+		for _, img := range []image.Image{testPhoto(size[0], size[1]), testGray(size[0], size[1])} {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+				t.Fatal(err)
+			}
+			inputs[fmt.Sprintf("stdlib/%T/%dx%d", img, size[0], size[1])] = buf.Bytes()
+
+			for name, o := range testJPEGOptions() {
+				var buf bytes.Buffer
+				if err := encodeJPEG(context.Background(), &buf, img, 75, o); err != nil {
+					t.Fatal(err)
+				}
+				inputs[fmt.Sprintf("%s/%T/%dx%d", name, img, size[0], size[1])] = buf.Bytes()
+			}
+		}
+	}
+	return inputs
+}
+
+// samePixels 两张图片的每个像素完全相同
+func samePixels(t *testing.T, want, got image.Image) {
+	t.Helper()
+	if want.Bounds() != got.Bounds() {
+		t.Fatalf("bounds: want %v, got %v", want.Bounds(), got.Bounds())
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			wr, wg, wb, wa := want.At(x, y).RGBA()
+			gr, gg, gb, ga := got.At(x, y).RGBA()
+			if wr != gr || wg != gg || wb != gb || wa != ga {
+				t.Fatalf("pixel (%d,%d): want %v, got %v", x, y, want.At(x, y), got.At(x, y))
+			}
+		}
+	}
+}
+
+// 无损优化后标准库解码出的像素与原文件完全相同
+func TestOptimizeJPEGLosslessPixels(t *testing.T) {
+	for name, data := range testJPEGInputs(t) {
+		t.Run(name, func(t *testing.T) {
+			out, err := optimizeJPEGLossless(context.Background(), data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := jpeg.Decode(bytes.NewReader(out))
+			if err != nil {
+				t.Fatalf("image/jpeg: %v", err)
+			}
+			samePixels(t, want, got)
+		})
+	}
+}
+
+// testExif 只有 IFD0 的 EXIF：方向和软件名称两个标签
+func testExif() []byte {
+	software := "squash-test-software\x00"
+	tiff := []byte("II*\x00")
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	// 方向 = 6（SHORT，值在条目内）
+	tiff = binary.LittleEndian.AppendUint16(tiff, exifTagOrientation)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 6)
+	// 软件名称（ASCII，值在 IFD 之后）
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0131)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(software)))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8+2+2*12+4)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 0)
+	tiff = append(tiff, software...)
+	return append(append([]byte{}, exifHeader...), tiff...)
+}
+
+// 去掉注释和 EXIF 中除方向以外的标签，保留 ICC 颜色配置
+func TestOptimizeJPEGLosslessMarkers(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testPhoto(33, 17), nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := insertJPEGExif(buf.Bytes(), testExif())
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment := []byte("squash-test-comment")
+	icc := append([]byte("ICC_PROFILE\x00\x01\x01"), "squash-test-icc"...)
+	var extra []byte
+	for _, seg := range []struct {
+		marker  byte
+		payload []byte
+	}{{0xFE, comment}, {0xE2, icc}} {
+		extra = append(extra, 0xFF, seg.marker)
+		extra = binary.BigEndian.AppendUint16(extra, uint16(len(seg.payload)+2))
+		extra = append(extra, seg.payload...)
+	}
+	data = append(append(append([]byte{}, data[:2]...), extra...), data[2:]...)
+
+	out, err := optimizeJPEGLossless(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, comment) || bytes.Contains(out, []byte("squash-test-software")) {
+		t.Error("comment or EXIF software tag was kept")
+	}
+	if !bytes.Contains(out, []byte("squash-test-icc")) {
+		t.Error("ICC profile was dropped")
+	}
+	exif := jpegExif(out)
+	if exif == nil {
+		t.Fatal("EXIF orientation was dropped")
+	}
+	if _, err := filterExif(exif, []string{preserveOrientation}); err != nil {
+		t.Fatalf("rewritten EXIF does not parse: %v", err)
+	}
+}
+
+// 截断或损坏的文件返回错误而不是崩溃
+func TestOptimizeJPEGLosslessTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	o := defaultJPEGOptions
+	o.progressive = true
+	if err := encodeJPEG(context.Background(), &buf, testPhoto(33, 17), 80, o); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	for n := 0; n < len(data)-2; n += 7 {
+		if _, err := optimizeJPEGLossless(context.Background(), data[:n]); err == nil {
+			t.Fatalf("no error for %d of %d bytes", n, len(data))
+		}
+	}
+}
+
+// testZeroQuantJPEG 把第一个量化表的最后一项（最高频率）改为 0：image/jpeg 可以解码，无损重写不支持
+func testZeroQuantJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testPhoto(64, 48), &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	dqt := bytes.Index(data, []byte{0xFF, 0xDB})
+	if dqt < 0 || data[dqt+4]>>4 != 0 {
+		t.Fatal("no 8-bit DQT segment")
+	}
+	data[dqt+5+63] = 0
+	if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("image/jpeg: %v", err)
+	}
+	if _, err := optimizeJPEGLossless(context.Background(), data); err == nil {
+		t.Fatal("lossless optimization accepted a zero quantization value")
+	}
+	return data
+}
+
+// 无损优化不支持的文件保留原文件，不退回有损的重新编码
+func TestCompressJPEGLosslessFallback(t *testing.T) {
+	testDataDirs(t)
+	dir := t.TempDir()
+	data := testZeroQuantJPEG(t)
+	input := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(input, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, lossless := range []bool{true, false} {
+		options := CompressOptions{Quality: 80, OutputFormat: "original", KeepAspect: true, JPEGLossless: lossless, Force: true,
+			OutputDir: filepath.Join(dir, fmt.Sprint(lossless))}
+		r := NewApp().CompressImage(input, options)
+		if !r.Success {
+			t.Fatalf("lossless=%v: %s", lossless, r.Message)
+		}
+		out, err := os.ReadFile(r.OutputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 有损重新编码（质量 80）一定比质量 100 的原文件小，用来确认测试能区分两种结果
+		if kept := bytes.Equal(out, data); kept != lossless {
+			t.Errorf("lossless=%v: output is original = %v (%d of %d bytes)", lossless, kept, len(out), len(data))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// systemLocale 返回系统语言（如 zh_CN.UTF-8、en_US），按 POSIX 约定依次检查环境变量
+// macOS 从图形界面启动时通常没有这些环境变量，改为读取系统偏好设置
+func systemLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG", "LANGUAGE"} {
+		if v := os.Getenv(key); v != "" && v != "C" && v != "POSIX" {
+			return strings.SplitN(v, ":", 2)[0]
+		}
+	}
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+		if err == nil {
+			return strings.TrimSpace(string(out))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// appVersion 应用版本，写入日志和诊断包
+const appVersion = "1.0.0"
+
+// 日志文件轮转参数
+const (
+	logFileName   = "squash.log"
+	logMaxSize    = 5 << 20 // 单个日志文件最大 5 MB
+	logMaxBackups = 3       // 保留 squash.log.1 ~ squash.log.3
+)
+
+// 日志级别
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+var (
+	// logLevel 当前日志级别，可在运行时修改
+	logLevel = new(slog.LevelVar)
+	// logger 全局日志，initLogging 之前丢弃所有日志
+	logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+)
+
+// logDir 返回日志目录
+func logDir() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs"), nil
+}
+
+// initLogging 初始化日志：以 JSON 行写入数据目录下的轮转日志文件
+// extra 不为 nil 时同时输出到 extra（命令行 -v 时为标准错误）
+func initLogging(extra io.Writer) {
+	level, err := parseLogLevel(loadSettings().LogLevel)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	logLevel.Set(level)
+
+	var writers []io.Writer
+	if dir, err := logDir(); err == nil {
+		if f, err := newRotatingFile(filepath.Join(dir, logFileName), logMaxSize, logMaxBackups); err == nil {
+			writers = append(writers, f)
+		}
+	}
+	if extra != nil {
+		writers = append(writers, extra)
+	}
+	if len(writers) == 0 {
+		return
+	}
+	logger = slog.New(slog.NewJSONHandler(io.MultiWriter(writers...), &slog.HandlerOptions{Level: logLevel}))
+	logger.Info("start", "version", appVersion, "os", runtime.GOOS, "arch", runtime.GOARCH, "locale", currentLocale())
+}
+
+// parseLogLevel 解析日志级别名称，空字符串为 info
+func parseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case LogLevelDebug:
+		return slog.LevelDebug, nil
+	case "", LogLevelInfo:
+		return slog.LevelInfo, nil
+	case LogLevelWarn:
+		return slog.LevelWarn, nil
+	case LogLevelError:
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, newError(ErrInvalidOptions, nil, "log.unknown_level", name)
+}
+
+// logLevelName 返回日志级别名称
+func logLevelName(level slog.Level) string {
+	switch {
+	case level <= slog.LevelDebug:
+		return LogLevelDebug
+	case level <= slog.LevelInfo:
+		return LogLevelInfo
+	case level <= slog.LevelWarn:
+		return LogLevelWarn
+	}
+	return LogLevelError
+}
+
+// since 返回自 start 起经过的毫秒数日志字段
+func since(start time.Time) slog.Attr {
+	return slog.Int64("duration_ms", time.Since(start).Milliseconds())
+}
+
+// GetLogLevel 返回当前日志级别
+func (a *App) GetLogLevel() string {
+	return logLevelName(logLevel.Level())
+}
+
+// SetLogLevel 设置日志级别（debug、info、warn、error）并保存
+func (a *App) SetLogLevel(name string) ActionResult {
+	level, err := parseLogLevel(name)
+	if err != nil {
+		return ActionResult{Success: false, Message: err.Error()}
+	}
+	logLevel.Set(level)
+	if err := updateSettings(func(s *appSettings) { s.LogLevel = logLevelName(level) }); err != nil {
+		return ActionResult{Success: false, Message: tr("settings.save_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("log.level_changed", logLevelName(level))}
+}
+
+// rotatingFile 按大小轮转的日志文件，超过 maxSize 时依次重命名为 .1、.2 ...
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// newRotatingFile 打开（追加写入）日志文件
+func newRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = stat.Size()
+	return nil
+}
+
+// Write 写入一条日志，写入前超过大小限制则先轮转
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate 关闭当前文件，squash.log.N-1 → squash.log.N，squash.log → squash.log.1，再重新打开
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	for i := r.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
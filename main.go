@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -13,10 +14,16 @@ import (
 var assets embed.FS
 
 func main() {
+	// 带子命令时以命令行模式运行，否则启动图形界面
+	if len(os.Args) > 1 && isCLICommand(os.Args[1]) {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
+	initLogging(nil)
 	app := NewApp()
 
 	err := wails.Run(&options.App{
-		Title:     "Squash - 图片压缩工具",
+		Title:     tr("app.title"),
 		Width:     1000,
 		Height:    700,
 		MinWidth:  800,
@@ -26,6 +33,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 30, G: 30, B: 46, A: 1},
 		OnStartup:        app.startup,
+		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
 		},
@@ -43,8 +51,8 @@ func main() {
 			},
 			Appearance: mac.NSAppearanceNameDarkAqua,
 			About: &mac.AboutInfo{
-				Title:   "Squash - 图片压缩工具",
-				Message: "一款简单高效的图片压缩工具\n支持 PNG, JPEG, WebP, GIF, TIFF 等格式",
+				Title:   tr("app.title"),
+				Message: tr("app.about"),
 			},
 		},
 	})
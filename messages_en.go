@@ -0,0 +1,276 @@
+package main
+
+// messagesEn 英文消息
+var messagesEn = map[string]string{
+	// app
+	"dialog.select_images":     "Select Images",
+	"filter.images":            "Image Files",
+	"dialog.select_output_dir": "Select Output Folder",
+	"dialog.select_folder":     "Select Folder",
+	"dialog.import_presets":    "Import Presets",
+	"filter.presets":           "Preset Files",
+	"dialog.export_presets":    "Export Presets",
+	"dialog.export_history":    "Export History",
+	"filter.csv":               "CSV Files",
+	"dialog.export_report":     "Export Report",
+	"filter.json_report":       "JSON Report",
+	"filter.csv_report":        "CSV Report",
+	"app.title":                "Squash - Image Compressor",
+	"app.about":                "A simple, efficient image compressor\nSupports PNG, JPEG, WebP, GIF, TIFF and more",
+	"locale.unsupported":       "Unsupported language: %s",
+	"locale.changed":           "Language changed",
+	"settings.save_failed":     "Failed to save settings: %v",
+
+	// cache
+	"cache.clear_failed": "Failed to clear cache: %v",
+	"cache.cleared":      "Cache cleared",
+	"cache.write_failed": "Failed to write cache: %v",
+
+	// cli
+	"cli.usage": `Usage:
+  squash compress [options] <file or folder>...   Compress images; folders are processed recursively, keeping their structure
+  squash watch [options] <folder>                 Watch a folder and compress new or modified images automatically
+  squash serve [options]                          Start the local HTTP API server
+
+Run "squash <command> -h" for the options of each command. Run without a command to start the GUI.`,
+	"cli.watching":           "Watching %s → %s (Ctrl+C to quit)",
+	"cli.watch_stopped":      "Stopped: %d files processed, %d failed",
+	"flag.preset":            "Use a named preset as defaults (other flags override it)",
+	"flag.quality":           "Compression quality 1-100",
+	"flag.accept":            "Formats accepted with -format best (repeatable), e.g. -accept webp -accept jpeg; default all",
+	"flag.background":        "Background for transparent areas when the output format has no alpha (e.g. JPEG): #rrggbb, #rgb or auto (edge color); default white",
+	"flag.progressive":       "Write progressive JPEG",
+	"flag.subsampling":       "JPEG chroma subsampling: 420 (default), 422, 444",
+	"flag.quant_table":       "JPEG quantization table: standard (default), mozjpeg, or 64/128 comma-separated values (natural order)",
+	"flag.png_effort":        "Lossless PNG optimization level: 0 stdlib encoder (default), 1 fast, 2 all color types and filter strategies, 3 plus zopfli-like deflate (slow); quality 100 with a non-zero level skips quantization",
+	"flag.jpeg_lossless":     "Lossless JPEG optimization: rewrite the entropy coding as progressive without re-encoding (pixels unchanged, quality ignored)",
+	"flag.format":            "Output format: original, auto (chosen from image content), best (smallest of the accepted formats), jpeg, png, webp, avif",
+	"flag.max_width":         "Maximum width, 0 for no limit",
+	"flag.max_height":        "Maximum height, 0 for no limit",
+	"flag.out":               "Output folder; defaults to the source file's folder",
+	"flag.name":              "Output file name template, e.g. {name}.min.{ext}",
+	"flag.collision":         "Collision policy: overwrite, skip, increment, fail",
+	"flag.in_place":          "Optimize in place; replace the source only when the result is smaller",
+	"flag.backup":            "Backup mode for in-place optimization: none, orig, trash",
+	"flag.no_project_config": "Ignore rules in .squashrc / squash.toml",
+	"flag.force":             "Bypass the cache and recompress files produced by Squash",
+	"flag.include_dir":       "Glob pattern to include in folder mode, repeatable",
+	"flag.exclude_dir":       "Glob pattern to exclude in folder mode, repeatable",
+	"flag.report":            "Write a batch report, JSON (.json) or CSV (.csv) by extension, repeatable",
+	"flag.include":           "Glob pattern to include, repeatable",
+	"flag.exclude":           "Glob pattern to exclude, repeatable",
+	"flag.recursive":         "Also watch subfolders",
+	"flag.debounce":          "How long a file must stop changing before it is processed (ms)",
+	"flag.mode":              "copy: keep source files; move: delete sources after success",
+
+	// errors / compress
+	"err.open_file":                  "Cannot open file",
+	"err.image_too_large":            "Image is too large: %dx%d",
+	"err.resize_too_large":           "Resized image is too large: %dx%d (at most %d pixels)",
+	"err.unsupported_image":          "Unsupported image format",
+	"err.decode_image":               "Cannot decode image",
+	"err.save":                       "Save failed",
+	"err.create_output_dir":          "Cannot create output folder",
+	"err.create_output_dir_detail":   "Cannot create output folder: %v",
+	"err.unsupported_output":         "Unsupported output format: %s",
+	"err.compress":                   "Compression failed",
+	"err.in_place_convert":           "In-place optimization cannot convert formats; choose the original format",
+	"err.open_folder_detail":         "Cannot open folder: %v",
+	"err.not_folder":                 "Not a folder: %s",
+	"err.backup":                     "Backup failed",
+	"err.unknown_backup":             "Unknown backup mode: %s",
+	"err.read_file":                  "Cannot read file",
+	"err.read_file_named":            "Cannot read file %s",
+	"err.output_exists":              "Output file already exists: %s",
+	"err.overwrite_input":            "Output path is the same as the source file; refusing to overwrite it (enable inPlace for in-place optimization): %s",
+	"err.unknown_collision":          "Unknown collision policy: %s",
+	"err.no_free_name":               "Cannot find a free file name for %s",
+	"history.write_failed":           "Failed to write history: %v",
+	"compress.excluded_by_project":   "Excluded by project config",
+	"compress.already_squashed":      "Already compressed by Squash, skipped",
+	"compress.target_exists_skipped": "Target file already exists, skipped",
+	"compress.success":               "Compressed successfully",
+	"compress.kept_original":         "Kept the original file (compressed result was larger)",
+	"compress.warn_kept_original":    "The compressed file was larger; kept the original",
+	"compress.cached_suffix":         " (from cache)",
+	"preview.failed":                 "Failed to generate preview",
+	"preview.failed_detail":          "Failed to generate preview: %v",
+
+	// directory
+	"directory.walk_failed":   "Failed to scan folder: %v",
+	"directory.summary":       "%d files: %d succeeded, %d skipped, %d failed; %s → %s",
+	"directory.report_failed": "; failed to write report: %v",
+	"report.write_failed":     "Failed to write report: %v",
+
+	// gif
+	"gif.need_two_frames":   "At least 2 images are needed to create a GIF",
+	"gif.encode_failed":     "GIF encoding failed",
+	"gif.created":           "GIF created successfully",
+	"gif.progress.decoding": "Decoding GIF...",
+	"gif.too_large":         "GIF is too large: %dx%d",
+	"gif.decode_failed":     "Cannot decode GIF",
+	"gif.no_frames":         "GIF has no frames",
+	"gif.progress.palette":  "Building palette...",
+	"gif.progress.frame":    "Processing frame %d/%d...",
+	"gif.progress.encoding": "Encoding GIF...",
+	"gif.progress.done":     "Done!",
+	"gif.compressed":        "Done! Original: %s → compressed: %s (saved %.1f%%)",
+
+	// history
+	"history.read_failed":      "Failed to read history: %v",
+	"history.not_found":        "History entry not found: %s",
+	"history.already_reverted": "This entry has already been reverted",
+	"history.failed_entry":     "Failed operations have nothing to revert",
+	"history.output_changed":   "The output file was modified or deleted; cannot revert",
+	"history.no_backup":        "No original was kept for this in-place optimization; cannot revert",
+	"restore.failed":           "Restore failed: %v",
+	"history.remove_failed":    "Failed to delete output file: %v",
+	"history.reverted":         "Reverted",
+	"export.failed":            "Export failed: %v",
+	"history.exported":         "Exported %d entries, %s saved in total",
+
+	// presets
+	"preset.no_compress_options": "Preset %s has no image compression options",
+	"preset.file_invalid":        "Invalid preset file %s",
+	"preset.not_found":           "Preset not found: %s",
+	"preset.name_empty":          "Preset name cannot be empty",
+	"preset.empty":               "Preset contains no options",
+	"preset.read_failed":         "Failed to read presets: %v",
+	"preset.save_failed":         "Failed to save presets: %v",
+	"preset.saved":               "Saved preset %s",
+	"preset.deleted":             "Deleted preset %s",
+	"preset.applied":             "Applied preset %s",
+	"preset.read_file_failed":    "Failed to read preset file: %v",
+	"preset.imported":            "Imported %d presets",
+	"preset.exported":            "Exported %d presets to %s",
+
+	// project config
+	"config.not_json_or_toml": "Neither valid JSON (%v) nor valid TOML (%v)",
+	"config.invalid":          "Invalid project config %s",
+
+	// report
+	"report.unsupported_format": "Unsupported report format: %s (use .json or .csv)",
+	"report.export_failed":      "Failed to export report: %v",
+	"report.exported":           "Exported a report for %d files to %s",
+
+	// responsive
+	"responsive.no_formats":      "No usable output formats: %s",
+	"responsive.encode_failed":   "Encoding %s at %dw failed",
+	"responsive.generated":       "Generated %d variants (%d widths × %d formats), %s in total",
+	"responsive.skipped_formats": "; skipped unsupported formats: %s",
+
+	// trash
+	"trash.not_found": "No such trash entry: %s",
+	"trash.restored":  "Restored %s",
+
+	// watch
+	"watch.no_in_place":          "Watch mode does not support in-place optimization",
+	"watch.need_output_dir":      "Watch mode needs an output folder different from the watched folder",
+	"watch.error":                "Watch error",
+	"watch.start_failed":         "Failed to start folder watching",
+	"watch.add_failed":           "Failed to watch folder %s",
+	"watch.remove_source_failed": " (failed to delete source file: %v)",
+	"watch.started":              "Watching started",
+	"watch.not_found":            "Watch not found: %s",
+	"watch.stopped":              "Watching stopped",
+	"webp.windows_unsupported":   "WebP encoding is not supported on Windows yet; choose another format",
+
+	// logging
+	"log.unknown_level":         "Unknown log level: %s",
+	"log.level_changed":         "Log level set to %s",
+	"dialog.export_diagnostics": "Export Diagnostics",
+	"filter.zip":                "ZIP Archive",
+	"dialog.canceled":           "Canceled",
+	"diag.export_failed":        "Failed to export diagnostics: %v",
+	"diag.exported":             "Diagnostics exported: %s",
+	"flag.verbose":              "Write verbose (debug) logs to stderr",
+
+	// jobs
+	"job.canceled":            "Canceled",
+	"job.not_found":           "No running job: %s",
+	"job.cancel_requested":    "Cancellation requested",
+	"directory.canceled":      "; %d canceled",
+	"job.queued":              "Queued",
+	"job.running":             "Running",
+	"job.resumed":             "Unfinished when the app exited; queued again",
+	"job.unknown_kind":        "Unknown job kind: %s",
+	"job.invalid_request":     "Invalid job request: %s job is missing paths or options",
+	"job.compress_summary":    "%d files: %d succeeded, %d failed",
+	"job.invalid_concurrency": "Concurrency must be between 1 and %d",
+	"job.concurrency_changed": "Concurrency set to %d",
+	"gif.progress.reading":    "Reading frame %d/%d",
+	"responsive.progress":     "Generating %dpx variants",
+
+	// server
+	"server.listening":            "HTTP API listening on http://%s (Ctrl+C to quit)",
+	"server.stopped":              "HTTP API stopped",
+	"server.failed":               "HTTP API failed to start: %v",
+	"server.no_file":              "No image in request",
+	"server.need_multipart":       "Upload sequence frames as multipart/form-data",
+	"server.read_upload":          "Failed to read uploaded file: %s",
+	"server.read_body":            "Failed to read request",
+	"server.body_too_large":       "Request exceeds the size limit of %s",
+	"server.invalid_options_json": "The options parameter is not valid JSON",
+	"server.invalid_option":       "Invalid value for %s: %s",
+	"server.invalid_quality":      "Quality must be between 1 and 100, got %d",
+	"flag.addr":                   "Listen address",
+	"flag.max_body":               "Request body size limit (MB)",
+	"flag.concurrency":            "Number of requests processed at the same time",
+
+	// tinify
+	"tinify.keys_read_failed":  "Failed to read API key config: %s",
+	"tinify.keys_invalid":      "Invalid API key config: %s",
+	"tinify.keys_loaded":       "TinyPNG-compatible API enabled: %d API keys (%s)",
+	"tinify.no_keys":           "No API keys configured; add them to %s",
+	"tinify.unauthorized":      "Credentials are invalid",
+	"tinify.bad_source":        "source.url must be an http or https URL",
+	"tinify.fetch_failed":      "Failed to download %s",
+	"tinify.source_blocked":    "%s resolves to a loopback or private address and cannot be fetched",
+	"tinify.output_not_found":  "Output not found or expired",
+	"tinify.store_unsupported": "store (upload to cloud storage) is not supported",
+	"tinify.bad_convert":       "convert.type must be a MIME type or an array of them",
+	"tinify.scale_dimension":   "The scale method takes either width or height, not both",
+	"tinify.need_dimensions":   "The %s method requires both width and height",
+	"tinify.bad_resize_method": "Unknown resize method: %s",
+	"tinify.bad_preserve":      "Unknown metadata to preserve: %s",
+	"flag.keys":                "API key config for the TinyPNG-compatible API; defaults to api-keys.json in the data directory",
+
+	// grpc
+	"server.grpc_listening": "gRPC listening on %s",
+	"flag.grpc_addr":        "Listen address of the gRPC API; disabled when empty",
+
+	// proxy
+	"proxy.root_failed": "Failed to open image root: %s",
+	"proxy.enabled":     "Image URL processing enabled for %s, e.g. http://%s/img/rs:fit:800:600/q:75/f:webp/<path>",
+	"proxy.no_path":     "Image path missing from URL",
+	"proxy.bad_option":  "Invalid arguments for processing option %s: %s",
+	"proxy.not_found":   "Image not found: %s",
+	"flag.proxy_root":   "Image root; enables on-the-fly processing with URLs like /img/rs:fit:800:600/q:75/f:webp/<path>",
+
+	// best format
+	"err.no_accepted_format": "No usable output format (accepted: %s)",
+	"compress.best_format":   "; smallest format: %s",
+
+	// auto format
+	"compress.auto_format":     "; auto selected %s: %s",
+	"auto.png_lossless":        "only %d colors, using lossless palette PNG",
+	"auto.png_quantized":       "%d%% flat areas and %d%% hard edges (graphic or screenshot), using quantized PNG to avoid JPEG artifacts",
+	"auto.png_quantized_alpha": "has transparency and WebP is not available on this platform, using quantized PNG",
+	"auto.webp":                "continuous tone (%d%% of neighboring pixels differ slightly) with transparency, using WebP",
+	"auto.jpeg":                "continuous tone (%d%% of neighboring pixels differ slightly, about %d colors), using JPEG for a photo",
+
+	// background
+	"err.invalid_background":        "Invalid background color: %s (expected #rrggbb, #rgb or auto)",
+	"compress.warn_alpha_flattened": "The output format has no alpha channel; transparent areas were flattened onto %s",
+
+	// jpeg encoder
+	"err.invalid_subsampling": "Invalid chroma subsampling: %s (expected 444, 422 or 420)",
+	"err.invalid_quant_table": "A custom quantization table needs 64 or 128 values, got %d",
+	"err.invalid_quant_value": "Invalid quantization value: %s (expected 1-255)",
+
+	// jpeg lossless
+	"compress.lossless_suffix": " (lossless, pixels unchanged)",
+
+	// png optimizer
+	"err.invalid_png_effort": "Invalid PNG optimization level: %d (expected 0-3)",
+}
@@ -0,0 +1,276 @@
+package main
+
+// messagesZhCN 简体中文消息（默认语言，其他语言缺少的消息也回退到这里）
+var messagesZhCN = map[string]string{
+	// app
+	"dialog.select_images":     "选择图片",
+	"filter.images":            "图片文件",
+	"dialog.select_output_dir": "选择输出目录",
+	"dialog.select_folder":     "选择文件夹",
+	"dialog.import_presets":    "导入预设",
+	"filter.presets":           "预设文件",
+	"dialog.export_presets":    "导出预设",
+	"dialog.export_history":    "导出历史记录",
+	"filter.csv":               "CSV 文件",
+	"dialog.export_report":     "导出报告",
+	"filter.json_report":       "JSON 报告",
+	"filter.csv_report":        "CSV 报告",
+	"app.title":                "Squash - 图片压缩工具",
+	"app.about":                "一款简单高效的图片压缩工具\n支持 PNG, JPEG, WebP, GIF, TIFF 等格式",
+	"locale.unsupported":       "不支持的语言: %s",
+	"locale.changed":           "已切换语言",
+	"settings.save_failed":     "保存设置失败: %v",
+
+	// cache
+	"cache.clear_failed": "清空缓存失败: %v",
+	"cache.cleared":      "已清空缓存",
+	"cache.write_failed": "写入缓存失败: %v",
+
+	// cli
+	"cli.usage": `用法:
+  squash compress [选项] <文件或文件夹>...   压缩图片，文件夹会递归处理并保持目录结构
+  squash watch [选项] <文件夹>              监视文件夹，自动压缩新增或修改的图片
+  squash serve [选项]                       启动本地 HTTP API 服务
+
+运行 "squash <命令> -h" 查看各命令的选项。不带命令运行时启动图形界面。`,
+	"cli.watching":           "正在监视 %s → %s（Ctrl+C 退出）",
+	"cli.watch_stopped":      "已停止：处理 %d 个文件，失败 %d 个",
+	"flag.preset":            "使用命名预设作为默认选项（其余参数可覆盖预设）",
+	"flag.quality":           "压缩质量 1-100",
+	"flag.accept":            "format=best 时可接受的格式（可重复），如 -accept webp -accept jpeg，默认全部",
+	"flag.background":        "转为 JPEG 等不支持透明通道的格式时透明区域的背景色：#rrggbb、#rgb 或 auto（边缘颜色），默认白色",
+	"flag.progressive":       "输出渐进式 JPEG",
+	"flag.subsampling":       "JPEG 色度抽样：420（默认）、422、444",
+	"flag.quant_table":       "JPEG 量化表：standard（默认）、mozjpeg，或 64/128 个逗号分隔的数（自然顺序）",
+	"flag.png_effort":        "PNG 无损优化级别：0 标准库编码器（默认），1 快速，2 尝试所有颜色类型和滤波策略，3 再加类似 zopfli 的 deflate（很慢）；非 0 且质量为 100 时不量化",
+	"flag.jpeg_lossless":     "JPEG 无损优化：不重新编码，只重写熵编码并转为渐进式（像素不变，忽略质量）",
+	"flag.format":            "输出格式：original, auto（按图片内容选择）, best（自动选择最小的格式）, jpeg, png, webp, avif",
+	"flag.max_width":         "最大宽度，0 表示不限制",
+	"flag.max_height":        "最大高度，0 表示不限制",
+	"flag.out":               "输出目录，默认写入源文件所在目录",
+	"flag.name":              "输出文件名模板，如 {name}.min.{ext}",
+	"flag.collision":         "冲突策略：overwrite, skip, increment, fail",
+	"flag.in_place":          "原地优化，仅在结果更小时替换源文件",
+	"flag.backup":            "原地优化时的备份方式：none, orig, trash",
+	"flag.no_project_config": "不使用 .squashrc / squash.toml 中的规则",
+	"flag.force":             "不使用缓存，并重新压缩已由 Squash 生成的文件",
+	"flag.include_dir":       "文件夹模式下包含的 glob 模式，可重复",
+	"flag.exclude_dir":       "文件夹模式下排除的 glob 模式，可重复",
+	"flag.report":            "写入批处理报告，按扩展名输出 JSON（.json）或 CSV（.csv），可重复",
+	"flag.include":           "包含的 glob 模式，可重复",
+	"flag.exclude":           "排除的 glob 模式，可重复",
+	"flag.recursive":         "同时监视子文件夹",
+	"flag.debounce":          "文件停止变化多久后开始处理（毫秒）",
+	"flag.mode":              "copy：保留源文件；move：成功后删除源文件",
+
+	// errors / compress
+	"err.open_file":                  "无法打开文件",
+	"err.image_too_large":            "图片尺寸过大: %dx%d",
+	"err.resize_too_large":           "缩放后的尺寸过大: %dx%d（最多 %d 像素）",
+	"err.unsupported_image":          "不支持的图片格式",
+	"err.decode_image":               "无法解码图片",
+	"err.save":                       "保存失败",
+	"err.create_output_dir":          "无法创建输出目录",
+	"err.create_output_dir_detail":   "无法创建输出目录: %v",
+	"err.unsupported_output":         "不支持的输出格式: %s",
+	"err.compress":                   "压缩失败",
+	"err.in_place_convert":           "原地优化不支持转换格式，请选择原格式输出",
+	"err.open_folder_detail":         "无法打开文件夹: %v",
+	"err.not_folder":                 "不是文件夹: %s",
+	"err.backup":                     "备份失败",
+	"err.unknown_backup":             "未知的备份方式: %s",
+	"err.read_file":                  "无法读取文件",
+	"err.read_file_named":            "无法读取文件 %s",
+	"err.output_exists":              "输出文件已存在: %s",
+	"err.overwrite_input":            "输出路径与源文件相同，拒绝覆盖源文件（如需原地优化请开启 inPlace）: %s",
+	"err.unknown_collision":          "未知的冲突策略: %s",
+	"err.no_free_name":               "无法为 %s 找到可用的文件名",
+	"history.write_failed":           "写入历史失败: %v",
+	"compress.excluded_by_project":   "已被项目配置排除",
+	"compress.already_squashed":      "该文件已由 Squash 压缩过，已跳过",
+	"compress.target_exists_skipped": "目标文件已存在，已跳过",
+	"compress.success":               "压缩成功",
+	"compress.kept_original":         "已保持原文件（压缩后更大）",
+	"compress.warn_kept_original":    "压缩后文件更大，已保持原文件",
+	"compress.cached_suffix":         "（使用缓存）",
+	"preview.failed":                 "生成预览失败",
+	"preview.failed_detail":          "生成预览失败: %v",
+
+	// directory
+	"directory.walk_failed":   "遍历文件夹失败: %v",
+	"directory.summary":       "共 %d 个文件：成功 %d，跳过 %d，失败 %d；%s → %s",
+	"directory.report_failed": "；写入报告失败: %v",
+	"report.write_failed":     "写入报告失败: %v",
+
+	// gif
+	"gif.need_two_frames":   "至少需要 2 张图片来创建 GIF",
+	"gif.encode_failed":     "GIF 编码失败",
+	"gif.created":           "GIF 创建成功",
+	"gif.progress.decoding": "正在解码 GIF...",
+	"gif.too_large":         "GIF 尺寸过大: %dx%d",
+	"gif.decode_failed":     "无法解码 GIF",
+	"gif.no_frames":         "GIF 文件没有帧",
+	"gif.progress.palette":  "正在生成调色板...",
+	"gif.progress.frame":    "正在处理帧 %d/%d...",
+	"gif.progress.encoding": "正在编码 GIF...",
+	"gif.progress.done":     "压缩完成!",
+	"gif.compressed":        "压缩完成！原始: %s → 压缩后: %s (节省 %.1f%%)",
+
+	// history
+	"history.read_failed":      "读取历史失败: %v",
+	"history.not_found":        "历史记录不存在: %s",
+	"history.already_reverted": "该记录已撤销",
+	"history.failed_entry":     "失败的操作无需撤销",
+	"history.output_changed":   "输出文件已被修改或删除，无法撤销",
+	"history.no_backup":        "原地优化时未保留原始文件，无法撤销",
+	"restore.failed":           "恢复失败: %v",
+	"history.remove_failed":    "删除输出文件失败: %v",
+	"history.reverted":         "已撤销",
+	"export.failed":            "导出失败: %v",
+	"history.exported":         "已导出 %d 条记录，共节省 %s",
+
+	// presets
+	"preset.no_compress_options": "预设 %s 不包含图片压缩选项",
+	"preset.file_invalid":        "预设文件格式错误 %s",
+	"preset.not_found":           "预设不存在: %s",
+	"preset.name_empty":          "预设名称不能为空",
+	"preset.empty":               "预设没有包含任何选项",
+	"preset.read_failed":         "读取预设失败: %v",
+	"preset.save_failed":         "保存预设失败: %v",
+	"preset.saved":               "已保存预设 %s",
+	"preset.deleted":             "已删除预设 %s",
+	"preset.applied":             "已应用预设 %s",
+	"preset.read_file_failed":    "读取预设文件失败: %v",
+	"preset.imported":            "已导入 %d 个预设",
+	"preset.exported":            "已导出 %d 个预设到 %s",
+
+	// project config
+	"config.not_json_or_toml": "既不是有效的 JSON（%v）也不是有效的 TOML（%v）",
+	"config.invalid":          "项目配置格式错误 %s",
+
+	// report
+	"report.unsupported_format": "不支持的报告格式: %s（请使用 .json 或 .csv）",
+	"report.export_failed":      "导出报告失败: %v",
+	"report.exported":           "已导出 %d 个文件的报告到 %s",
+
+	// responsive
+	"responsive.no_formats":      "没有可用的输出格式: %s",
+	"responsive.encode_failed":   "%s %dw 编码失败",
+	"responsive.generated":       "已生成 %d 个变体（%d 个宽度 × %d 种格式），共 %s",
+	"responsive.skipped_formats": "，已跳过不支持的格式: %s",
+
+	// trash
+	"trash.not_found": "回收站中没有该记录: %s",
+	"trash.restored":  "已恢复 %s",
+
+	// watch
+	"watch.no_in_place":          "监视模式不支持原地优化",
+	"watch.need_output_dir":      "监视模式需要指定不同于监视文件夹的输出目录",
+	"watch.error":                "监视出错",
+	"watch.start_failed":         "无法启动文件夹监视",
+	"watch.add_failed":           "无法监视文件夹 %s",
+	"watch.remove_source_failed": "（删除源文件失败: %v）",
+	"watch.started":              "已开始监视",
+	"watch.not_found":            "监视任务不存在: %s",
+	"watch.stopped":              "已停止监视",
+	"webp.windows_unsupported":   "WebP 编码在 Windows 版本暂不支持，请选择其他格式",
+
+	// logging
+	"log.unknown_level":         "未知的日志级别: %s",
+	"log.level_changed":         "日志级别已设置为 %s",
+	"dialog.export_diagnostics": "导出诊断包",
+	"filter.zip":                "ZIP 压缩包",
+	"dialog.canceled":           "已取消",
+	"diag.export_failed":        "导出诊断包失败: %v",
+	"diag.exported":             "已导出诊断包: %s",
+	"flag.verbose":              "输出详细日志（debug 级别）到标准错误",
+
+	// jobs
+	"job.canceled":            "已取消",
+	"job.not_found":           "没有正在执行的任务: %s",
+	"job.cancel_requested":    "已请求取消",
+	"directory.canceled":      "；%d 个已取消",
+	"job.queued":              "已加入队列",
+	"job.running":             "正在执行",
+	"job.resumed":             "上次退出时未完成，已重新加入队列",
+	"job.unknown_kind":        "未知的任务类型: %s",
+	"job.invalid_request":     "任务请求无效：%s 任务缺少路径或选项",
+	"job.compress_summary":    "共 %d 个文件：成功 %d，失败 %d",
+	"job.invalid_concurrency": "同时执行的任务数必须在 1-%d 之间",
+	"job.concurrency_changed": "同时执行的任务数已设置为 %d",
+	"gif.progress.reading":    "正在读取第 %d/%d 帧",
+	"responsive.progress":     "正在生成 %d 像素宽的变体",
+
+	// server
+	"server.listening":            "HTTP API 已启动：http://%s（Ctrl+C 退出）",
+	"server.stopped":              "HTTP API 已停止",
+	"server.failed":               "HTTP API 启动失败: %v",
+	"server.no_file":              "请求中没有图片",
+	"server.need_multipart":       "需要使用 multipart/form-data 上传多张序列帧",
+	"server.read_upload":          "读取上传的文件失败: %s",
+	"server.read_body":            "读取请求失败",
+	"server.body_too_large":       "请求超过大小上限 %s",
+	"server.invalid_options_json": "options 参数不是有效的 JSON",
+	"server.invalid_option":       "参数 %s 的值无效: %s",
+	"server.invalid_quality":      "质量必须在 1-100 之间，当前为 %d",
+	"flag.addr":                   "监听地址",
+	"flag.max_body":               "请求体大小上限（MB）",
+	"flag.concurrency":            "同时处理的请求数",
+
+	// tinify
+	"tinify.keys_read_failed":  "读取 API 密钥配置失败: %s",
+	"tinify.keys_invalid":      "API 密钥配置格式错误: %s",
+	"tinify.keys_loaded":       "TinyPNG 兼容接口已启用：%d 个 API 密钥（%s）",
+	"tinify.no_keys":           "未配置 API 密钥，请在 %s 中添加",
+	"tinify.unauthorized":      "API 密钥无效",
+	"tinify.bad_source":        "source.url 必须是 http 或 https 地址",
+	"tinify.fetch_failed":      "下载 %s 失败",
+	"tinify.source_blocked":    "%s 指向本机或内网地址，不允许下载",
+	"tinify.output_not_found":  "压缩结果不存在或已过期",
+	"tinify.store_unsupported": "不支持 store（上传到云存储）",
+	"tinify.bad_convert":       "convert.type 必须是 MIME 类型或其数组",
+	"tinify.scale_dimension":   "scale 方式只能指定宽度或高度其中之一",
+	"tinify.need_dimensions":   "%s 方式需要同时指定宽度和高度",
+	"tinify.bad_resize_method": "未知的缩放方式: %s",
+	"tinify.bad_preserve":      "未知的元数据类别: %s",
+	"flag.keys":                "TinyPNG 兼容接口的 API 密钥配置文件，默认为数据目录下的 api-keys.json",
+
+	// grpc
+	"server.grpc_listening": "gRPC 接口已启动：%s",
+	"flag.grpc_addr":        "gRPC 接口的监听地址，为空时不启用",
+
+	// proxy
+	"proxy.root_failed": "无法打开图片根目录: %s",
+	"proxy.enabled":     "图片 URL 处理已启用：%s，如 http://%s/img/rs:fit:800:600/q:75/f:webp/<路径>",
+	"proxy.no_path":     "URL 中缺少图片路径",
+	"proxy.bad_option":  "处理选项 %s 的参数无效: %s",
+	"proxy.not_found":   "图片不存在: %s",
+	"flag.proxy_root":   "图片根目录，设置后可按 /img/rs:fit:800:600/q:75/f:webp/<路径> 形式的 URL 实时处理图片",
+
+	// best format
+	"err.no_accepted_format": "没有可用的输出格式（可接受的格式: %s）",
+	"compress.best_format":   "；最小的格式为 %s",
+
+	// auto format
+	"compress.auto_format":     "；自动选择 %s：%s",
+	"auto.png_lossless":        "只有 %d 种颜色，使用无损索引色 PNG",
+	"auto.png_quantized":       "%d%% 为纯色区域、%d%% 为硬边缘（图形或截图），使用量化 PNG 避免 JPEG 伪影",
+	"auto.png_quantized_alpha": "带透明通道且当前平台不支持 WebP，使用量化 PNG",
+	"auto.webp":                "连续色调（%d%% 的相邻像素有细微差异）且带透明通道，使用 WebP",
+	"auto.jpeg":                "连续色调（%d%% 的相邻像素有细微差异，约 %d 种颜色），按照片使用 JPEG",
+
+	// background
+	"err.invalid_background":        "无效的背景色: %s（应为 #rrggbb、#rgb 或 auto）",
+	"compress.warn_alpha_flattened": "输出格式不支持透明通道，透明区域已合成到背景色 %s 上",
+
+	// jpeg encoder
+	"err.invalid_subsampling": "无效的色度抽样: %s（应为 444、422 或 420）",
+	"err.invalid_quant_table": "自定义量化表应为 64 或 128 个数，实际为 %d 个",
+	"err.invalid_quant_value": "无效的量化值: %s（应为 1-255）",
+
+	// jpeg lossless
+	"compress.lossless_suffix": "（无损优化，像素未改变）",
+
+	// png optimizer
+	"err.invalid_png_effort": "无效的 PNG 优化级别: %d（应为 0-3）",
+}
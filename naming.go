@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 输出文件冲突策略
+const (
+	CollisionOverwrite = "overwrite" // 覆盖已存在的文件（默认）
+	CollisionSkip      = "skip"      // 跳过，不写入
+	CollisionIncrement = "increment" // 自动追加序号：name-1.ext、name-2.ext ...
+	CollisionFail      = "fail"      // 返回错误
+)
+
+// 默认文件名模板：输出到其他目录时保持原名，写回源文件所在目录时加 .min，避免覆盖源文件
+const (
+	defaultNameTemplate        = "{name}.{ext}"
+	defaultSameDirNameTemplate = "{name}.min.{ext}"
+)
+
+// isMinifiedName 判断是否为默认模板写回源目录生成的文件（如 photo.min.jpg）
+func isMinifiedName(name string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(name, filepath.Ext(name)), ".min")
+}
+
+// nameVars 输出文件名模板变量
+type nameVars struct {
+	Name    string // 源文件名（不含扩展名）
+	Ext     string // 输出扩展名（不含点）
+	Width   int
+	Height  int
+	Quality int
+	Data    []byte // 输出内容，用于 {hash}
+}
+
+// renderNameTemplate 渲染文件名模板
+// 支持 {name} {ext} {w} {h} {quality} {date} {hash}
+func renderNameTemplate(tmpl string, v nameVars) string {
+	replacements := []string{
+		"{name}", v.Name,
+		"{ext}", v.Ext,
+		"{w}", strconv.Itoa(v.Width),
+		"{h}", strconv.Itoa(v.Height),
+		"{quality}", strconv.Itoa(v.Quality),
+		"{date}", time.Now().Format("20060102"),
+	}
+	if strings.Contains(tmpl, "{hash}") {
+		replacements = append(replacements, "{hash}", hashBytes(v.Data)[:8])
+	}
+	return strings.NewReplacer(replacements...).Replace(tmpl)
+}
+
+// resolveOutputPath 按冲突策略确定最终输出路径
+// skip 为 true 表示目标已存在且策略为跳过；始终拒绝覆盖源文件（原地优化另行处理）
+func resolveOutputPath(outputPath, inputPath, policy string) (string, bool, error) {
+	overwritesInput := inputPath != "" && sameFilePath(outputPath, inputPath)
+
+	_, statErr := os.Stat(outputPath)
+	exists := statErr == nil
+
+	switch policy {
+	case CollisionSkip:
+		if exists {
+			return outputPath, true, nil
+		}
+	case CollisionIncrement:
+		if exists {
+			return nextFreePath(outputPath)
+		}
+	case CollisionFail:
+		if exists {
+			return "", false, newError(ErrWrite, nil, "err.output_exists", outputPath)
+		}
+	case "", CollisionOverwrite:
+		if overwritesInput {
+			return "", false, newError(ErrInvalidOptions, nil, "err.overwrite_input", outputPath)
+		}
+	default:
+		return "", false, newError(ErrInvalidOptions, nil, "err.unknown_collision", policy)
+	}
+
+	return outputPath, false, nil
+}
+
+// nextFreePath 在文件名后追加序号，直到找到不存在的路径
+func nextFreePath(path string) (string, bool, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; i < 10000; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate, false, nil
+		}
+	}
+	return "", false, newError(ErrWrite, nil, "err.no_free_name", path)
+}
+
+// sameFilePath 判断两个路径是否指向同一个文件
+func sameFilePath(a, b string) bool {
+	sa, errA := os.Stat(a)
+	sb, errB := os.Stat(b)
+	if errA == nil && errB == nil {
+		return os.SameFile(sa, sb)
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
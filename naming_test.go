@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderNameTemplate(t *testing.T) {
+	v := nameVars{Name: "photo", Ext: "webp", Width: 640, Height: 480, Quality: 75, Data: []byte("x")}
+	cases := map[string]string{
+		"{name}.{ext}":                    "photo.webp",
+		"{name}.min.{ext}":                "photo.min.webp",
+		"{name}-{w}x{h}-q{quality}.{ext}": "photo-640x480-q75.webp",
+		"{name}.{hash}.{ext}":             "photo." + hashBytes([]byte("x"))[:8] + ".webp",
+	}
+	for tmpl, want := range cases {
+		if got := renderNameTemplate(tmpl, v); got != want {
+			t.Errorf("%s: got %q, want %q", tmpl, got, want)
+		}
+	}
+}
+
+// 各冲突策略在目标存在、不存在以及目标就是源文件时的结果
+func TestResolveOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	input := testFile(t, filepath.Join(dir, "photo.jpg"), "in", testMtime)
+	existing := testFile(t, filepath.Join(dir, "out.jpg"), "out", testMtime)
+	testFile(t, filepath.Join(dir, "out-1.jpg"), "out", testMtime)
+	missing := filepath.Join(dir, "new.jpg")
+
+	cases := []struct {
+		output, policy string
+		want           string // 空表示返回错误
+		skip           bool
+		err            error
+	}{
+		{missing, "", missing, false, nil},
+		{existing, "", existing, false, nil},
+		{existing, CollisionOverwrite, existing, false, nil},
+		{input, "", "", false, ErrInvalidOptions},
+		{input, CollisionOverwrite, "", false, ErrInvalidOptions},
+		{missing, CollisionSkip, missing, false, nil},
+		{existing, CollisionSkip, existing, true, nil},
+		{input, CollisionSkip, input, true, nil},
+		{missing, CollisionIncrement, missing, false, nil},
+		{existing, CollisionIncrement, filepath.Join(dir, "out-2.jpg"), false, nil},
+		{input, CollisionIncrement, filepath.Join(dir, "photo-1.jpg"), false, nil},
+		{missing, CollisionFail, missing, false, nil},
+		{existing, CollisionFail, "", false, ErrWrite},
+		{missing, "rename", "", false, ErrInvalidOptions},
+	}
+	for _, c := range cases {
+		got, skip, err := resolveOutputPath(c.output, input, c.policy)
+		name := filepath.Base(c.output) + "/" + c.policy
+		if c.err != nil {
+			if !errors.Is(err, c.err) {
+				t.Errorf("%s: want %v, got %v", name, c.err, err)
+			}
+			continue
+		}
+		if err != nil || got != c.want || skip != c.skip {
+			t.Errorf("%s: got %q skip=%v err=%v, want %q skip=%v", name, got, skip, err, c.want, c.skip)
+		}
+	}
+}
+
+// 不指定输出目录和模板时写到源文件旁的 .min 文件，不会因覆盖源文件而失败
+func TestCompressDefaultName(t *testing.T) {
+	testDataDirs(t)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "photo.png")
+	f, err := os.Create(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, testPhoto(33, 17)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	outDir := t.TempDir()
+	cases := []struct {
+		outputDir, want string
+	}{
+		{"", filepath.Join(dir, "photo.min.png")},
+		{dir, filepath.Join(dir, "photo.min.png")},
+		{outDir, filepath.Join(outDir, "photo.png")},
+	}
+	for _, c := range cases {
+		r := NewApp().CompressImage(input, CompressOptions{Quality: 80, OutputFormat: "original", KeepAspect: true, OutputDir: c.outputDir})
+		if !r.Success || r.OutputPath != c.want {
+			t.Errorf("outputDir %q: success=%v output %q (%s), want %q", c.outputDir, r.Success, r.OutputPath, r.Message, c.want)
+		}
+	}
+
+	// 文件夹压缩写回源目录时不再处理上次生成的 .min 文件
+	files, err := collectDirectoryImages(dir, DirectoryOptions{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != "photo.png" {
+		t.Errorf("collected %q", files)
+	}
+}
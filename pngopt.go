@@ -0,0 +1,655 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// PNG 无损优化级别（CompressOptions.PNGEffort）
+const (
+	PNGEffortOff      = 0 // 使用标准库编码器（默认）
+	PNGEffortFast     = 1 // 缩减位深和颜色类型（索引色、灰度、真彩色中最小的），尝试几种常用的滤波策略
+	PNGEffortThorough = 2 // 再尝试所有滤波策略
+	PNGEffortMax      = 3 // 再加逐行试压缩选择滤波器和类似 zopfli 的 deflate，速度很慢
+)
+
+// zopfliIterations 最高优化级别时 deflate 解析的迭代次数
+const zopfliIterations = 10
+
+// PNG 颜色类型
+const (
+	pngGray      = 0
+	pngRGB       = 2
+	pngPalette   = 3
+	pngGrayAlpha = 4
+	pngRGBA      = 6
+)
+
+// 逐行滤波的选择策略：0-4 为所有行使用同一种滤波器，其余为逐行选择
+const (
+	pngStrategyMinSum  = 5 + iota // 绝对值之和最小（与 libpng 和标准库相同）
+	pngStrategyEntropy            // 字节分布的熵最小
+	pngStrategyBigrams            // 不同的相邻字节对最少
+	pngStrategyBrute              // 与前几行一起试压缩，结果最小
+)
+
+// validatePNGEffort 检查 PNG 优化级别
+func validatePNGEffort(effort int) error {
+	if effort < PNGEffortOff || effort > PNGEffortMax {
+		return newError(ErrInvalidOptions, nil, "err.invalid_png_effort", effort)
+	}
+	return nil
+}
+
+// pngRaw 一种颜色表示下的 PNG 图像数据：IHDR 参数、调色板和未滤波的扫描行
+type pngRaw struct {
+	width, height int
+	depth         int
+	colorType     int
+	palette       []color.NRGBA
+	stride        int
+	pix           []byte
+	bpp           int // 滤波时左侧像素的字节距离（不足 1 字节按 1）
+}
+
+// encodePNG 按优化级别编码 PNG：级别为 0 时使用标准库编码器，
+// 否则尝试可以无损表示图片的颜色类型和位深以及多种滤波策略，保留压缩后最小的结果
+// 输出只包含 IHDR、PLTE、tRNS、IDAT 和 IEND，不写任何辅助块
+func encodePNG(ctx context.Context, img image.Image, effort int) ([]byte, error) {
+	var buf bytes.Buffer
+	if effort <= PNGEffortOff {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	candidates, err := pngCandidates(ctx, img)
+	if err != nil {
+		return nil, err
+	}
+	strategies := []int{0, pngStrategyMinSum, pngStrategyEntropy} // 滤波器 0 即不滤波
+	if effort >= PNGEffortThorough {
+		strategies = []int{0, 1, 2, 3, 4, pngStrategyMinSum, pngStrategyEntropy, pngStrategyBigrams}
+	}
+	if effort >= PNGEffortMax {
+		strategies = append(strategies, pngStrategyBrute)
+	}
+
+	// 各颜色表示和滤波策略的组合并行试压缩，比较包括调色板在内的大小
+	type trial struct {
+		raw                  *pngRaw
+		strategy             int
+		filtered, compressed []byte
+		err                  error
+	}
+	var trials []*trial
+	for _, raw := range candidates {
+		for _, s := range strategies {
+			trials = append(trials, &trial{raw: raw, strategy: s})
+		}
+	}
+	var wg sync.WaitGroup
+	// 标准库编码器的结果也参与比较，个别情况下它的逐行滤波选择更好，优化结果不会比它大
+	var std []byte
+	var stdErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		std, stdErr = encodePNG(ctx, img, PNGEffortOff)
+	}()
+	sem := make(chan struct{}, runtime.NumCPU())
+	for _, t := range trials {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			if t.err = checkCanceled(ctx); t.err != nil {
+				return
+			}
+			t.filtered = t.raw.filter(t.strategy)
+			t.compressed, t.err = zlibCompress(t.filtered)
+		}()
+	}
+	wg.Wait()
+	if stdErr != nil {
+		return nil, stdErr
+	}
+	var best *trial
+	for _, t := range trials {
+		if t.err != nil {
+			return nil, t.err
+		}
+		if best == nil || len(t.compressed)+t.raw.overhead() < len(best.compressed)+best.raw.overhead() {
+			best = t
+		}
+	}
+	bestData := best.compressed
+
+	// 最高级别只对最好的组合做慢速压缩
+	if effort >= PNGEffortMax {
+		zdata, err := zopfliZlib(ctx, best.filtered, zopfliIterations)
+		if err != nil {
+			return nil, err
+		}
+		if len(zdata) < len(bestData) {
+			bestData = zdata
+		}
+	}
+	logger.Debug("png optimize", "effort", effort, "colorType", best.raw.colorType, "depth", best.raw.depth,
+		"palette", len(best.raw.palette), "strategy", best.strategy, "idat", len(bestData))
+	if out := writePNG(best.raw, bestData); len(out) <= len(std) {
+		return out, nil
+	}
+	return std, nil
+}
+
+// overhead 调色板和透明度块的字节数
+func (r *pngRaw) overhead() int {
+	if r.colorType != pngPalette {
+		return 0
+	}
+	n := 12 + 3*len(r.palette)
+	if len(r.palette) > 0 && r.palette[0].A < 255 {
+		n += 12
+		for _, c := range r.palette {
+			if c.A < 255 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// zlibCompress 用 zlib 最高级别压缩
+func zlibCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writePNG 写出 PNG 文件
+func writePNG(raw *pngRaw, idat []byte) []byte {
+	out := []byte("\x89PNG\r\n\x1a\n")
+	ihdr := binary.BigEndian.AppendUint32(nil, uint32(raw.width))
+	ihdr = binary.BigEndian.AppendUint32(ihdr, uint32(raw.height))
+	ihdr = append(ihdr, byte(raw.depth), byte(raw.colorType), 0, 0, 0)
+	out = appendPNGChunk(out, "IHDR", ihdr)
+	if raw.colorType == pngPalette {
+		var plte, trns []byte
+		for _, c := range raw.palette {
+			plte = append(plte, c.R, c.G, c.B)
+			if c.A < 255 {
+				trns = append(trns, c.A) // 半透明的颜色排在调色板前面
+			}
+		}
+		out = appendPNGChunk(out, "PLTE", plte)
+		if len(trns) > 0 {
+			out = appendPNGChunk(out, "tRNS", trns)
+		}
+	}
+	out = appendPNGChunk(out, "IDAT", idat)
+	return appendPNGChunk(out, "IEND", nil)
+}
+
+func appendPNGChunk(out []byte, typ string, data []byte) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(data)))
+	start := len(out)
+	out = append(out, typ...)
+	out = append(out, data...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[start:]))
+}
+
+// pngCandidates 生成可以无损表示图片的颜色表示：不超过 256 种颜色时为索引色，另外一种为最少通道的灰度或真彩色
+func pngCandidates(ctx context.Context, img image.Image) ([]*pngRaw, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// 16 位图片：每个采样的高低字节都相同时可以缩减为 8 位
+	if deep, ok := pngDeepImage(img); ok {
+		return pngDeepCandidates(ctx, deep)
+	}
+
+	src := pngNRGBA(img)
+	opaque, gray := true, true
+	counts := make(map[color.NRGBA]int)
+	for y := 0; y < h; y++ {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		for x := 0; x < len(row); x += 4 {
+			c := color.NRGBA{row[x], row[x+1], row[x+2], row[x+3]}
+			opaque = opaque && c.A == 255
+			gray = gray && c.R == c.G && c.G == c.B
+			if len(counts) <= 256 {
+				counts[c]++
+			}
+		}
+	}
+
+	var out []*pngRaw
+	palette := len(counts) <= 256
+	grayDepth := 8
+	if gray && opaque {
+		grayDepth = 1
+		for c := range counts {
+			grayDepth = max(grayDepth, pngGrayDepth(c.R))
+		}
+		if !palette {
+			grayDepth = 8
+		}
+	}
+	if palette {
+		out = append(out, pngPaletteRaw(src, counts))
+	}
+
+	raw := &pngRaw{width: w, height: h, depth: 8}
+	switch {
+	case gray && opaque:
+		raw.colorType, raw.depth = pngGray, grayDepth
+	case gray:
+		raw.colorType = pngGrayAlpha
+	case opaque:
+		raw.colorType = pngRGB
+	default:
+		raw.colorType = pngRGBA
+	}
+	channels := map[int]int{pngGray: 1, pngGrayAlpha: 2, pngRGB: 3, pngRGBA: 4}[raw.colorType]
+	raw.stride = (w*channels*raw.depth + 7) / 8
+	raw.bpp = max(1, channels*raw.depth/8)
+	raw.pix = make([]byte, raw.stride*h)
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		dst := raw.pix[y*raw.stride : (y+1)*raw.stride]
+		for x := 0; x < w; x++ {
+			p := row[x*4 : x*4+4]
+			switch raw.colorType {
+			case pngGray:
+				packPNGSample(dst, x, raw.depth, int(p[0])*(1<<raw.depth-1)/255)
+			case pngGrayAlpha:
+				dst[x*2], dst[x*2+1] = p[0], p[3]
+			case pngRGB:
+				copy(dst[x*3:], p[:3])
+			default:
+				copy(dst[x*4:], p)
+			}
+		}
+	}
+	return append(out, raw), nil
+}
+
+// pngNRGBA 转换为 8 位不预乘的 NRGBA。不预乘的图片直接复制颜色值，
+// 不经过预乘的中间表示，完全透明像素的颜色也保持不变
+func pngNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	switch src := img.(type) {
+	case *image.NRGBA:
+		return src
+	case *image.NRGBA64:
+		dst := image.NewNRGBA(b)
+		for y := 0; y < b.Dy(); y++ {
+			row := src.Pix[y*src.Stride : y*src.Stride+b.Dx()*8]
+			out := dst.Pix[y*dst.Stride : y*dst.Stride+b.Dx()*4]
+			for i := range out {
+				out[i] = row[i*2] // 调用前已确认高低字节相同
+			}
+		}
+		return dst
+	case *image.Paletted:
+		palette := make([]color.NRGBA, len(src.Palette))
+		for i, c := range src.Palette {
+			if n, ok := c.(color.NRGBA); ok {
+				palette[i] = n
+			} else {
+				palette[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+			}
+		}
+		dst := image.NewNRGBA(b)
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				c := palette[src.Pix[y*src.Stride+x]]
+				copy(dst.Pix[y*dst.Stride+x*4:], []byte{c.R, c.G, c.B, c.A})
+			}
+		}
+		return dst
+	}
+	dst := image.NewNRGBA(b)
+	draw.Draw(dst, b, img, b.Min, draw.Src)
+	return dst
+}
+
+// pngGrayDepth 返回能精确表示灰度值 v 的最小位深
+func pngGrayDepth(v uint8) int {
+	for _, d := range []int{1, 2, 4} {
+		if int(v)%(255/(1<<d-1)) == 0 {
+			return d
+		}
+	}
+	return 8
+}
+
+// packPNGSample 把位深为 depth 的第 x 个采样写入扫描行（高位在前）
+func packPNGSample(row []byte, x, depth, v int) {
+	if depth == 8 {
+		row[x] = byte(v)
+		return
+	}
+	perByte := 8 / depth
+	shift := 8 - depth*(x%perByte+1)
+	row[x/perByte] |= byte(v << shift)
+}
+
+// pngPaletteRaw 生成索引色数据：半透明的颜色排在前面（tRNS 可以更短），其余按出现次数排序
+func pngPaletteRaw(src *image.NRGBA, counts map[color.NRGBA]int) *pngRaw {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	palette := make([]color.NRGBA, 0, len(counts))
+	for c := range counts {
+		palette = append(palette, c)
+	}
+	sort.Slice(palette, func(i, j int) bool {
+		a, c := palette[i], palette[j]
+		if (a.A < 255) != (c.A < 255) {
+			return a.A < 255
+		}
+		if counts[a] != counts[c] {
+			return counts[a] > counts[c]
+		}
+		return pngColorKey(a) < pngColorKey(c)
+	})
+	index := make(map[color.NRGBA]int, len(palette))
+	for i, c := range palette {
+		index[c] = i
+	}
+
+	raw := &pngRaw{width: w, height: h, colorType: pngPalette, palette: palette, bpp: 1}
+	switch {
+	case len(palette) <= 2:
+		raw.depth = 1
+	case len(palette) <= 4:
+		raw.depth = 2
+	case len(palette) <= 16:
+		raw.depth = 4
+	default:
+		raw.depth = 8
+	}
+	raw.stride = (w*raw.depth + 7) / 8
+	raw.pix = make([]byte, raw.stride*h)
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*4]
+		dst := raw.pix[y*raw.stride : (y+1)*raw.stride]
+		for x := 0; x < w; x++ {
+			p := row[x*4 : x*4+4]
+			packPNGSample(dst, x, raw.depth, index[color.NRGBA{p[0], p[1], p[2], p[3]}])
+		}
+	}
+	return raw
+}
+
+func pngColorKey(c color.NRGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
+
+// pngDeepImage 16 位图片确实需要 16 位（某个采样的高低字节不同）时返回其 NRGBA64 表示
+func pngDeepImage(img image.Image) (*image.NRGBA64, bool) {
+	switch img.(type) {
+	case *image.Gray16, *image.NRGBA64, *image.RGBA64:
+	default:
+		return nil, false
+	}
+	b := img.Bounds()
+	deep, ok := img.(*image.NRGBA64)
+	if !ok {
+		deep = image.NewNRGBA64(b)
+		draw.Draw(deep, b, img, b.Min, draw.Src)
+	}
+	for i := 0; i < len(deep.Pix); i += 2 {
+		if deep.Pix[i] != deep.Pix[i+1] {
+			return deep, true
+		}
+	}
+	return nil, false
+}
+
+// pngDeepCandidates 16 位图片只缩减颜色类型（灰度、去掉不透明的 alpha 通道）
+func pngDeepCandidates(ctx context.Context, src *image.NRGBA64) ([]*pngRaw, error) {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	opaque, gray := true, true
+	for y := 0; y < h; y++ {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
+		row := src.Pix[y*src.Stride : y*src.Stride+w*8]
+		for x := 0; x < len(row); x += 8 {
+			p := row[x : x+8]
+			opaque = opaque && p[6] == 0xFF && p[7] == 0xFF
+			gray = gray && bytes.Equal(p[0:2], p[2:4]) && bytes.Equal(p[2:4], p[4:6])
+		}
+	}
+	raw := &pngRaw{width: w, height: h, depth: 16}
+	var channels []int // 每个像素写出的通道（NRGBA64 中的下标）
+	switch {
+	case gray && opaque:
+		raw.colorType, channels = pngGray, []int{0}
+	case gray:
+		raw.colorType, channels = pngGrayAlpha, []int{0, 3}
+	case opaque:
+		raw.colorType, channels = pngRGB, []int{0, 1, 2}
+	default:
+		raw.colorType, channels = pngRGBA, []int{0, 1, 2, 3}
+	}
+	raw.bpp = len(channels) * 2
+	raw.stride = w * raw.bpp
+	raw.pix = make([]byte, 0, raw.stride*h)
+	for y := 0; y < h; y++ {
+		row := src.Pix[y*src.Stride : y*src.Stride+w*8]
+		for x := 0; x < w; x++ {
+			for _, c := range channels {
+				raw.pix = append(raw.pix, row[x*8+c*2], row[x*8+c*2+1])
+			}
+		}
+	}
+	return []*pngRaw{raw}, nil
+}
+
+// filter 按策略对所有扫描行滤波，返回 IDAT 压缩前的数据（每行前面是滤波器类型）
+func (r *pngRaw) filter(strategy int) []byte {
+	out := make([]byte, 0, (r.stride+1)*r.height)
+	prev := make([]byte, r.stride)
+	var trial [5][]byte
+	for i := range trial {
+		trial[i] = make([]byte, r.stride)
+	}
+	var brute pngBrute
+	for y := 0; y < r.height; y++ {
+		cur := r.pix[y*r.stride : (y+1)*r.stride]
+		if strategy < 5 {
+			filterPNGRow(strategy, cur, prev, r.bpp, trial[0])
+			out = append(append(out, byte(strategy)), trial[0]...)
+			prev = cur
+			continue
+		}
+		best, bestScore := 0, math.Inf(1)
+		for f := 0; f < 5; f++ {
+			filterPNGRow(f, cur, prev, r.bpp, trial[f])
+			var score float64
+			switch strategy {
+			case pngStrategyMinSum:
+				score = pngMinSum(trial[f])
+			case pngStrategyEntropy:
+				score = pngEntropy(f, trial[f])
+			case pngStrategyBigrams:
+				score = pngBigrams(f, trial[f])
+			default:
+				score = brute.score(out, r.stride, f, trial[f])
+			}
+			if score < bestScore {
+				best, bestScore = f, score
+			}
+		}
+		out = append(append(out, byte(best)), trial[best]...)
+		prev = cur
+	}
+	return out
+}
+
+// filterPNGRow 用滤波器 f（0-4：None、Sub、Up、Average、Paeth）处理一行
+func filterPNGRow(f int, cur, prev []byte, bpp int, out []byte) {
+	for i := range cur {
+		var a, c byte
+		if i >= bpp {
+			a, c = cur[i-bpp], prev[i-bpp]
+		}
+		b := prev[i]
+		switch f {
+		case 0:
+			out[i] = cur[i]
+		case 1:
+			out[i] = cur[i] - a
+		case 2:
+			out[i] = cur[i] - b
+		case 3:
+			out[i] = cur[i] - byte((int(a)+int(b))/2)
+		default:
+			out[i] = cur[i] - paeth(a, b, c)
+		}
+	}
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// pngMinSum 把字节当作有符号数的绝对值之和
+func pngMinSum(row []byte) float64 {
+	sum := 0
+	for _, v := range row {
+		sum += abs(int(int8(v)))
+	}
+	return float64(sum)
+}
+
+// pngEntropy 包括滤波器类型在内的字节分布的熵（总位数）
+func pngEntropy(f int, row []byte) float64 {
+	var counts [256]int
+	counts[f]++
+	for _, v := range row {
+		counts[v]++
+	}
+	n := float64(len(row) + 1)
+	var bitsTotal float64
+	for _, c := range counts {
+		if c > 0 {
+			bitsTotal -= float64(c) * math.Log2(float64(c)/n)
+		}
+	}
+	return bitsTotal
+}
+
+// pngBigrams 不同的相邻字节对的个数
+func pngBigrams(f int, row []byte) float64 {
+	seen := make(map[uint16]struct{}, len(row))
+	prev := byte(f)
+	for _, v := range row {
+		seen[uint16(prev)<<8|uint16(v)] = struct{}{}
+		prev = v
+	}
+	return float64(len(seen))
+}
+
+// pngBruteRows 逐行试压缩时带上的前面几行，使匹配可以引用上方的数据
+const pngBruteRows = 4
+
+// pngBrute 逐行试压缩，复用压缩器
+type pngBrute struct {
+	buf bytes.Buffer
+	zw  *zlib.Writer
+}
+
+// score 与前几行一起用快速级别压缩后的大小
+func (b *pngBrute) score(done []byte, stride, f int, row []byte) float64 {
+	b.buf.Reset()
+	if b.zw == nil {
+		b.zw, _ = zlib.NewWriterLevel(&b.buf, zlib.BestSpeed)
+	} else {
+		b.zw.Reset(&b.buf)
+	}
+	b.zw.Write(done[max(0, len(done)-pngBruteRows*(stride+1)):])
+	b.zw.Write([]byte{byte(f)})
+	b.zw.Write(row)
+	b.zw.Close()
+	return float64(b.buf.Len())
+}
+
+// pngColorChunks 返回 PNG 中影响颜色显示的辅助块（cICP、iCCP、sRGB、gAMA、cHRM，含长度和 CRC），
+// 优化时保留这些块，其余辅助块（文本、时间、EXIF 等）全部去掉
+func pngColorChunks(data []byte) [][]byte {
+	if len(data) < 8 || !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return nil
+	}
+	var chunks [][]byte
+	for i := 8; i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			break
+		}
+		switch string(data[i+4 : i+8]) {
+		case "cICP", "iCCP", "sRGB", "gAMA", "cHRM":
+			chunks = append(chunks, data[i:end])
+		case "PLTE", "IDAT":
+			return chunks // 这些块必须位于 PLTE 和 IDAT 之前
+		}
+		i = end
+	}
+	return chunks
+}
+
+// insertPNGChunks 在 IHDR 之后插入块
+func insertPNGChunks(data []byte, chunks [][]byte) []byte {
+	const ihdrEnd = 8 + 12 + 13
+	if len(chunks) == 0 || len(data) < ihdrEnd {
+		return data
+	}
+	out := append([]byte{}, data[:ihdrEnd]...)
+	for _, c := range chunks {
+		out = append(out, c...)
+	}
+	return append(out, data[ihdrEnd:]...)
+}
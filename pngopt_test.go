@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// testPNGImage 按 f 生成每个像素的颜色
+func testPNGImage(w, h int, f func(x, y int) color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, f(x, y))
+		}
+	}
+	return img
+}
+
+// testGrayLevels 只使用 levels 种灰度值（位深为 1/2/4 时可以精确表示）
+func testGrayLevels(w, h, levels int) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{uint8((x + 3*y) % levels * 255 / (levels - 1))})
+		}
+	}
+	return img
+}
+
+// testNoise 由坐标得到的伪随机数，避免图案规律到让真彩色加滤波比索引色更小
+func testNoise(x, y int) int {
+	h := uint32(x)*73856093 ^ uint32(y)*19349663
+	h ^= h >> 13
+	h *= 0x5bd1e995
+	return int(h>>16) ^ int(h>>8)&0xFF
+}
+
+// testDeep 16 位图片，reducible 为 true 时高低字节相同（可以缩减为 8 位）
+func testDeep(w, h int, gray, alpha, reducible bool) *image.NRGBA64 {
+	img := image.NewNRGBA64(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint16(x*4099 + y*257*13)
+			c := color.NRGBA64{v, v ^ 0x5a5a, v + 999, 0xFFFF}
+			if gray {
+				c.G, c.B = v, v
+			}
+			if alpha {
+				c.A = uint16(y*0x1111 + x)
+			}
+			if reducible {
+				c.R, c.G, c.B, c.A = c.R>>8*0x101, c.G>>8*0x101, c.B>>8*0x101, c.A>>8*0x101
+			}
+			img.SetNRGBA64(x, y, c)
+		}
+	}
+	return img
+}
+
+// testPNGImages 各种颜色类型和位深的测试图片
+func testPNGImages(w, h int) map[string]image.Image {
+	photo := testPhoto(w, h)
+	paletted := image.NewPaletted(image.Rect(0, 0, w, h), color.Palette{
+		color.NRGBA{255, 0, 0, 255}, color.NRGBA{0, 0, 255, 128}, color.NRGBA{50, 60, 70, 0}, color.NRGBA{9, 99, 199, 255},
+	})
+	for i := range paletted.Pix {
+		paletted.Pix[i] = uint8(testNoise(i, 0) % 4)
+	}
+	return map[string]image.Image{
+		"gray1":     testGrayLevels(w, h, 2),
+		"gray2":     testGrayLevels(w, h, 4),
+		"gray4":     testGrayLevels(w, h, 16),
+		"gray8":     testGray(w, h),
+		"grayAlpha": testPNGImage(w, h, func(x, y int) color.NRGBA { v := uint8(x * 37); return color.NRGBA{v, v, v, uint8(y*29 + x)} }),
+		"rgb":       photo,
+		"rgba": testPNGImage(w, h, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x * 7), uint8(y * 11), uint8(x * y), uint8(x*13 + y*5)}
+		}),
+		"palette": testPNGImage(w, h, func(x, y int) color.NRGBA {
+			i := testNoise(x, y) % 15
+			return color.NRGBA{uint8(i % 5 * 60), uint8(i / 5 * 100), 50, 255}
+		}),
+		"paletteAlpha": testPNGImage(w, h, func(x, y int) color.NRGBA {
+			return color.NRGBA{uint8(x % 5 * 60), 10, 20, uint8(y % 4 * 85)}
+		}),
+		"paletted":      paletted,
+		"gray16":        testDeep(w, h, true, false, false),
+		"grayAlpha16":   testDeep(w, h, true, true, false),
+		"rgb16":         testDeep(w, h, false, false, false),
+		"rgba16":        testDeep(w, h, false, true, false),
+		"rgba16reduced": testDeep(w, h, false, true, true),
+	}
+}
+
+// sameNRGBA64 两张图片转换为不预乘的 16 位颜色后每个像素完全相同
+func sameNRGBA64(t *testing.T, want, got image.Image) {
+	t.Helper()
+	if want.Bounds() != got.Bounds() {
+		t.Fatalf("bounds: want %v, got %v", want.Bounds(), got.Bounds())
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			wc, gc := testNRGBA64(want.At(x, y)), testNRGBA64(got.At(x, y))
+			if wc != gc {
+				t.Fatalf("pixel (%d,%d): want %v, got %v", x, y, wc, gc)
+			}
+		}
+	}
+}
+
+// testNRGBA64 不经过预乘转换为 16 位颜色（NRGBA64Model 会丢掉完全透明像素的颜色）
+func testNRGBA64(c color.Color) color.NRGBA64 {
+	switch c := c.(type) {
+	case color.NRGBA:
+		return color.NRGBA64{uint16(c.R) * 0x101, uint16(c.G) * 0x101, uint16(c.B) * 0x101, uint16(c.A) * 0x101}
+	case color.NRGBA64:
+		return c
+	}
+	return color.NRGBA64Model.Convert(c).(color.NRGBA64)
+}
+
+// 各优化级别的输出都能被标准库解码，像素与原图完全相同，且不比标准库编码器大
+func TestEncodePNGRoundTrip(t *testing.T) {
+	for _, size := range [][2]int{{1, 1}, {7, 9}, {33, 17}} {
+		for name, img := range testPNGImages(size[0], size[1]) {
+			for effort := PNGEffortFast; effort <= PNGEffortMax; effort++ {
+				t.Run(fmt.Sprintf("%s/%dx%d/effort%d", name, size[0], size[1], effort), func(t *testing.T) {
+					data, err := encodePNG(context.Background(), img, effort)
+					if err != nil {
+						t.Fatal(err)
+					}
+					decoded, err := png.Decode(bytes.NewReader(data))
+					if err != nil {
+						t.Fatalf("image/png: %v", err)
+					}
+					sameNRGBA64(t, img, decoded)
+
+					std, err := encodePNG(context.Background(), img, PNGEffortOff)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(data) > len(std) {
+						t.Errorf("%d bytes, image/png %d bytes", len(data), len(std))
+					}
+				})
+			}
+		}
+	}
+}
+
+// 选择的颜色类型和位深（IHDR 中的值）
+func TestEncodePNGColorType(t *testing.T) {
+	cases := map[string]struct{ colorType, depth int }{
+		"gray1":         {pngGray, 1},
+		"gray2":         {pngGray, 2},
+		"gray4":         {pngGray, 4},
+		"rgb":           {pngRGB, 8},
+		"rgba":          {pngRGBA, 8},
+		"palette":       {pngPalette, 4},
+		"paletted":      {pngPalette, 2},
+		"gray16":        {pngGray, 16},
+		"grayAlpha16":   {pngGrayAlpha, 16},
+		"rgb16":         {pngRGB, 16},
+		"rgba16":        {pngRGBA, 16},
+		"rgba16reduced": {pngRGBA, 8},
+	}
+	images := testPNGImages(33, 17)
+	for name, want := range cases {
+		data, err := encodePNG(context.Background(), images[name], PNGEffortThorough)
+		if err != nil {
+			t.Fatal(err)
+		}
+		depth, colorType := int(data[24]), int(data[25])
+		if colorType != want.colorType || depth != want.depth {
+			t.Errorf("%s: color type %d depth %d, want %d/%d", name, colorType, depth, want.colorType, want.depth)
+		}
+	}
+}
+
+// 只保留颜色相关的辅助块，并插入到 IHDR 之后
+func TestPNGColorChunks(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testPhoto(7, 9)); err != nil {
+		t.Fatal(err)
+	}
+	const ihdrEnd = 8 + 12 + 13
+	var src []byte
+	src = append(src, buf.Bytes()[:ihdrEnd]...)
+	src = appendPNGChunk(src, "tEXt", []byte("Comment\x00squash-test-text"))
+	src = appendPNGChunk(src, "gAMA", []byte{0, 0, 0xB1, 0x8F})
+	src = appendPNGChunk(src, "sRGB", []byte{0})
+	src = append(src, buf.Bytes()[ihdrEnd:]...)
+
+	chunks := pngColorChunks(src)
+	if len(chunks) != 2 || string(chunks[0][4:8]) != "gAMA" || string(chunks[1][4:8]) != "sRGB" {
+		t.Fatalf("unexpected chunks %q", chunks)
+	}
+
+	optimized, err := encodePNG(context.Background(), testPhoto(7, 9), PNGEffortFast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := insertPNGChunks(optimized, chunks)
+	if bytes.Contains(out, []byte("squash-test-text")) {
+		t.Error("text chunk was kept")
+	}
+	if string(out[ihdrEnd+4:ihdrEnd+8]) != "gAMA" {
+		t.Errorf("gAMA is not right after IHDR")
+	}
+	decoded, err := png.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("image/png: %v", err)
+	}
+	sameNRGBA64(t, testPhoto(7, 9), decoded)
+}
+
+func TestValidatePNGEffort(t *testing.T) {
+	for effort := -1; effort <= PNGEffortMax+1; effort++ {
+		err := validatePNGEffort(effort)
+		valid := effort >= PNGEffortOff && effort <= PNGEffortMax
+		if valid != (err == nil) || (!valid && !errors.Is(err, ErrInvalidOptions)) {
+			t.Errorf("effort %d: %v", effort, err)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// sharedPresetFile 团队共享的预设文件名，可提交到仓库中
+const sharedPresetFile = "squash-presets.json"
+
+// presetFile 预设文件格式（用户预设和共享预设通用）
+type presetFile struct {
+	Version int      `json:"version"`
+	Active  string   `json:"active,omitempty"` // 上次应用的预设，启动时恢复
+	Presets []Preset `json:"presets"`
+}
+
+// presetMu 保护用户预设文件的读写
+var presetMu sync.Mutex
+
+// userPresetPath 返回用户预设文件路径
+func userPresetPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "presets.json"), nil
+}
+
+// readPresetFile 读取预设文件，文件不存在时返回空列表
+func readPresetFile(path string) (presetFile, error) {
+	file := presetFile{Version: 1, Presets: []Preset{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, newError(ErrInvalidOptions, err, "preset.file_invalid", path)
+	}
+	if file.Presets == nil {
+		file.Presets = []Preset{}
+	}
+	return file, nil
+}
+
+// writePresetFile 写入预设文件（按名称排序，便于提交到仓库后比较差异）
+func writePresetFile(path string, file presetFile) error {
+	sort.Slice(file.Presets, func(i, j int) bool {
+		return file.Presets[i].Name < file.Presets[j].Name
+	})
+	file.Version = 1
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// loadUserPresets 读取用户预设
+func loadUserPresets() (presetFile, string, error) {
+	path, err := userPresetPath()
+	if err != nil {
+		return presetFile{}, "", err
+	}
+	file, err := readPresetFile(path)
+	return file, path, err
+}
+
+// upsertPreset 按名称添加或替换预设
+func upsertPreset(presets []Preset, preset Preset) []Preset {
+	for i, p := range presets {
+		if p.Name == preset.Name {
+			presets[i] = preset
+			return presets
+		}
+	}
+	return append(presets, preset)
+}
+
+// findSharedPresetFile 从 dir 向上查找团队共享的预设文件
+func findSharedPresetFile(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, sharedPresetFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findPreset 按名称查找预设：先查找 dir 向上的共享预设文件，再查找用户预设
+func findPreset(name, dir string) (Preset, error) {
+	if dir != "" {
+		if path := findSharedPresetFile(dir); path != "" {
+			file, err := readPresetFile(path)
+			if err != nil {
+				return Preset{}, err
+			}
+			for _, p := range file.Presets {
+				if p.Name == name {
+					return p, nil
+				}
+			}
+		}
+	}
+
+	presetMu.Lock()
+	file, _, err := loadUserPresets()
+	presetMu.Unlock()
+	if err != nil {
+		return Preset{}, err
+	}
+	for _, p := range file.Presets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Preset{}, newError(ErrInvalidOptions, nil, "preset.not_found", name)
+}
+
+// ListPresets 列出用户保存的预设
+func (a *App) ListPresets() []Preset {
+	presetMu.Lock()
+	defer presetMu.Unlock()
+
+	file, _, err := loadUserPresets()
+	if err != nil {
+		return []Preset{}
+	}
+	return file.Presets
+}
+
+// SavePreset 保存预设，同名预设会被覆盖
+func (a *App) SavePreset(preset Preset) ActionResult {
+	preset.Name = strings.TrimSpace(preset.Name)
+	if preset.Name == "" {
+		return ActionResult{Success: false, Message: tr("preset.name_empty")}
+	}
+	if preset.Compress == nil && preset.Gif == nil && preset.GifCompress == nil {
+		return ActionResult{Success: false, Message: tr("preset.empty")}
+	}
+	preset = clearPresetJobIDs(preset)
+
+	presetMu.Lock()
+	defer presetMu.Unlock()
+
+	file, path, err := loadUserPresets()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("preset.read_failed", err)}
+	}
+	file.Presets = upsertPreset(file.Presets, preset)
+	if err := writePresetFile(path, file); err != nil {
+		return ActionResult{Success: false, Message: tr("preset.save_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("preset.saved", preset.Name)}
+}
+
+// clearPresetJobIDs 清除选项中的任务 ID，任务 ID 只对单次调用有效，不保存到预设
+func clearPresetJobIDs(preset Preset) Preset {
+	if preset.Compress != nil {
+		c := *preset.Compress
+		c.JobID = ""
+		preset.Compress = &c
+	}
+	if preset.Gif != nil {
+		g := *preset.Gif
+		g.JobID = ""
+		preset.Gif = &g
+	}
+	if preset.GifCompress != nil {
+		g := *preset.GifCompress
+		g.JobID = ""
+		preset.GifCompress = &g
+	}
+	return preset
+}
+
+// DeletePreset 删除预设
+func (a *App) DeletePreset(name string) ActionResult {
+	presetMu.Lock()
+	defer presetMu.Unlock()
+
+	file, path, err := loadUserPresets()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("preset.read_failed", err)}
+	}
+
+	kept := file.Presets[:0]
+	found := false
+	for _, p := range file.Presets {
+		if p.Name == name {
+			found = true
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if !found {
+		return ActionResult{Success: false, Message: tr("preset.not_found", name)}
+	}
+	file.Presets = kept
+	if file.Active == name {
+		file.Active = ""
+	}
+	if err := writePresetFile(path, file); err != nil {
+		return ActionResult{Success: false, Message: tr("preset.save_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("preset.deleted", name)}
+}
+
+// ApplyPreset 应用预设：返回预设内容并记为当前预设，下次启动时恢复
+func (a *App) ApplyPreset(name string) PresetResult {
+	presetMu.Lock()
+	defer presetMu.Unlock()
+
+	file, path, err := loadUserPresets()
+	if err != nil {
+		return PresetResult{Success: false, Message: tr("preset.read_failed", err)}
+	}
+	for _, p := range file.Presets {
+		if p.Name != name {
+			continue
+		}
+		file.Active = name
+		if err := writePresetFile(path, file); err != nil {
+			return PresetResult{Success: false, Message: tr("preset.save_failed", err)}
+		}
+		return PresetResult{Success: true, Message: tr("preset.applied", name), Preset: p}
+	}
+	return PresetResult{Success: false, Message: tr("preset.not_found", name)}
+}
+
+// GetActivePreset 获取上次应用的预设（没有时 Success 为 false）
+func (a *App) GetActivePreset() PresetResult {
+	presetMu.Lock()
+	defer presetMu.Unlock()
+
+	file, _, err := loadUserPresets()
+	if err != nil || file.Active == "" {
+		return PresetResult{Success: false}
+	}
+	for _, p := range file.Presets {
+		if p.Name == file.Active {
+			return PresetResult{Success: true, Preset: p}
+		}
+	}
+	return PresetResult{Success: false}
+}
+
+// ImportPresets 从文件导入预设（如仓库中的 squash-presets.json），同名预设会被覆盖
+func (a *App) ImportPresets(path string) ActionResult {
+	if _, err := os.Stat(path); err != nil {
+		return ActionResult{Success: false, Message: tr("preset.read_file_failed", err)}
+	}
+	imported, err := readPresetFile(path)
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("preset.read_file_failed", err)}
+	}
+
+	presetMu.Lock()
+	defer presetMu.Unlock()
+
+	file, userPath, err := loadUserPresets()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("preset.read_failed", err)}
+	}
+	count := 0
+	for _, p := range imported.Presets {
+		if strings.TrimSpace(p.Name) == "" {
+			continue
+		}
+		file.Presets = upsertPreset(file.Presets, p)
+		count++
+	}
+	if err := writePresetFile(userPath, file); err != nil {
+		return ActionResult{Success: false, Message: tr("preset.save_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("preset.imported", count)}
+}
+
+// ExportPresets 导出预设到文件，names 为空时导出全部
+func (a *App) ExportPresets(path string, names []string) ActionResult {
+	presetMu.Lock()
+	file, _, err := loadUserPresets()
+	presetMu.Unlock()
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("preset.read_failed", err)}
+	}
+
+	out := presetFile{Presets: []Preset{}}
+	for _, p := range file.Presets {
+		if len(names) == 0 || containsString(names, p.Name) {
+			out.Presets = append(out.Presets, p)
+		}
+	}
+	if err := writePresetFile(path, out); err != nil {
+		return ActionResult{Success: false, Message: tr("export.failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("preset.exported", len(out.Presets), path)}
+}
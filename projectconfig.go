@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// projectConfigNames 项目配置文件名，按优先级排列
+var projectConfigNames = []string{".squashrc", "squash.toml"}
+
+// projectRule 项目配置中的一条按 glob 匹配的规则，未设置的字段不覆盖
+type projectRule struct {
+	Match        string  `json:"match" toml:"match"` // 相对于配置文件所在目录的 glob，如 "icons/**/*.png"
+	Preset       string  `json:"preset" toml:"preset"`
+	Format       *string `json:"format" toml:"format"`
+	Quality      *int    `json:"quality" toml:"quality"`
+	MaxWidth     *uint   `json:"maxWidth" toml:"max_width"`
+	MaxHeight    *uint   `json:"maxHeight" toml:"max_height"`
+	KeepAspect   *bool   `json:"keepAspect" toml:"keep_aspect"`
+	NameTemplate *string `json:"nameTemplate" toml:"name_template"`
+
+	AcceptFormats []string `json:"acceptFormats" toml:"accept_formats"`
+	Background    *string  `json:"background" toml:"background"`
+
+	JPEGProgressive *bool   `json:"jpegProgressive" toml:"jpeg_progressive"`
+	JPEGSubsampling *string `json:"jpegSubsampling" toml:"jpeg_subsampling"`
+	JPEGQuantTable  *string `json:"jpegQuantTable" toml:"jpeg_quant_table"`
+	JPEGLossless    *bool   `json:"jpegLossless" toml:"jpeg_lossless"`
+	PNGEffort       *int    `json:"pngEffort" toml:"png_effort"`
+
+	Skip bool `json:"skip" toml:"skip"` // 匹配的文件不做处理
+}
+
+// projectConfig 项目配置文件
+type projectConfig struct {
+	Path  string        `json:"-" toml:"-"`
+	Rules []projectRule `json:"rules" toml:"rules"`
+
+	modTime time.Time
+}
+
+// projectConfigCache 缓存已解析的配置文件，文件修改后重新解析
+var (
+	projectConfigMu    sync.Mutex
+	projectConfigCache = make(map[string]*projectConfig)
+)
+
+// findProjectConfig 从文件所在目录向上查找最近的项目配置文件
+func findProjectConfig(inputPath string) (*projectConfig, error) {
+	dir, err := filepath.Abs(filepath.Dir(inputPath))
+	if err != nil {
+		return nil, err
+	}
+	for {
+		for _, name := range projectConfigNames {
+			path := filepath.Join(dir, name)
+			if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+				return loadProjectConfig(path, stat.ModTime())
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// loadProjectConfig 解析配置文件：squash.toml 为 TOML，.squashrc 可以是 JSON 或 TOML
+func loadProjectConfig(path string, modTime time.Time) (*projectConfig, error) {
+	projectConfigMu.Lock()
+	defer projectConfigMu.Unlock()
+
+	if cached, ok := projectConfigCache[path]; ok && cached.modTime.Equal(modTime) {
+		return cached, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &projectConfig{}
+	if filepath.Ext(path) == ".toml" {
+		err = toml.Unmarshal(data, config)
+	} else if jsonErr := json.Unmarshal(data, config); jsonErr != nil {
+		config = &projectConfig{}
+		if err = toml.Unmarshal(data, config); err != nil {
+			err = errors.New(tr("config.not_json_or_toml", jsonErr, err))
+		}
+	}
+	if err != nil {
+		return nil, newError(ErrInvalidOptions, err, "config.invalid", path)
+	}
+
+	config.Path = path
+	config.modTime = modTime
+	projectConfigCache[path] = config
+	return config, nil
+}
+
+// projectResolution 项目配置的解析结果
+type projectResolution struct {
+	ConfigPath string   // 使用的配置文件，未找到时为空
+	Rules      []string // 命中的规则（match 模式）
+	Skip       bool     // 命中了 skip 规则
+}
+
+// resolveProjectOptions 按项目配置为单个文件解析压缩选项
+// 所有匹配的规则按顺序应用，后面的规则覆盖前面的
+func resolveProjectOptions(inputPath string, options CompressOptions) (CompressOptions, projectResolution, error) {
+	var res projectResolution
+
+	config, err := findProjectConfig(inputPath)
+	if err != nil || config == nil {
+		return options, res, err
+	}
+	res.ConfigPath = config.Path
+
+	absInput, err := filepath.Abs(inputPath)
+	if err != nil {
+		return options, res, err
+	}
+	rel, err := filepath.Rel(filepath.Dir(config.Path), absInput)
+	if err != nil {
+		return options, res, err
+	}
+
+	for _, rule := range config.Rules {
+		if rule.Match == "" || !matchGlob(rule.Match, rel) {
+			continue
+		}
+		res.Rules = append(res.Rules, rule.Match)
+
+		if rule.Skip {
+			res.Skip = true
+			continue
+		}
+		if rule.Preset != "" {
+			preset, err := findPreset(rule.Preset, filepath.Dir(config.Path))
+			if err != nil {
+				return options, res, err
+			}
+			if preset.Compress != nil {
+				options = mergePresetOptions(options, *preset.Compress)
+			}
+		}
+		if rule.Format != nil {
+			options.OutputFormat = *rule.Format
+		}
+		if rule.Quality != nil {
+			options.Quality = *rule.Quality
+		}
+		if rule.MaxWidth != nil {
+			options.MaxWidth = *rule.MaxWidth
+		}
+		if rule.MaxHeight != nil {
+			options.MaxHeight = *rule.MaxHeight
+		}
+		if rule.KeepAspect != nil {
+			options.KeepAspect = *rule.KeepAspect
+		} else if rule.MaxWidth != nil || rule.MaxHeight != nil {
+			options.KeepAspect = true
+		}
+		if rule.NameTemplate != nil {
+			options.NameTemplate = *rule.NameTemplate
+		}
+		if rule.AcceptFormats != nil {
+			options.AcceptFormats = rule.AcceptFormats
+		}
+		if rule.Background != nil {
+			options.Background = *rule.Background
+		}
+		if rule.JPEGProgressive != nil {
+			options.JPEGProgressive = *rule.JPEGProgressive
+		}
+		if rule.JPEGSubsampling != nil {
+			options.JPEGSubsampling = *rule.JPEGSubsampling
+		}
+		if rule.JPEGQuantTable != nil {
+			options.JPEGQuantTable = *rule.JPEGQuantTable
+		}
+		if rule.JPEGLossless != nil {
+			options.JPEGLossless = *rule.JPEGLossless
+		}
+		if rule.PNGEffort != nil {
+			options.PNGEffort = *rule.PNGEffort
+		}
+	}
+	return options, res, nil
+}
+
+// mergePresetOptions 用预设中的编码相关选项覆盖当前选项，输出位置和写入方式保持不变
+func mergePresetOptions(options, preset CompressOptions) CompressOptions {
+	options.Quality = preset.Quality
+	options.OutputFormat = preset.OutputFormat
+	options.MaxWidth = preset.MaxWidth
+	options.MaxHeight = preset.MaxHeight
+	options.KeepAspect = preset.KeepAspect
+	options.AcceptFormats = preset.AcceptFormats
+	options.Background = preset.Background
+	options.JPEGProgressive = preset.JPEGProgressive
+	options.JPEGSubsampling = preset.JPEGSubsampling
+	options.JPEGQuantTable = preset.JPEGQuantTable
+	options.JPEGLossless = preset.JPEGLossless
+	options.PNGEffort = preset.PNGEffort
+	if preset.NameTemplate != "" {
+		options.NameTemplate = preset.NameTemplate
+	}
+	return options
+}
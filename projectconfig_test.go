@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testProjectFiles 在 dir 下写入文件（相对路径到内容）
+func testProjectFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// 规则按顺序覆盖，预设先展开再被同一规则的字段覆盖，输出位置等不由项目配置决定
+func TestResolveProjectOptions(t *testing.T) {
+	testDataDirs(t)
+	dir := t.TempDir()
+	testProjectFiles(t, dir, map[string]string{
+		"site/squash.toml": `
+[[rules]]
+match = "**"
+quality = 70
+
+[[rules]]
+match = "icons/**/*.png"
+format = "png"
+quality = 95
+png_effort = 3
+
+[[rules]]
+match = "photos/**"
+format = "webp"
+max_width = 2048
+
+[[rules]]
+match = "photos/raw/**"
+skip = true
+
+[[rules]]
+match = "hero/*"
+preset = "hero"
+max_height = 600
+keep_aspect = false
+name_template = "{name}-hero.{ext}"
+
+[[rules]]
+match = "web/*"
+format = "best"
+accept_formats = ["webp", "image/avif"]
+background = "#102030"
+jpeg_progressive = true
+jpeg_subsampling = "444"
+jpeg_quant_table = "mozjpeg"
+`,
+		sharedPresetFile:  `{"version": 1, "presets": [{"name": "hero", "compress": {"quality": 60, "outputFormat": "avif", "maxWidth": 1920, "keepAspect": true, "acceptFormats": ["avif"], "background": "auto", "jpegSubsampling": "422", "pngEffort": 2}}]}`,
+		"other/.squashrc": `{"rules": [{"match": "*.jpg", "quality": 50, "maxWidth": 100, "keepAspect": false}, {"match": "deep/*", "jpegLossless": true}]}`,
+	})
+
+	base := CompressOptions{Quality: 80, OutputFormat: "original", OutputDir: "out", Collision: CollisionIncrement}
+	with := func(f func(o *CompressOptions)) CompressOptions {
+		o := base
+		f(&o)
+		return o
+	}
+	cases := []struct {
+		path  string
+		want  CompressOptions
+		rules []string
+		skip  bool
+	}{
+		{"site/a.jpg", with(func(o *CompressOptions) { o.Quality = 70 }), []string{"**"}, false},
+		{"site/icons/ui/x.png", with(func(o *CompressOptions) { o.Quality, o.OutputFormat, o.PNGEffort = 95, "png", 3 }), []string{"**", "icons/**/*.png"}, false},
+		{"site/icons/ui/x.jpg", with(func(o *CompressOptions) { o.Quality = 70 }), []string{"**"}, false},
+		{"site/photos/2024/p.jpg", with(func(o *CompressOptions) {
+			o.Quality, o.OutputFormat, o.MaxWidth, o.KeepAspect = 70, "webp", 2048, true
+		}), []string{"**", "photos/**"}, false},
+		{"site/photos/raw/p.jpg", with(func(o *CompressOptions) {
+			o.Quality, o.OutputFormat, o.MaxWidth, o.KeepAspect = 70, "webp", 2048, true
+		}), []string{"**", "photos/**", "photos/raw/**"}, true},
+		{"site/hero/h.png", with(func(o *CompressOptions) {
+			o.Quality, o.OutputFormat, o.MaxWidth, o.MaxHeight, o.KeepAspect, o.NameTemplate = 60, "avif", 1920, 600, false, "{name}-hero.{ext}"
+			o.AcceptFormats, o.Background, o.JPEGSubsampling, o.PNGEffort = []string{"avif"}, "auto", "422", 2
+		}), []string{"**", "hero/*"}, false},
+		{"site/web/w.png", with(func(o *CompressOptions) {
+			o.Quality, o.OutputFormat, o.AcceptFormats, o.Background = 70, "best", []string{"webp", "image/avif"}, "#102030"
+			o.JPEGProgressive, o.JPEGSubsampling, o.JPEGQuantTable = true, "444", "mozjpeg"
+		}), []string{"**", "web/*"}, false},
+		{"other/deep/b.jpg", with(func(o *CompressOptions) { o.Quality, o.MaxWidth, o.JPEGLossless = 50, 100, true }), []string{"*.jpg", "deep/*"}, false},
+		{"c.jpg", base, nil, false},
+	}
+	for _, c := range cases {
+		got, res, err := resolveProjectOptions(filepath.Join(dir, filepath.FromSlash(c.path)), base)
+		if err != nil {
+			t.Errorf("%s: %v", c.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: got %+v\nwant %+v", c.path, got, c.want)
+		}
+		if !reflect.DeepEqual(res.Rules, c.rules) || res.Skip != c.skip {
+			t.Errorf("%s: rules %q skip %v, want %q %v", c.path, res.Rules, res.Skip, c.rules, c.skip)
+		}
+	}
+
+	// 引用不存在的预设时返回错误
+	testProjectFiles(t, dir, map[string]string{"broken/squash.toml": "[[rules]]\nmatch = \"*\"\npreset = \"missing\"\n"})
+	if _, _, err := resolveProjectOptions(filepath.Join(dir, "broken", "a.png"), base); err == nil {
+		t.Error("missing preset: no error")
+	}
+}
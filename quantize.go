@@ -1,11 +1,10 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
 	"math"
 	"sort"
 )
@@ -223,7 +222,8 @@ func generateDefaultPalette(numColors int) color.Palette {
 // quantizePNG 将图像量化为索引色 PNG（类似 TinyPNG）
 // quality: 1-100，控制颜色数量 (1=最少颜色/最小文件, 100=256色/最高质量)
 // dither: 是否使用 Floyd-Steinberg 抖动
-func quantizePNG(img image.Image, quality int, dither bool) *image.Paletted {
+// 逐行处理时检查 ctx，取消时返回 ErrCanceled
+func quantizePNG(ctx context.Context, img image.Image, quality int, dither bool) (*image.Paletted, error) {
 	// 根据质量计算颜色数量
 	// quality 1-100 映射到 16-256 色
 	numColors := 16 + (quality * 240 / 100)
@@ -240,6 +240,9 @@ func quantizePNG(img image.Image, quality int, dither bool) *image.Paletted {
 	hasTransparency := false
 	transparentPixels := make(map[int]bool) // 记录透明像素位置
 	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
 		for x := bounds.Min.X; x < bounds.Max.X; x++ {
 			_, _, _, a := img.At(x, y).RGBA()
 			if a < 65535/2 { // alpha < 50% 视为透明
@@ -252,6 +255,9 @@ func quantizePNG(img image.Image, quality int, dither bool) *image.Paletted {
 
 	// 使用 Median Cut 生成调色板
 	palette := medianCutQuantize(img, numColors)
+	if err := checkCanceled(ctx); err != nil {
+		return nil, err
+	}
 
 	// 如果有透明像素，确保调色板包含透明色
 	if hasTransparency {
@@ -285,6 +291,9 @@ func quantizePNG(img image.Image, quality int, dither bool) *image.Paletted {
 		// 直接映射（更快，但可能有色带）
 		draw.Draw(palettedImg, bounds, img, image.Point{}, draw.Src)
 	}
+	if err := checkCanceled(ctx); err != nil {
+		return nil, err
+	}
 
 	// 恢复透明像素
 	if hasTransparency {
@@ -299,42 +308,48 @@ func quantizePNG(img image.Image, quality int, dither bool) *image.Paletted {
 		}
 	}
 
-	return palettedImg
+	return palettedImg, nil
 }
 
-// compressPNGLikeTinyPNG 使用类似 TinyPNG 的方式压缩 PNG
+// compressPNGLikeTinyPNG 使用类似 TinyPNG 的方式压缩 PNG，effort 为 PNG 无损优化级别
 // 返回压缩后的字节和是否使用了量化
-func compressPNGLikeTinyPNG(img image.Image, quality int) ([]byte, bool) {
+func compressPNGLikeTinyPNG(ctx context.Context, img image.Image, quality int, effort int) ([]byte, bool, error) {
 	// 检查原图是否已经是低色图像
 	uniqueColors := countUniqueColors(img, 1000) // 采样检测
 
-	var buf bytes.Buffer
-
 	// 如果颜色数量很少（<= 256），可能已经是索引色图像
-	// 或者质量设置很高，优先保持质量
-	if uniqueColors <= 256 && quality >= 95 {
+	// 或者质量设置很高，优先保持质量；开启无损优化且质量为 100 时不量化
+	if (uniqueColors <= 256 && quality >= 95) || (effort > PNGEffortOff && quality >= 100) {
 		// 使用无损压缩
-		encoder := png.Encoder{CompressionLevel: png.BestCompression}
-		encoder.Encode(&buf, img)
-		return buf.Bytes(), false
+		data, err := encodePNG(ctx, img, effort)
+		if err != nil {
+			return nil, false, err
+		}
+		return data, false, nil
 	}
 
 	// 使用量化压缩
-	palettedImg := quantizePNG(img, quality, true)
+	palettedImg, err := quantizePNG(ctx, img, quality, true)
+	if err != nil {
+		return nil, false, err
+	}
 
-	encoder := png.Encoder{CompressionLevel: png.BestCompression}
-	encoder.Encode(&buf, palettedImg)
+	data, err := encodePNG(ctx, palettedImg, effort)
+	if err != nil {
+		return nil, false, err
+	}
 
-	// 如果量化后反而更大（极少数情况），回退到原始压缩
-	var origBuf bytes.Buffer
-	origEncoder := png.Encoder{CompressionLevel: png.BestCompression}
-	origEncoder.Encode(&origBuf, img)
+	// 如果量化后反而更大（极少数情况），回退到原始压缩（只用于比较，不做最慢的 deflate）
+	origData, err := encodePNG(ctx, img, min(effort, PNGEffortThorough))
+	if err != nil {
+		return nil, false, err
+	}
 
-	if buf.Len() > origBuf.Len() {
-		return origBuf.Bytes(), false
+	if len(data) > len(origData) {
+		return origData, false, nil
 	}
 
-	return buf.Bytes(), true
+	return data, true, nil
 }
 
 // countUniqueColors 计算图像中的唯一颜色数量（采样）
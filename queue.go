@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 任务类型
+const (
+	JobKindCompress    = "compress"
+	JobKindDirectory   = "directory"
+	JobKindGifSequence = "gif_sequence"
+	JobKindGifCompress = "gif_compress"
+	JobKindResponsive  = "responsive"
+)
+
+// 任务状态
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+const (
+	defaultJobConcurrency = 2   // 默认同时执行的任务数
+	maxJobConcurrency     = 16  // 同时执行的任务数上限
+	maxFinishedJobs       = 100 // 保留的已结束任务数，更早的任务不再保存
+)
+
+// jobQueue 后台任务队列：按优先级调度，限制并发数，状态保存到 jobs.json
+// 应用退出时未完成的任务在下次启动时重新执行
+type jobQueue struct {
+	app *App
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	order    []string // 提交顺序
+	cancels  map[string]context.CancelFunc
+	running  int
+	limit    int
+	seq      int
+	started  bool // 应用启动（恢复任务）之后才开始执行
+	stopping bool // 应用退出中，被中止的任务保持未完成状态
+}
+
+// jobsFile 任务队列的保存格式
+type jobsFile struct {
+	Version int    `json:"version"`
+	Jobs    []*Job `json:"jobs"`
+}
+
+func newJobQueue(a *App) *jobQueue {
+	return &jobQueue{
+		app:     a,
+		jobs:    make(map[string]*Job),
+		cancels: make(map[string]context.CancelFunc),
+		limit:   defaultJobConcurrency,
+	}
+}
+
+// jobsPath 返回任务队列文件路径
+func jobsPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jobs.json"), nil
+}
+
+// start 恢复上次未完成的任务并开始执行
+func (q *jobQueue) start() {
+	var file jobsFile
+	if path, err := jobsPath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			if err := json.Unmarshal(data, &file); err != nil {
+				logger.Warn("load jobs failed", "path", path, "error", err)
+			}
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if n := loadSettings().JobConcurrency; n > 0 {
+		q.limit = n
+	}
+	restored := make([]string, 0, len(file.Jobs))
+	for _, job := range file.Jobs {
+		if job == nil || job.ID == "" || q.jobs[job.ID] != nil {
+			continue
+		}
+		if job.Status == JobRunning {
+			// 上次退出时正在执行，从头重新执行
+			job.Status = JobQueued
+			job.Progress = 0
+			job.StartedAt = ""
+			job.Message = tr("job.resumed")
+		}
+		if job.Status == JobQueued {
+			logger.Info("job restored", "job", job.ID, "kind", job.Kind)
+		}
+		q.jobs[job.ID] = job
+		restored = append(restored, job.ID)
+	}
+	q.order = append(restored, q.order...)
+	q.started = true
+	q.dispatchLocked()
+}
+
+// shutdown 应用退出时中止正在执行的任务，它们在 jobs.json 中仍为执行中，下次启动时重新执行
+func (q *jobQueue) shutdown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopping = true
+	for _, cancel := range q.cancels {
+		cancel()
+	}
+}
+
+// submit 校验请求并加入队列，返回任务 ID
+func (q *jobQueue) submit(req JobRequest) (string, error) {
+	if err := validateJobRequest(req); err != nil {
+		return "", err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	job := &Job{
+		ID:        "job-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.Itoa(q.seq),
+		Kind:      req.Kind,
+		Status:    JobQueued,
+		Priority:  req.Priority,
+		Message:   tr("job.queued"),
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Request:   req,
+	}
+	q.jobs[job.ID] = job
+	q.order = append(q.order, job.ID)
+	logger.Info("job submitted", "job", job.ID, "kind", job.Kind, "priority", job.Priority)
+
+	q.changedLocked(job, true)
+	q.dispatchLocked()
+	return job.ID, nil
+}
+
+// validateJobRequest 检查任务类型与路径、选项是否匹配
+func validateJobRequest(req JobRequest) error {
+	n := len(req.Paths)
+	var ok bool
+	switch req.Kind {
+	case JobKindCompress:
+		ok = n > 0 && req.Compress != nil
+	case JobKindDirectory:
+		ok = n == 1 && req.Directory != nil
+	case JobKindGifSequence:
+		ok = n >= 2 && req.Gif != nil
+	case JobKindGifCompress:
+		ok = n == 1 && req.GifCompress != nil
+	case JobKindResponsive:
+		ok = n == 1 && req.Responsive != nil
+	default:
+		return newError(ErrInvalidOptions, nil, "job.unknown_kind", req.Kind)
+	}
+	if !ok {
+		return newError(ErrInvalidOptions, nil, "job.invalid_request", req.Kind)
+	}
+	return nil
+}
+
+// dispatchLocked 在并发数允许时启动优先级最高的排队任务
+func (q *jobQueue) dispatchLocked() {
+	for q.started && !q.stopping && q.running < q.limit {
+		job := q.nextLocked()
+		if job == nil {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		q.cancels[job.ID] = cancel
+		q.running++
+		job.Status = JobRunning
+		job.StartedAt = time.Now().Format(time.RFC3339)
+		job.Message = tr("job.running")
+		q.changedLocked(job, true)
+
+		go q.run(ctx, job.ID, job.Request)
+	}
+}
+
+// nextLocked 返回优先级最高的排队任务，优先级相同时先提交的优先
+func (q *jobQueue) nextLocked() *Job {
+	var next *Job
+	for _, id := range q.order {
+		job := q.jobs[id]
+		if job.Status != JobQueued {
+			continue
+		}
+		if next == nil || job.Priority > next.Priority {
+			next = job
+		}
+	}
+	return next
+}
+
+// run 执行任务并记录结果
+func (q *jobQueue) run(ctx context.Context, id string, req JobRequest) {
+	start := time.Now()
+	ctx = withProgress(ctx, func(progress int, message string) {
+		q.setProgress(id, progress, message)
+	})
+
+	result := Job{Kind: req.Kind}
+	q.app.executeJob(ctx, req, &result)
+	logger.Info("job finished", "job", id, "kind", req.Kind, "status", result.Status, "code", result.Code, since(start))
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.cancels[id]()
+	delete(q.cancels, id)
+	q.running--
+	if q.stopping {
+		return
+	}
+
+	job := q.jobs[id]
+	job.Status = result.Status
+	job.Code = result.Code
+	job.Message = result.Message
+	job.FinishedAt = time.Now().Format(time.RFC3339)
+	job.Compress = result.Compress
+	job.Directory = result.Directory
+	job.Gif = result.Gif
+	job.Responsive = result.Responsive
+	if job.Status == JobDone {
+		job.Progress = 100
+	}
+	q.pruneLocked()
+	q.changedLocked(job, true)
+	q.dispatchLocked()
+}
+
+// setProgress 更新任务进度（只通知前端，不写入文件）
+func (q *jobQueue) setProgress(id string, progress int, message string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok || job.Status != JobRunning {
+		return
+	}
+	job.Progress = progress
+	job.Message = message
+	q.changedLocked(job, false)
+}
+
+// cancel 取消任务：排队中的任务直接标记为已取消，执行中的任务在下一个检查点停止
+func (q *jobQueue) cancel(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return false
+	}
+	switch job.Status {
+	case JobQueued:
+		job.Status = JobCanceled
+		job.Code = CodeCanceled
+		job.Message = tr("job.canceled")
+		job.FinishedAt = time.Now().Format(time.RFC3339)
+		q.changedLocked(job, true)
+		return true
+	case JobRunning:
+		q.cancels[id]()
+		return true
+	}
+	return false
+}
+
+// setLimit 修改同时执行的任务数
+func (q *jobQueue) setLimit(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = n
+	q.dispatchLocked()
+}
+
+// pruneLocked 只保留最近的已结束任务
+func (q *jobQueue) pruneLocked() {
+	finished := 0
+	for i := len(q.order) - 1; i >= 0; i-- {
+		if s := q.jobs[q.order[i]].Status; s == JobQueued || s == JobRunning {
+			continue
+		}
+		finished++
+		if finished > maxFinishedJobs {
+			delete(q.jobs, q.order[i])
+			q.order = append(q.order[:i], q.order[i+1:]...)
+		}
+	}
+}
+
+// changedLocked 通知前端任务变化，persist 为 true 时同时保存队列
+func (q *jobQueue) changedLocked(job *Job, persist bool) {
+	q.app.emit("job-updated", *job)
+	if !persist {
+		return
+	}
+	if err := q.saveLocked(); err != nil {
+		logger.Warn("save jobs failed", "error", err)
+	}
+}
+
+// saveLocked 保存任务队列
+func (q *jobQueue) saveLocked() error {
+	path, err := jobsPath()
+	if err != nil {
+		return err
+	}
+	file := jobsFile{Version: 1, Jobs: make([]*Job, 0, len(q.order))}
+	for _, id := range q.order {
+		file.Jobs = append(file.Jobs, q.jobs[id])
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// executeJob 按任务类型执行，并把结果、状态和消息写入 job
+func (a *App) executeJob(ctx context.Context, req JobRequest, job *Job) {
+	var success bool
+	switch req.Kind {
+	case JobKindCompress:
+		failed := 0
+		for i, path := range req.Paths {
+			reportProgress(ctx, i*100/len(req.Paths), filepath.Base(path))
+			r := a.compressFile(ctx, path, *req.Compress, false)
+			if !r.Success {
+				failed++
+			}
+			job.Compress = append(job.Compress, r)
+		}
+		success = failed == 0
+		job.Message = tr("job.compress_summary", len(req.Paths), len(req.Paths)-failed, failed)
+	case JobKindDirectory:
+		r := a.compressDirectory(ctx, req.Paths[0], *req.Directory)
+		job.Directory = &r
+		success, job.Message = r.Success, r.Message
+	case JobKindGifSequence:
+		r := a.runGifSequence(ctx, req.Paths, *req.Gif)
+		job.Gif = &r
+		success, job.Code, job.Message = r.Success, r.Code, r.Message
+	case JobKindGifCompress:
+		r := a.runGifCompress(ctx, req.Paths[0], *req.GifCompress)
+		job.Gif = &r
+		success, job.Code, job.Message = r.Success, r.Code, r.Message
+	case JobKindResponsive:
+		r := generateResponsiveSet(ctx, req.Paths[0], *req.Responsive)
+		job.Responsive = &r
+		success, job.Code, job.Message = r.Success, r.Code, r.Message
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		job.Status, job.Code, job.Message = JobCanceled, CodeCanceled, tr("job.canceled")
+	case success:
+		job.Status = JobDone
+	default:
+		job.Status = JobFailed
+	}
+}
+
+// SubmitJob 提交后台任务，立即返回任务 ID
+// 任务状态通过 GetJob/ListJobs 查询，变化时发送 "job-updated" 事件
+func (a *App) SubmitJob(req JobRequest) JobSubmitResult {
+	id, err := a.queue.submit(req)
+	if err != nil {
+		return JobSubmitResult{Success: false, Code: errorCode(err), Message: err.Error()}
+	}
+	return JobSubmitResult{Success: true, Message: tr("job.queued"), JobID: id}
+}
+
+// GetJob 返回任务状态，任务不存在时返回 null
+func (a *App) GetJob(id string) *Job {
+	a.queue.mu.Lock()
+	defer a.queue.mu.Unlock()
+	job, ok := a.queue.jobs[id]
+	if !ok {
+		return nil
+	}
+	copied := *job
+	return &copied
+}
+
+// ListJobs 按提交顺序返回所有任务（包括最近结束的任务）
+func (a *App) ListJobs() []Job {
+	a.queue.mu.Lock()
+	defer a.queue.mu.Unlock()
+	jobs := make([]Job, 0, len(a.queue.order))
+	for _, id := range a.queue.order {
+		jobs = append(jobs, *a.queue.jobs[id])
+	}
+	return jobs
+}
+
+// SetJobConcurrency 设置同时执行的任务数（1-16）并保存
+func (a *App) SetJobConcurrency(n int) ActionResult {
+	if n < 1 || n > maxJobConcurrency {
+		return ActionResult{Success: false, Message: tr("job.invalid_concurrency", maxJobConcurrency)}
+	}
+	a.queue.setLimit(n)
+	if err := updateSettings(func(s *appSettings) { s.JobConcurrency = n }); err != nil {
+		return ActionResult{Success: false, Message: tr("settings.save_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("job.concurrency_changed", n)}
+}
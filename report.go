@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 报告中单个文件的状态
+const (
+	ReportStatusOK       = "ok"
+	ReportStatusSkipped  = "skipped"
+	ReportStatusFailed   = "failed"
+	ReportStatusCanceled = "canceled"
+)
+
+// newBatchReport 由一批压缩结果生成报告（不包含预览图）
+func newBatchReport(results []CompressResult) BatchReport {
+	report := BatchReport{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Files:       make([]ReportEntry, 0, len(results)),
+	}
+	for _, r := range results {
+		entry := ReportEntry{
+			InputPath:      filepath.ToSlash(r.InputPath),
+			OutputPath:     filepath.ToSlash(r.OutputPath),
+			Status:         ReportStatusOK,
+			InputFormat:    r.InputFormat,
+			OutputFormat:   r.OutputFormat,
+			Quality:        r.Quality,
+			OriginalSize:   r.OriginalSize,
+			NewSize:        r.NewSize,
+			OriginalWidth:  r.OriginalWidth,
+			OriginalHeight: r.OriginalHeight,
+			NewWidth:       r.NewWidth,
+			NewHeight:      r.NewHeight,
+			Ratio:          r.CompressionRatio,
+			DurationMs:     r.DurationMs,
+			Warnings:       r.Warnings,
+		}
+		if entry.Warnings == nil {
+			entry.Warnings = []string{}
+		}
+
+		t := &report.Totals
+		t.Files++
+		t.DurationMs += r.DurationMs
+		switch {
+		case r.Code == CodeCanceled:
+			entry.Status = ReportStatusCanceled
+			entry.Code = r.Code
+			t.Canceled++
+		case !r.Success:
+			entry.Status = ReportStatusFailed
+			entry.Code = r.Code
+			entry.Error = r.Message
+			t.Failed++
+		case r.Skipped:
+			entry.Status = ReportStatusSkipped
+			entry.Warnings = append(entry.Warnings, r.Message)
+			t.Skipped++
+		default:
+			t.Succeeded++
+			t.OriginalSize += r.OriginalSize
+			t.NewSize += r.NewSize
+		}
+		t.Warnings += len(entry.Warnings)
+		report.Files = append(report.Files, entry)
+	}
+
+	if report.Totals.OriginalSize > 0 {
+		t := &report.Totals
+		t.SavedBytes = t.OriginalSize - t.NewSize
+		t.Ratio = float64(t.SavedBytes) / float64(t.OriginalSize) * 100
+	}
+	return report
+}
+
+// directoryResults 取出文件夹压缩结果中的各文件结果
+func directoryResults(result DirectoryResult) []CompressResult {
+	results := make([]CompressResult, 0, len(result.Files))
+	for _, f := range result.Files {
+		results = append(results, f.Result)
+	}
+	return results
+}
+
+// writeBatchReport 按扩展名写入 JSON（.json）或 CSV（.csv）报告
+func writeBatchReport(path string, report BatchReport) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err = json.MarshalIndent(report, "", "  ")
+		data = append(data, '\n')
+	case ".csv":
+		data, err = reportCSV(report)
+	default:
+		return newError(ErrInvalidOptions, nil, "report.unsupported_format", path)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// reportCSV 生成 CSV 报告，最后一行为合计
+func reportCSV(report BatchReport) ([]byte, error) {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Write([]string{"input_path", "output_path", "status", "input_format", "output_format", "quality",
+		"original_size", "new_size", "original_width", "original_height", "new_width", "new_height",
+		"ratio", "duration_ms", "warnings", "code", "error"})
+
+	for _, e := range report.Files {
+		w.Write([]string{
+			e.InputPath, e.OutputPath, e.Status, e.InputFormat, e.OutputFormat,
+			strconv.Itoa(e.Quality),
+			strconv.FormatInt(e.OriginalSize, 10),
+			strconv.FormatInt(e.NewSize, 10),
+			strconv.Itoa(e.OriginalWidth),
+			strconv.Itoa(e.OriginalHeight),
+			strconv.Itoa(e.NewWidth),
+			strconv.Itoa(e.NewHeight),
+			strconv.FormatFloat(e.Ratio, 'f', 2, 64),
+			strconv.FormatInt(e.DurationMs, 10),
+			strings.Join(e.Warnings, "; "),
+			e.Code,
+			e.Error,
+		})
+	}
+
+	t := report.Totals
+	w.Write([]string{"TOTAL", "", fmt.Sprintf("%d ok / %d skipped / %d failed / %d canceled", t.Succeeded, t.Skipped, t.Failed, t.Canceled), "", "", "",
+		strconv.FormatInt(t.OriginalSize, 10),
+		strconv.FormatInt(t.NewSize, 10),
+		"", "", "", "",
+		strconv.FormatFloat(t.Ratio, 'f', 2, 64),
+		strconv.FormatInt(t.DurationMs, 10),
+		strconv.Itoa(t.Warnings),
+		"", ""})
+
+	w.Flush()
+	return []byte(sb.String()), w.Error()
+}
+
+// ExportBatchReport 将界面中一批压缩结果导出为 JSON 或 CSV 报告（按扩展名决定格式）
+func (a *App) ExportBatchReport(path string, results []CompressResult) ActionResult {
+	report := newBatchReport(results)
+	if err := writeBatchReport(path, report); err != nil {
+		return ActionResult{Success: false, Message: tr("report.export_failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("report.exported", len(report.Files), path)}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nfnt/resize"
+)
+
+// 默认的响应式输出宽度和格式
+var (
+	defaultResponsiveWidths  = []uint{320, 640, 1024, 1600, 2400}
+	defaultResponsiveFormats = []string{"avif", "webp", "jpeg"}
+)
+
+// GenerateResponsiveSet 从一张源图生成多宽度、多格式的响应式图片集
+// 源图只解码一次，每个宽度只缩放一次，再分别编码为各个格式
+func (a *App) GenerateResponsiveSet(inputPath string, spec ResponsiveSpec) ResponsiveResult {
+	ctx, done := a.beginJob(spec.JobID)
+	defer done()
+
+	return generateResponsiveSet(ctx, inputPath, spec)
+}
+
+// generateResponsiveSet 生成响应式图片集，每个变体编码前检查 ctx
+// 取消时删除本次已写入的变体，不完整的图片集没有用处
+func generateResponsiveSet(ctx context.Context, inputPath string, spec ResponsiveSpec) ResponsiveResult {
+	originalData, err := os.ReadFile(inputPath)
+	if err != nil {
+		return responsiveFailResult(newError(ErrRead, err, "err.open_file"))
+	}
+
+	img, _, err := decodeImage(originalData, inputPath)
+	if err != nil {
+		return responsiveFailResult(err)
+	}
+
+	bounds := img.Bounds()
+	originalWidth := bounds.Dx()
+	originalHeight := bounds.Dy()
+
+	widths := responsiveWidths(spec.Widths, uint(originalWidth), spec.AllowUpscale)
+
+	formats := spec.Formats
+	if len(formats) == 0 {
+		formats = defaultResponsiveFormats
+	}
+	var skipped []string
+	var usable []string
+	for _, f := range formats {
+		f = strings.ToLower(f)
+		if !responsiveFormatSupported(f) {
+			skipped = append(skipped, f)
+			continue
+		}
+		usable = append(usable, f)
+	}
+	if len(usable) == 0 {
+		return responsiveFailResult(newError(ErrUnsupportedFormat, nil, "responsive.no_formats", strings.Join(skipped, ", ")))
+	}
+
+	quality := spec.Quality
+	if quality <= 0 || quality > 100 {
+		quality = 80
+	}
+
+	outputDir := spec.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Dir(inputPath)
+	}
+
+	nameTemplate := spec.NameTemplate
+	if nameTemplate == "" {
+		nameTemplate = "{name}-{w}.{ext}"
+	}
+	baseName := filepath.Base(inputPath)
+	nameWithoutExt := strings.TrimSuffix(baseName, filepath.Ext(baseName))
+
+	result := ResponsiveResult{
+		OriginalSize:   int64(len(originalData)),
+		OriginalWidth:  originalWidth,
+		OriginalHeight: originalHeight,
+	}
+
+	// 取消或出错时删除已写入的变体，不留下不完整的图片集
+	var written []string
+	fail := func(err error) ResponsiveResult {
+		for _, path := range written {
+			os.Remove(path)
+		}
+		return responsiveFailResult(err)
+	}
+
+	for i, w := range widths {
+		if err := checkCanceled(ctx); err != nil {
+			return fail(err)
+		}
+		reportProgress(ctx, i*100/len(widths), tr("responsive.progress", w))
+
+		// 每个宽度只缩放一次，各格式共用
+		resized := img
+		if int(w) != originalWidth {
+			resized = resize.Resize(w, 0, img, resize.Lanczos3)
+		}
+		rb := resized.Bounds()
+
+		for _, f := range usable {
+			if err := checkCanceled(ctx); err != nil {
+				return fail(err)
+			}
+			data, mimeType, err := encodeImage(ctx, resized, f, quality)
+			if errors.Is(err, ErrCanceled) {
+				return fail(err)
+			}
+			if err != nil {
+				return fail(newError(ErrEncode, err, "responsive.encode_failed", f, w))
+			}
+
+			fileName := renderNameTemplate(nameTemplate, nameVars{
+				Name:    nameWithoutExt,
+				Ext:     strings.TrimPrefix(outputExtension(f, "."+f), "."),
+				Width:   rb.Dx(),
+				Height:  rb.Dy(),
+				Quality: quality,
+				Data:    data,
+			})
+			outputPath, skip, err := resolveOutputPath(filepath.Join(outputDir, fileName), inputPath, spec.Collision)
+			if err != nil {
+				return fail(err)
+			}
+			if rel, err := filepath.Rel(outputDir, outputPath); err == nil {
+				fileName = rel
+			}
+
+			size := int64(len(data))
+			if skip {
+				// 已存在的变体不重写，但仍列入清单
+				if stat, err := os.Stat(outputPath); err == nil {
+					size = stat.Size()
+				}
+			} else {
+				if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+					return fail(newError(ErrWrite, err, "err.create_output_dir"))
+				}
+				if err := writeFileAtomic(outputPath, data, 0644); err != nil {
+					return fail(newError(ErrWrite, err, "err.save"))
+				}
+				written = append(written, outputPath)
+			}
+
+			result.Variants = append(result.Variants, ResponsiveVariant{
+				Format:   f,
+				MimeType: mimeType,
+				Path:     outputPath,
+				URL:      spec.URLPrefix + filepath.ToSlash(fileName),
+				Width:    rb.Dx(),
+				Height:   rb.Dy(),
+				Size:     size,
+			})
+			result.TotalSize += size
+		}
+	}
+
+	result.Success = true
+	result.HTML = buildPictureHTML(result.Variants, usable, spec, originalWidth, originalHeight)
+	result.Message = tr("responsive.generated",
+		len(result.Variants), len(widths), len(usable), formatFileSize(result.TotalSize))
+	if len(skipped) > 0 {
+		result.Message += tr("responsive.skipped_formats", strings.Join(skipped, ", "))
+	}
+	return result
+}
+
+// responsiveWidths 整理输出宽度：去重、升序，默认不放大超过原图宽度的尺寸
+func responsiveWidths(requested []uint, originalWidth uint, allowUpscale bool) []uint {
+	if len(requested) == 0 {
+		requested = defaultResponsiveWidths
+	}
+
+	seen := make(map[uint]bool)
+	var widths []uint
+	clamped := false
+	for _, w := range requested {
+		if w == 0 {
+			continue
+		}
+		if w > originalWidth && !allowUpscale {
+			clamped = true
+			continue
+		}
+		if !seen[w] {
+			seen[w] = true
+			widths = append(widths, w)
+		}
+	}
+
+	// 有宽度超过原图时，用原图宽度作为最大的一档
+	if clamped && !seen[originalWidth] {
+		widths = append(widths, originalWidth)
+	}
+
+	sort.Slice(widths, func(i, j int) bool { return widths[i] < widths[j] })
+	return widths
+}
+
+// responsiveFormatSupported 判断格式是否可用于响应式输出
+func responsiveFormatSupported(format string) bool {
+	switch format {
+	case "jpeg", "jpg", "png":
+		return true
+	case "webp":
+		return webpSupported()
+	case "avif":
+		return avifSupported()
+	}
+	return false
+}
+
+// buildPictureHTML 生成 <picture> 片段：现代格式作为 <source>，JPEG/PNG 作为 <img> 回退
+func buildPictureHTML(variants []ResponsiveVariant, formats []string, spec ResponsiveSpec, width, height int) string {
+	sizes := spec.Sizes
+	if sizes == "" {
+		sizes = "100vw"
+	}
+
+	// 选择回退格式：优先 JPEG/PNG，否则使用最后一种格式
+	fallback := formats[len(formats)-1]
+	for _, f := range formats {
+		if f == "jpeg" || f == "jpg" || f == "png" {
+			fallback = f
+			break
+		}
+	}
+
+	byFormat := make(map[string][]ResponsiveVariant)
+	for _, v := range variants {
+		byFormat[v.Format] = append(byFormat[v.Format], v)
+	}
+
+	srcset := func(list []ResponsiveVariant) string {
+		parts := make([]string, 0, len(list))
+		for _, v := range list {
+			parts = append(parts, fmt.Sprintf("%s %dw", v.URL, v.Width))
+		}
+		return html.EscapeString(strings.Join(parts, ", "))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<picture>\n")
+	for _, f := range formats {
+		if f == fallback {
+			continue
+		}
+		list := byFormat[f]
+		if len(list) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  <source type=\"%s\" srcset=\"%s\" sizes=\"%s\">\n",
+			list[0].MimeType, srcset(list), html.EscapeString(sizes))
+	}
+
+	list := byFormat[fallback]
+	if len(list) > 0 {
+		// src 使用不小于 1024 的最小一档，否则使用最大一档
+		src := list[len(list)-1]
+		for _, v := range list {
+			if v.Width >= 1024 {
+				src = v
+				break
+			}
+		}
+		// width/height 按最大一档的宽高比给出，避免布局抖动
+		largest := list[len(list)-1]
+		fmt.Fprintf(&sb, "  <img src=\"%s\" srcset=\"%s\" sizes=\"%s\" width=\"%d\" height=\"%d\" alt=\"%s\" loading=\"lazy\" decoding=\"async\">\n",
+			html.EscapeString(src.URL), srcset(list), html.EscapeString(sizes), largest.Width, largest.Height, html.EscapeString(spec.Alt))
+	} else {
+		fmt.Fprintf(&sb, "  <img width=\"%d\" height=\"%d\" alt=\"%s\">\n", width, height, html.EscapeString(spec.Alt))
+	}
+	sb.WriteString("</picture>")
+
+	return sb.String()
+}
@@ -0,0 +1,467 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiServer 本地 HTTP API，供局域网内的工具调用压缩功能
+// 结果直接在响应中返回，不在服务端写入文件
+type apiServer struct {
+	app     *App
+	maxBody int64         // 请求体大小上限（字节）
+	slots   chan struct{} // 同时处理的请求数
+	mux     *http.ServeMux
+	tinify  *tinifyAPI // TinyPNG 兼容接口
+}
+
+// newAPIServer 创建 HTTP API，concurrency 为同时处理的请求数
+func newAPIServer(app *App, maxBody int64, concurrency int) *apiServer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	s := &apiServer{
+		app:     app,
+		maxBody: maxBody,
+		slots:   make(chan struct{}, concurrency),
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /compress", s.handleCompress)
+	s.mux.HandleFunc("POST /gif/compress", s.handleGifCompress)
+	s.mux.HandleFunc("POST /gif/sequence", s.handleGifSequence)
+	s.mux.HandleFunc("GET /formats", s.handleFormats)
+	s.tinify = newTinifyAPI(s)
+	return s
+}
+
+// statusRecorder 记录响应状态码和大小，用于访问日志
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (s *apiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w}
+	s.mux.ServeHTTP(rec, r)
+	logger.Info("http", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr,
+		"status", rec.status, "size", rec.size, since(start))
+}
+
+// acquire 等待处理槽位，客户端断开时返回错误
+func (s *apiServer) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return checkCanceled(ctx)
+	}
+}
+
+func (s *apiServer) release() { <-s.slots }
+
+// handleCompress 压缩一张图片
+// 图片为 multipart 的 file 字段或原始请求体，选项为 JSON（options 字段或参数）或与 CompressOptions 同名的参数
+func (s *apiServer) handleCompress(w http.ResponseWriter, r *http.Request) {
+	uploads, err := s.readUploads(w, r)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	options := CompressOptions{Quality: 80, OutputFormat: "original", KeepAspect: true}
+	if err := parseRequestOptions(r, &options); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	if options.Quality < 1 || options.Quality > 100 {
+		writeAPIError(w, newError(ErrInvalidOptions, nil, "server.invalid_quality", options.Quality))
+		return
+	}
+	// "best" 未指定可接受的格式时按 Accept 头协商
+	if options.OutputFormat == "best" {
+		if len(options.AcceptFormats) == 0 {
+			options.AcceptFormats = parseAcceptHeader(r.Header.Get("Accept"))
+		}
+		w.Header().Add("Vary", "Accept")
+	}
+
+	if err := s.acquire(r.Context()); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	defer s.release()
+
+	in := uploads[0]
+	enc, cached, err := compressBytes(r.Context(), in.data, in.name, options)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	h := w.Header()
+	h.Set("X-Input-Format", enc.InputFormat)
+	h.Set("X-Output-Format", enc.Format)
+	h.Set("X-Original-Width", strconv.Itoa(enc.OriginalWidth))
+	h.Set("X-Original-Height", strconv.Itoa(enc.OriginalHeight))
+	h.Set("X-Width", strconv.Itoa(enc.NewWidth))
+	h.Set("X-Height", strconv.Itoa(enc.NewHeight))
+	h.Set("X-Cache", map[bool]string{true: "HIT", false: "MISS"}[cached])
+	if len(enc.Candidates) > 0 {
+		sizes := make([]string, len(enc.Candidates))
+		for i, c := range enc.Candidates {
+			sizes[i] = c.Format + "=" + strconv.FormatInt(c.Size, 10)
+		}
+		h.Set("X-Format-Candidates", strings.Join(sizes, ", "))
+	}
+	if enc.Decision != nil {
+		h.Set("X-Format-Mode", enc.Decision.Mode)
+	}
+	if enc.Flattened != "" {
+		h.Set("X-Alpha-Flattened", enc.Flattened)
+	}
+	if enc.Lossless {
+		h.Set("X-JPEG-Lossless", "true")
+	}
+	writeImageResponse(w, in, enc.Data, enc.MimeType, outputExtension(enc.Format, filepath.Ext(in.name)))
+}
+
+// handleGifCompress 压缩一个 GIF，选项与 GifCompressOptions 同名
+func (s *apiServer) handleGifCompress(w http.ResponseWriter, r *http.Request) {
+	uploads, err := s.readUploads(w, r)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	var options GifCompressOptions
+	if err := parseRequestOptions(r, &options); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	if err := s.acquire(r.Context()); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	defer s.release()
+
+	in := uploads[0]
+	newGif, encoded, err := s.app.recompressGif(r.Context(), in.data, options)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	h := w.Header()
+	h.Set("X-Frame-Count", strconv.Itoa(len(newGif.Image)))
+	h.Set("X-Width", strconv.Itoa(newGif.Config.Width))
+	h.Set("X-Height", strconv.Itoa(newGif.Config.Height))
+	writeImageResponse(w, in, encoded, "image/gif", ".gif")
+}
+
+// handleGifSequence 由多张序列帧生成 GIF，帧按文件名自然排序，选项与 GifOptions 同名
+func (s *apiServer) handleGifSequence(w http.ResponseWriter, r *http.Request) {
+	if !isMultipart(r) {
+		writeAPIError(w, newError(ErrInvalidOptions, nil, "server.need_multipart"))
+		return
+	}
+	uploads, err := s.readUploads(w, r)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	var options GifOptions
+	if err := parseRequestOptions(r, &options); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	if err := s.acquire(r.Context()); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	defer s.release()
+
+	frames, total, err := decodeFrames(r.Context(), uploads)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	gifImg, encoded, err := encodeGifSequence(r.Context(), frames, options)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	name := options.OutputName
+	if name == "" {
+		name = "animation"
+	}
+	h := w.Header()
+	h.Set("X-Frame-Count", strconv.Itoa(len(gifImg.Image)))
+	h.Set("X-Width", strconv.Itoa(gifImg.Config.Width))
+	h.Set("X-Height", strconv.Itoa(gifImg.Config.Height))
+	writeImageResponse(w, upload{name: name + ".gif", size: total}, encoded, "image/gif", ".gif")
+}
+
+// decodeFrames 按文件名自然排序（与桌面端行为一致）并解码序列帧，返回帧和上传的总字节数
+func decodeFrames(ctx context.Context, uploads []upload) ([]image.Image, int64, error) {
+	// 对下标做稳定排序，同名的上传保持上传顺序，不会互相覆盖
+	order := make([]int, len(uploads))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return naturalLess(filepath.Base(uploads[order[i]].name), filepath.Base(uploads[order[j]].name))
+	})
+	var frames []image.Image
+	var total int64
+	for _, i := range order {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, 0, err
+		}
+		u := uploads[i]
+		img, _, err := decodeImage(u.data, u.name)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", u.name, err)
+		}
+		frames = append(frames, img)
+		total += int64(len(u.data))
+	}
+	return frames, total, nil
+}
+
+// handleFormats 返回支持的输入和输出格式
+func (s *apiServer) handleFormats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.app.GetSupportedFormats())
+}
+
+// upload 请求中上传的一个文件
+type upload struct {
+	name string
+	data []byte
+	size int64
+}
+
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// readUploads 读取上传的文件：multipart 中的所有文件，或原始请求体（文件名取 name 参数）
+func (s *apiServer) readUploads(w http.ResponseWriter, r *http.Request) ([]upload, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+
+	if !isMultipart(r) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, bodyError(err)
+		}
+		if len(data) == 0 {
+			return nil, newError(ErrInvalidOptions, nil, "server.no_file")
+		}
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			name = "image"
+		}
+		return []upload{{name: name, data: data, size: int64(len(data))}}, nil
+	}
+
+	// 按上传顺序逐个读取（MultipartForm.File 是 map，不同字段之间的顺序不确定），
+	// 普通字段保存到 r.MultipartForm.Value，供 parseRequestOptions 读取
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, bodyError(err)
+	}
+	r.MultipartForm = &multipart.Form{Value: make(map[string][]string)}
+
+	var uploads []upload
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, multipartError(r.Body, err, "")
+		}
+		if part.FormName() == "" {
+			part.Close()
+			continue
+		}
+		data, err := io.ReadAll(part)
+		part.Close()
+		switch {
+		case err != nil:
+			return nil, multipartError(r.Body, err, part.FileName())
+		case part.FileName() == "":
+			r.MultipartForm.Value[part.FormName()] = append(r.MultipartForm.Value[part.FormName()], string(data))
+		default:
+			uploads = append(uploads, upload{name: filepath.Base(part.FileName()), data: data, size: int64(len(data))})
+		}
+	}
+	if len(uploads) == 0 {
+		return nil, newError(ErrInvalidOptions, nil, "server.no_file")
+	}
+	return uploads, nil
+}
+
+// multipartError 读取 multipart 的某一部分失败时的错误，fileName 为文件部分的文件名
+// 请求体在部分头中间被截断时 multipart 只报告格式错误，这里再从 body 取出超过大小上限的错误
+func multipartError(body io.Reader, err error, fileName string) error {
+	var maxErr *http.MaxBytesError
+	if _, bodyErr := body.Read(nil); errors.As(bodyErr, &maxErr) {
+		err = bodyErr
+	}
+	if fileName != "" && !errors.As(err, &maxErr) {
+		return newError(ErrRead, err, "server.read_upload", fileName)
+	}
+	return bodyError(err)
+}
+
+// bodyError 将读取请求体的错误归类，超过大小上限时为 too_large
+func bodyError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return newError(ErrTooLarge, nil, "server.body_too_large", formatFileSize(maxErr.Limit))
+	}
+	return newError(ErrRead, err, "server.read_body")
+}
+
+// parseRequestOptions 解析请求中的选项：先读取 JSON 格式的 options 参数，再用同名参数逐项覆盖
+// 参数名与选项结构体的 JSON 字段名相同，如 quality=75&outputFormat=webp
+func parseRequestOptions(r *http.Request, dst interface{}) error {
+	values := url.Values{}
+	for k, v := range r.URL.Query() {
+		values[k] = v
+	}
+	if r.MultipartForm != nil {
+		for k, v := range r.MultipartForm.Value {
+			values[k] = append(values[k], v...)
+		}
+	}
+
+	if raw := values.Get("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), dst); err != nil {
+			return newError(ErrInvalidOptions, err, "server.invalid_options_json")
+		}
+	}
+	return setOptionValues(dst, values)
+}
+
+// setOptionValues 按 JSON 字段名将参数写入选项结构体，未知参数被忽略
+func setOptionValues(dst interface{}, values url.Values) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		raw, ok := values[name]
+		if name == "" || name == "-" || !ok || len(raw) == 0 {
+			continue
+		}
+		field := v.Field(i)
+		var err error
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(raw[0])
+		case reflect.Int:
+			var n int64
+			n, err = strconv.ParseInt(raw[0], 10, 64)
+			field.SetInt(n)
+		case reflect.Uint:
+			var n uint64
+			n, err = strconv.ParseUint(raw[0], 10, 64)
+			field.SetUint(n)
+		case reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(raw[0])
+			field.SetBool(b)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.String {
+				var list []string
+				for _, item := range raw {
+					list = append(list, strings.Split(item, ",")...)
+				}
+				field.Set(reflect.ValueOf(list))
+			}
+		}
+		if err != nil {
+			return newError(ErrInvalidOptions, nil, "server.invalid_option", name, raw[0])
+		}
+	}
+	return nil
+}
+
+// writeImageResponse 返回图片数据，并在响应头中附带大小信息
+func writeImageResponse(w http.ResponseWriter, in upload, data []byte, mimeType, ext string) {
+	name := strings.TrimSuffix(in.name, filepath.Ext(in.name)) + ext
+	ratio := 0.0
+	if in.size > 0 {
+		ratio = float64(in.size-int64(len(data))) / float64(in.size) * 100
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", mimeType)
+	h.Set("Content-Length", strconv.Itoa(len(data)))
+	h.Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", name))
+	h.Set("X-Original-Size", strconv.FormatInt(in.size, 10))
+	h.Set("X-Compressed-Size", strconv.Itoa(len(data)))
+	h.Set("X-Compression-Ratio", strconv.FormatFloat(ratio, 'f', 2, 64))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// apiError 错误响应
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeAPIError 按错误码返回对应的 HTTP 状态码和 JSON 错误
+func writeAPIError(w http.ResponseWriter, err error) {
+	code := errorCode(err)
+	status := http.StatusInternalServerError
+	switch code {
+	case CodeInvalidOptions, CodeDecode, CodeRead:
+		status = http.StatusBadRequest
+	case CodeUnsupportedFormat:
+		status = http.StatusUnsupportedMediaType
+	case CodeTooLarge:
+		status = http.StatusRequestEntityTooLarge
+	case CodeCanceled:
+		status = http.StatusRequestTimeout
+	}
+	writeJSON(w, status, apiError{Code: code, Message: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
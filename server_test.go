@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// 多个文件按上传顺序返回（与字段名无关），普通字段作为选项参数
+func TestReadUploadsOrder(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, f := range []struct{ field, name string }{{"z", "1.png"}, {"file", "2.png"}, {"a", "3.png"}, {"z", "4.png"}, {"file", "5.png"}} {
+		w, err := mw.CreateFormFile(f.field, "dir/"+f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f.name))
+		if f.name == "3.png" {
+			mw.WriteField("quality", "42")
+		}
+	}
+	mw.WriteField("options", `{"outputFormat":"webp"}`)
+	mw.Close()
+
+	s := &apiServer{maxBody: 1 << 20}
+	r := httptest.NewRequest("POST", "/gif/sequence", bytes.NewReader(body.Bytes()))
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	uploads, err := s.readUploads(httptest.NewRecorder(), r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, u := range uploads {
+		if string(u.data) != u.name || u.size != int64(len(u.data)) {
+			t.Errorf("%s: data %q size %d", u.name, u.data, u.size)
+		}
+		names = append(names, u.name)
+	}
+	if got := strings.Join(names, ","); got != "1.png,2.png,3.png,4.png,5.png" {
+		t.Errorf("order %s", got)
+	}
+
+	options := CompressOptions{Quality: 80}
+	if err := parseRequestOptions(r, &options); err != nil {
+		t.Fatal(err)
+	}
+	if options.Quality != 42 || options.OutputFormat != "webp" {
+		t.Errorf("options %+v", options)
+	}
+
+	// 超过请求体大小上限时为 ErrTooLarge，无论截断在部分头、文件内容还是普通字段中
+	for limit := 1; limit < body.Len(); limit += 29 {
+		s.maxBody = int64(limit)
+		r = httptest.NewRequest("POST", "/gif/sequence", bytes.NewReader(body.Bytes()))
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		if _, err := s.readUploads(httptest.NewRecorder(), r); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("limit %d: want ErrTooLarge, got %v", limit, err)
+		}
+	}
+}
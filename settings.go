@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// appSettings 应用设置，保存在应用数据目录的 settings.json 中
+type appSettings struct {
+	Locale   string `json:"locale,omitempty"`   // 界面语言，为空时跟随系统
+	LogLevel string `json:"logLevel,omitempty"` // 日志级别，为空时为 info
+
+	JobConcurrency int `json:"jobConcurrency,omitempty"` // 任务队列同时执行的任务数，为 0 时为 2
+}
+
+// settingsMu 保护设置文件的读写
+var settingsMu sync.Mutex
+
+// settingsPath 返回设置文件路径
+func settingsPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// loadSettings 读取设置，文件不存在或无法解析时返回默认设置
+func loadSettings() appSettings {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	var s appSettings
+	path, err := settingsPath()
+	if err != nil {
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	json.Unmarshal(data, &s)
+	return s
+}
+
+// updateSettings 读取设置、修改后写回
+func updateSettings(update func(s *appSettings)) error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	var s appSettings
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &s)
+	}
+	update(&s)
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0644)
+}
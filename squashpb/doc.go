@@ -0,0 +1,4 @@
+// Package squashpb 是 Squash gRPC 接口（proto/squash/v1/squash.proto）生成的 Go 代码，供调用方创建客户端
+package squashpb
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=module=image-compressor/squashpb --go-grpc_out=. --go-grpc_opt=module=image-compressor/squashpb squash/v1/squash.proto
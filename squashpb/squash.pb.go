@@ -0,0 +1,1264 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: squash/v1/squash.proto
+
+// Squash 压缩引擎的 gRPC 接口，由 `squash serve -grpc-addr` 提供
+// 图片以字节传输，服务端不读写文件；请求的截止时间（deadline）到达时正在进行的操作会被取消
+// 失败时返回 gRPC 状态错误，错误详情中的 google.rpc.ErrorInfo.reason 为稳定的错误码（如 "decode_failed"）
+
+package squashpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CompressOptions 与桌面端的压缩选项相同，只包含影响编码结果的选项
+type CompressOptions struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Quality         int32                  `protobuf:"varint,1,opt,name=quality,proto3" json:"quality,omitempty"`                                        // 压缩质量 1-100，0 表示默认值 80
+	MaxWidth        uint32                 `protobuf:"varint,2,opt,name=max_width,json=maxWidth,proto3" json:"max_width,omitempty"`                      // 最大宽度，0 表示不限制
+	MaxHeight       uint32                 `protobuf:"varint,3,opt,name=max_height,json=maxHeight,proto3" json:"max_height,omitempty"`                   // 最大高度，0 表示不限制
+	OutputFormat    string                 `protobuf:"bytes,4,opt,name=output_format,json=outputFormat,proto3" json:"output_format,omitempty"`           // "original"（默认）, "auto", "best", "jpeg", "png", "webp", "avif"
+	KeepAspect      bool                   `protobuf:"varint,5,opt,name=keep_aspect,json=keepAspect,proto3" json:"keep_aspect,omitempty"`                // 保持宽高比
+	Force           bool                   `protobuf:"varint,6,opt,name=force,proto3" json:"force,omitempty"`                                            // 不使用缓存
+	AcceptFormats   []string               `protobuf:"bytes,7,rep,name=accept_formats,json=acceptFormats,proto3" json:"accept_formats,omitempty"`        // output_format 为 "best" 时可接受的格式或 MIME 类型，为空时接受所有格式
+	Background      string                 `protobuf:"bytes,8,opt,name=background,proto3" json:"background,omitempty"`                                   // 输出格式不支持透明通道时的背景色：#rrggbb、#rgb 或 "auto"，默认白色
+	JpegProgressive bool                   `protobuf:"varint,9,opt,name=jpeg_progressive,json=jpegProgressive,proto3" json:"jpeg_progressive,omitempty"` // 输出渐进式 JPEG
+	JpegSubsampling string                 `protobuf:"bytes,10,opt,name=jpeg_subsampling,json=jpegSubsampling,proto3" json:"jpeg_subsampling,omitempty"` // JPEG 色度抽样："420"（默认）, "422", "444"
+	JpegQuantTable  string                 `protobuf:"bytes,11,opt,name=jpeg_quant_table,json=jpegQuantTable,proto3" json:"jpeg_quant_table,omitempty"`  // JPEG 量化表："standard"（默认）, "mozjpeg"，或 64/128 个逗号分隔的数
+	JpegLossless    bool                   `protobuf:"varint,12,opt,name=jpeg_lossless,json=jpegLossless,proto3" json:"jpeg_lossless,omitempty"`         // JPEG 无损优化：JPEG 输入输出且不缩放时只重写熵编码，不重新编码
+	PngEffort       int32                  `protobuf:"varint,13,opt,name=png_effort,json=pngEffort,proto3" json:"png_effort,omitempty"`                  // PNG 无损优化级别：0 为标准库编码器（默认），1-3 依次更慢、更小
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CompressOptions) Reset() {
+	*x = CompressOptions{}
+	mi := &file_squash_v1_squash_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompressOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressOptions) ProtoMessage() {}
+
+func (x *CompressOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressOptions.ProtoReflect.Descriptor instead.
+func (*CompressOptions) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CompressOptions) GetQuality() int32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *CompressOptions) GetMaxWidth() uint32 {
+	if x != nil {
+		return x.MaxWidth
+	}
+	return 0
+}
+
+func (x *CompressOptions) GetMaxHeight() uint32 {
+	if x != nil {
+		return x.MaxHeight
+	}
+	return 0
+}
+
+func (x *CompressOptions) GetOutputFormat() string {
+	if x != nil {
+		return x.OutputFormat
+	}
+	return ""
+}
+
+func (x *CompressOptions) GetKeepAspect() bool {
+	if x != nil {
+		return x.KeepAspect
+	}
+	return false
+}
+
+func (x *CompressOptions) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *CompressOptions) GetAcceptFormats() []string {
+	if x != nil {
+		return x.AcceptFormats
+	}
+	return nil
+}
+
+func (x *CompressOptions) GetBackground() string {
+	if x != nil {
+		return x.Background
+	}
+	return ""
+}
+
+func (x *CompressOptions) GetJpegProgressive() bool {
+	if x != nil {
+		return x.JpegProgressive
+	}
+	return false
+}
+
+func (x *CompressOptions) GetJpegSubsampling() string {
+	if x != nil {
+		return x.JpegSubsampling
+	}
+	return ""
+}
+
+func (x *CompressOptions) GetJpegQuantTable() string {
+	if x != nil {
+		return x.JpegQuantTable
+	}
+	return ""
+}
+
+func (x *CompressOptions) GetJpegLossless() bool {
+	if x != nil {
+		return x.JpegLossless
+	}
+	return false
+}
+
+func (x *CompressOptions) GetPngEffort() int32 {
+	if x != nil {
+		return x.PngEffort
+	}
+	return 0
+}
+
+type CompressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // 文件名，用于推断格式和生成输出文件名
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Options       *CompressOptions       `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompressRequest) Reset() {
+	*x = CompressRequest{}
+	mi := &file_squash_v1_squash_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressRequest) ProtoMessage() {}
+
+func (x *CompressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressRequest.ProtoReflect.Descriptor instead.
+func (*CompressRequest) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CompressRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CompressRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CompressRequest) GetOptions() *CompressOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type CompressChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`       // 只在第一个分块中读取
+	Options       *CompressOptions       `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"` // 只在第一个分块中读取
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompressChunk) Reset() {
+	*x = CompressChunk{}
+	mi := &file_squash_v1_squash_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompressChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressChunk) ProtoMessage() {}
+
+func (x *CompressChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressChunk.ProtoReflect.Descriptor instead.
+func (*CompressChunk) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CompressChunk) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CompressChunk) GetOptions() *CompressOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *CompressChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// CompressResult 与桌面端的压缩结果对应
+type CompressResult struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	OriginalSize     int64                  `protobuf:"varint,1,opt,name=original_size,json=originalSize,proto3" json:"original_size,omitempty"`
+	NewSize          int64                  `protobuf:"varint,2,opt,name=new_size,json=newSize,proto3" json:"new_size,omitempty"`
+	OriginalWidth    int32                  `protobuf:"varint,3,opt,name=original_width,json=originalWidth,proto3" json:"original_width,omitempty"`
+	OriginalHeight   int32                  `protobuf:"varint,4,opt,name=original_height,json=originalHeight,proto3" json:"original_height,omitempty"`
+	NewWidth         int32                  `protobuf:"varint,5,opt,name=new_width,json=newWidth,proto3" json:"new_width,omitempty"`
+	NewHeight        int32                  `protobuf:"varint,6,opt,name=new_height,json=newHeight,proto3" json:"new_height,omitempty"`
+	CompressionRatio float64                `protobuf:"fixed64,7,opt,name=compression_ratio,json=compressionRatio,proto3" json:"compression_ratio,omitempty"` // 节省的百分比
+	Cached           bool                   `protobuf:"varint,8,opt,name=cached,proto3" json:"cached,omitempty"`
+	InputHash        string                 `protobuf:"bytes,9,opt,name=input_hash,json=inputHash,proto3" json:"input_hash,omitempty"`
+	InputFormat      string                 `protobuf:"bytes,10,opt,name=input_format,json=inputFormat,proto3" json:"input_format,omitempty"`
+	OutputFormat     string                 `protobuf:"bytes,11,opt,name=output_format,json=outputFormat,proto3" json:"output_format,omitempty"`
+	Quality          int32                  `protobuf:"varint,12,opt,name=quality,proto3" json:"quality,omitempty"`
+	DurationMs       int64                  `protobuf:"varint,13,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Warnings         []string               `protobuf:"bytes,14,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	FormatCandidates []*FormatCandidate     `protobuf:"bytes,15,rep,name=format_candidates,json=formatCandidates,proto3" json:"format_candidates,omitempty"` // output_format 为 "best" 时各候选格式的大小
+	FormatDecision   *FormatDecision        `protobuf:"bytes,16,opt,name=format_decision,json=formatDecision,proto3" json:"format_decision,omitempty"`       // output_format 为 "auto" 时的内容分析和选择理由
+	Lossless         bool                   `protobuf:"varint,17,opt,name=lossless,proto3" json:"lossless,omitempty"`                                        // 使用了 JPEG 无损优化，像素与原图相同
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CompressResult) Reset() {
+	*x = CompressResult{}
+	mi := &file_squash_v1_squash_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompressResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressResult) ProtoMessage() {}
+
+func (x *CompressResult) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressResult.ProtoReflect.Descriptor instead.
+func (*CompressResult) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CompressResult) GetOriginalSize() int64 {
+	if x != nil {
+		return x.OriginalSize
+	}
+	return 0
+}
+
+func (x *CompressResult) GetNewSize() int64 {
+	if x != nil {
+		return x.NewSize
+	}
+	return 0
+}
+
+func (x *CompressResult) GetOriginalWidth() int32 {
+	if x != nil {
+		return x.OriginalWidth
+	}
+	return 0
+}
+
+func (x *CompressResult) GetOriginalHeight() int32 {
+	if x != nil {
+		return x.OriginalHeight
+	}
+	return 0
+}
+
+func (x *CompressResult) GetNewWidth() int32 {
+	if x != nil {
+		return x.NewWidth
+	}
+	return 0
+}
+
+func (x *CompressResult) GetNewHeight() int32 {
+	if x != nil {
+		return x.NewHeight
+	}
+	return 0
+}
+
+func (x *CompressResult) GetCompressionRatio() float64 {
+	if x != nil {
+		return x.CompressionRatio
+	}
+	return 0
+}
+
+func (x *CompressResult) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *CompressResult) GetInputHash() string {
+	if x != nil {
+		return x.InputHash
+	}
+	return ""
+}
+
+func (x *CompressResult) GetInputFormat() string {
+	if x != nil {
+		return x.InputFormat
+	}
+	return ""
+}
+
+func (x *CompressResult) GetOutputFormat() string {
+	if x != nil {
+		return x.OutputFormat
+	}
+	return ""
+}
+
+func (x *CompressResult) GetQuality() int32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *CompressResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *CompressResult) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *CompressResult) GetFormatCandidates() []*FormatCandidate {
+	if x != nil {
+		return x.FormatCandidates
+	}
+	return nil
+}
+
+func (x *CompressResult) GetFormatDecision() *FormatDecision {
+	if x != nil {
+		return x.FormatDecision
+	}
+	return nil
+}
+
+func (x *CompressResult) GetLossless() bool {
+	if x != nil {
+		return x.Lossless
+	}
+	return false
+}
+
+type FormatCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FormatCandidate) Reset() {
+	*x = FormatCandidate{}
+	mi := &file_squash_v1_squash_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FormatCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FormatCandidate) ProtoMessage() {}
+
+func (x *FormatCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FormatCandidate.ProtoReflect.Descriptor instead.
+func (*FormatCandidate) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FormatCandidate) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FormatCandidate) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// FormatDecision 与桌面端的 FormatDecision 相同
+type FormatDecision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"` // "png-lossless", "png-quantized", "jpeg", "webp"
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	UniqueColors  int32                  `protobuf:"varint,4,opt,name=unique_colors,json=uniqueColors,proto3" json:"unique_colors,omitempty"`
+	HasAlpha      bool                   `protobuf:"varint,5,opt,name=has_alpha,json=hasAlpha,proto3" json:"has_alpha,omitempty"`
+	FlatRatio     float64                `protobuf:"fixed64,6,opt,name=flat_ratio,json=flatRatio,proto3" json:"flat_ratio,omitempty"`
+	EdgeRatio     float64                `protobuf:"fixed64,7,opt,name=edge_ratio,json=edgeRatio,proto3" json:"edge_ratio,omitempty"`
+	NoiseRatio    float64                `protobuf:"fixed64,8,opt,name=noise_ratio,json=noiseRatio,proto3" json:"noise_ratio,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FormatDecision) Reset() {
+	*x = FormatDecision{}
+	mi := &file_squash_v1_squash_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FormatDecision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FormatDecision) ProtoMessage() {}
+
+func (x *FormatDecision) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FormatDecision.ProtoReflect.Descriptor instead.
+func (*FormatDecision) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FormatDecision) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *FormatDecision) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FormatDecision) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FormatDecision) GetUniqueColors() int32 {
+	if x != nil {
+		return x.UniqueColors
+	}
+	return 0
+}
+
+func (x *FormatDecision) GetHasAlpha() bool {
+	if x != nil {
+		return x.HasAlpha
+	}
+	return false
+}
+
+func (x *FormatDecision) GetFlatRatio() float64 {
+	if x != nil {
+		return x.FlatRatio
+	}
+	return 0
+}
+
+func (x *FormatDecision) GetEdgeRatio() float64 {
+	if x != nil {
+		return x.EdgeRatio
+	}
+	return 0
+}
+
+func (x *FormatDecision) GetNoiseRatio() float64 {
+	if x != nil {
+		return x.NoiseRatio
+	}
+	return 0
+}
+
+type CompressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *CompressResult        `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	MimeType      string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"` // 建议的输出文件名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompressResponse) Reset() {
+	*x = CompressResponse{}
+	mi := &file_squash_v1_squash_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompressResponse) ProtoMessage() {}
+
+func (x *CompressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompressResponse.ProtoReflect.Descriptor instead.
+func (*CompressResponse) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CompressResponse) GetResult() *CompressResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *CompressResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CompressResponse) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *CompressResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// GifOptions 与桌面端的 GIF 生成选项相同
+type GifOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FrameDelay    int32                  `protobuf:"varint,1,opt,name=frame_delay,json=frameDelay,proto3" json:"frame_delay,omitempty"` // 帧延迟（毫秒）
+	LoopCount     int32                  `protobuf:"varint,2,opt,name=loop_count,json=loopCount,proto3" json:"loop_count,omitempty"`    // 循环次数，0 表示无限循环
+	MaxWidth      uint32                 `protobuf:"varint,3,opt,name=max_width,json=maxWidth,proto3" json:"max_width,omitempty"`
+	MaxHeight     uint32                 `protobuf:"varint,4,opt,name=max_height,json=maxHeight,proto3" json:"max_height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GifOptions) Reset() {
+	*x = GifOptions{}
+	mi := &file_squash_v1_squash_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GifOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GifOptions) ProtoMessage() {}
+
+func (x *GifOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GifOptions.ProtoReflect.Descriptor instead.
+func (*GifOptions) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GifOptions) GetFrameDelay() int32 {
+	if x != nil {
+		return x.FrameDelay
+	}
+	return 0
+}
+
+func (x *GifOptions) GetLoopCount() int32 {
+	if x != nil {
+		return x.LoopCount
+	}
+	return 0
+}
+
+func (x *GifOptions) GetMaxWidth() uint32 {
+	if x != nil {
+		return x.MaxWidth
+	}
+	return 0
+}
+
+func (x *GifOptions) GetMaxHeight() uint32 {
+	if x != nil {
+		return x.MaxHeight
+	}
+	return 0
+}
+
+type Frame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	mi := &file_squash_v1_squash_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Frame) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Frame) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type CreateAnimationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Frames        []*Frame               `protobuf:"bytes,1,rep,name=frames,proto3" json:"frames,omitempty"`
+	Options       *GifOptions            `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAnimationRequest) Reset() {
+	*x = CreateAnimationRequest{}
+	mi := &file_squash_v1_squash_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAnimationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAnimationRequest) ProtoMessage() {}
+
+func (x *CreateAnimationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAnimationRequest.ProtoReflect.Descriptor instead.
+func (*CreateAnimationRequest) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateAnimationRequest) GetFrames() []*Frame {
+	if x != nil {
+		return x.Frames
+	}
+	return nil
+}
+
+func (x *CreateAnimationRequest) GetOptions() *GifOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type CreateAnimationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	FileSize      int64                  `protobuf:"varint,2,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	FrameCount    int32                  `protobuf:"varint,3,opt,name=frame_count,json=frameCount,proto3" json:"frame_count,omitempty"`
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAnimationResponse) Reset() {
+	*x = CreateAnimationResponse{}
+	mi := &file_squash_v1_squash_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAnimationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAnimationResponse) ProtoMessage() {}
+
+func (x *CreateAnimationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAnimationResponse.ProtoReflect.Descriptor instead.
+func (*CreateAnimationResponse) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateAnimationResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *CreateAnimationResponse) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *CreateAnimationResponse) GetFrameCount() int32 {
+	if x != nil {
+		return x.FrameCount
+	}
+	return 0
+}
+
+func (x *CreateAnimationResponse) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *CreateAnimationResponse) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type InspectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Preview       bool                   `protobuf:"varint,3,opt,name=preview,proto3" json:"preview,omitempty"` // 是否生成预览缩略图
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectRequest) Reset() {
+	*x = InspectRequest{}
+	mi := &file_squash_v1_squash_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectRequest) ProtoMessage() {}
+
+func (x *InspectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectRequest.ProtoReflect.Descriptor instead.
+func (*InspectRequest) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *InspectRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InspectRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *InspectRequest) GetPreview() bool {
+	if x != nil {
+		return x.Preview
+	}
+	return false
+}
+
+// ImageInfo 与桌面端的图片信息相同
+type ImageInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	Format        string                 `protobuf:"bytes,5,opt,name=format,proto3" json:"format,omitempty"`
+	Preview       string                 `protobuf:"bytes,6,opt,name=preview,proto3" json:"preview,omitempty"` // JPEG 缩略图（data URL）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageInfo) Reset() {
+	*x = ImageInfo{}
+	mi := &file_squash_v1_squash_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageInfo) ProtoMessage() {}
+
+func (x *ImageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_squash_v1_squash_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageInfo.ProtoReflect.Descriptor instead.
+func (*ImageInfo) Descriptor() ([]byte, []int) {
+	return file_squash_v1_squash_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ImageInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ImageInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ImageInfo) GetPreview() string {
+	if x != nil {
+		return x.Preview
+	}
+	return ""
+}
+
+var File_squash_v1_squash_proto protoreflect.FileDescriptor
+
+const file_squash_v1_squash_proto_rawDesc = "" +
+	"\n" +
+	"\x16squash/v1/squash.proto\x12\tsquash.v1\"\xce\x03\n" +
+	"\x0fCompressOptions\x12\x18\n" +
+	"\aquality\x18\x01 \x01(\x05R\aquality\x12\x1b\n" +
+	"\tmax_width\x18\x02 \x01(\rR\bmaxWidth\x12\x1d\n" +
+	"\n" +
+	"max_height\x18\x03 \x01(\rR\tmaxHeight\x12#\n" +
+	"\routput_format\x18\x04 \x01(\tR\foutputFormat\x12\x1f\n" +
+	"\vkeep_aspect\x18\x05 \x01(\bR\n" +
+	"keepAspect\x12\x14\n" +
+	"\x05force\x18\x06 \x01(\bR\x05force\x12%\n" +
+	"\x0eaccept_formats\x18\a \x03(\tR\racceptFormats\x12\x1e\n" +
+	"\n" +
+	"background\x18\b \x01(\tR\n" +
+	"background\x12)\n" +
+	"\x10jpeg_progressive\x18\t \x01(\bR\x0fjpegProgressive\x12)\n" +
+	"\x10jpeg_subsampling\x18\n" +
+	" \x01(\tR\x0fjpegSubsampling\x12(\n" +
+	"\x10jpeg_quant_table\x18\v \x01(\tR\x0ejpegQuantTable\x12#\n" +
+	"\rjpeg_lossless\x18\f \x01(\bR\fjpegLossless\x12\x1d\n" +
+	"\n" +
+	"png_effort\x18\r \x01(\x05R\tpngEffort\"o\n" +
+	"\x0fCompressRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x124\n" +
+	"\aoptions\x18\x03 \x01(\v2\x1a.squash.v1.CompressOptionsR\aoptions\"m\n" +
+	"\rCompressChunk\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x124\n" +
+	"\aoptions\x18\x02 \x01(\v2\x1a.squash.v1.CompressOptionsR\aoptions\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"\x88\x05\n" +
+	"\x0eCompressResult\x12#\n" +
+	"\roriginal_size\x18\x01 \x01(\x03R\foriginalSize\x12\x19\n" +
+	"\bnew_size\x18\x02 \x01(\x03R\anewSize\x12%\n" +
+	"\x0eoriginal_width\x18\x03 \x01(\x05R\roriginalWidth\x12'\n" +
+	"\x0foriginal_height\x18\x04 \x01(\x05R\x0eoriginalHeight\x12\x1b\n" +
+	"\tnew_width\x18\x05 \x01(\x05R\bnewWidth\x12\x1d\n" +
+	"\n" +
+	"new_height\x18\x06 \x01(\x05R\tnewHeight\x12+\n" +
+	"\x11compression_ratio\x18\a \x01(\x01R\x10compressionRatio\x12\x16\n" +
+	"\x06cached\x18\b \x01(\bR\x06cached\x12\x1d\n" +
+	"\n" +
+	"input_hash\x18\t \x01(\tR\tinputHash\x12!\n" +
+	"\finput_format\x18\n" +
+	" \x01(\tR\vinputFormat\x12#\n" +
+	"\routput_format\x18\v \x01(\tR\foutputFormat\x12\x18\n" +
+	"\aquality\x18\f \x01(\x05R\aquality\x12\x1f\n" +
+	"\vduration_ms\x18\r \x01(\x03R\n" +
+	"durationMs\x12\x1a\n" +
+	"\bwarnings\x18\x0e \x03(\tR\bwarnings\x12G\n" +
+	"\x11format_candidates\x18\x0f \x03(\v2\x1a.squash.v1.FormatCandidateR\x10formatCandidates\x12B\n" +
+	"\x0fformat_decision\x18\x10 \x01(\v2\x19.squash.v1.FormatDecisionR\x0eformatDecision\x12\x1a\n" +
+	"\blossless\x18\x11 \x01(\bR\blossless\"=\n" +
+	"\x0fFormatCandidate\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\"\xf5\x01\n" +
+	"\x0eFormatDecision\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12#\n" +
+	"\runique_colors\x18\x04 \x01(\x05R\funiqueColors\x12\x1b\n" +
+	"\thas_alpha\x18\x05 \x01(\bR\bhasAlpha\x12\x1d\n" +
+	"\n" +
+	"flat_ratio\x18\x06 \x01(\x01R\tflatRatio\x12\x1d\n" +
+	"\n" +
+	"edge_ratio\x18\a \x01(\x01R\tedgeRatio\x12\x1f\n" +
+	"\vnoise_ratio\x18\b \x01(\x01R\n" +
+	"noiseRatio\"\x8a\x01\n" +
+	"\x10CompressResponse\x121\n" +
+	"\x06result\x18\x01 \x01(\v2\x19.squash.v1.CompressResultR\x06result\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x1b\n" +
+	"\tmime_type\x18\x03 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\"\x88\x01\n" +
+	"\n" +
+	"GifOptions\x12\x1f\n" +
+	"\vframe_delay\x18\x01 \x01(\x05R\n" +
+	"frameDelay\x12\x1d\n" +
+	"\n" +
+	"loop_count\x18\x02 \x01(\x05R\tloopCount\x12\x1b\n" +
+	"\tmax_width\x18\x03 \x01(\rR\bmaxWidth\x12\x1d\n" +
+	"\n" +
+	"max_height\x18\x04 \x01(\rR\tmaxHeight\"/\n" +
+	"\x05Frame\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"s\n" +
+	"\x16CreateAnimationRequest\x12(\n" +
+	"\x06frames\x18\x01 \x03(\v2\x10.squash.v1.FrameR\x06frames\x12/\n" +
+	"\aoptions\x18\x02 \x01(\v2\x15.squash.v1.GifOptionsR\aoptions\"\x99\x01\n" +
+	"\x17CreateAnimationResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x1b\n" +
+	"\tfile_size\x18\x02 \x01(\x03R\bfileSize\x12\x1f\n" +
+	"\vframe_count\x18\x03 \x01(\x05R\n" +
+	"frameCount\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\"R\n" +
+	"\x0eInspectRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x18\n" +
+	"\apreview\x18\x03 \x01(\bR\apreview\"\x93\x01\n" +
+	"\tImageInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\x12\x16\n" +
+	"\x06format\x18\x05 \x01(\tR\x06format\x12\x18\n" +
+	"\apreview\x18\x06 \x01(\tR\apreview2\xae\x02\n" +
+	"\x06Squash\x12C\n" +
+	"\bCompress\x12\x1a.squash.v1.CompressRequest\x1a\x1b.squash.v1.CompressResponse\x12I\n" +
+	"\x0eCompressStream\x12\x18.squash.v1.CompressChunk\x1a\x1b.squash.v1.CompressResponse(\x01\x12X\n" +
+	"\x0fCreateAnimation\x12!.squash.v1.CreateAnimationRequest\x1a\".squash.v1.CreateAnimationResponse\x12:\n" +
+	"\aInspect\x12\x19.squash.v1.InspectRequest\x1a\x14.squash.v1.ImageInfoB$Z\"image-compressor/squashpb;squashpbb\x06proto3"
+
+var (
+	file_squash_v1_squash_proto_rawDescOnce sync.Once
+	file_squash_v1_squash_proto_rawDescData []byte
+)
+
+func file_squash_v1_squash_proto_rawDescGZIP() []byte {
+	file_squash_v1_squash_proto_rawDescOnce.Do(func() {
+		file_squash_v1_squash_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_squash_v1_squash_proto_rawDesc), len(file_squash_v1_squash_proto_rawDesc)))
+	})
+	return file_squash_v1_squash_proto_rawDescData
+}
+
+var file_squash_v1_squash_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_squash_v1_squash_proto_goTypes = []any{
+	(*CompressOptions)(nil),         // 0: squash.v1.CompressOptions
+	(*CompressRequest)(nil),         // 1: squash.v1.CompressRequest
+	(*CompressChunk)(nil),           // 2: squash.v1.CompressChunk
+	(*CompressResult)(nil),          // 3: squash.v1.CompressResult
+	(*FormatCandidate)(nil),         // 4: squash.v1.FormatCandidate
+	(*FormatDecision)(nil),          // 5: squash.v1.FormatDecision
+	(*CompressResponse)(nil),        // 6: squash.v1.CompressResponse
+	(*GifOptions)(nil),              // 7: squash.v1.GifOptions
+	(*Frame)(nil),                   // 8: squash.v1.Frame
+	(*CreateAnimationRequest)(nil),  // 9: squash.v1.CreateAnimationRequest
+	(*CreateAnimationResponse)(nil), // 10: squash.v1.CreateAnimationResponse
+	(*InspectRequest)(nil),          // 11: squash.v1.InspectRequest
+	(*ImageInfo)(nil),               // 12: squash.v1.ImageInfo
+}
+var file_squash_v1_squash_proto_depIdxs = []int32{
+	0,  // 0: squash.v1.CompressRequest.options:type_name -> squash.v1.CompressOptions
+	0,  // 1: squash.v1.CompressChunk.options:type_name -> squash.v1.CompressOptions
+	4,  // 2: squash.v1.CompressResult.format_candidates:type_name -> squash.v1.FormatCandidate
+	5,  // 3: squash.v1.CompressResult.format_decision:type_name -> squash.v1.FormatDecision
+	3,  // 4: squash.v1.CompressResponse.result:type_name -> squash.v1.CompressResult
+	8,  // 5: squash.v1.CreateAnimationRequest.frames:type_name -> squash.v1.Frame
+	7,  // 6: squash.v1.CreateAnimationRequest.options:type_name -> squash.v1.GifOptions
+	1,  // 7: squash.v1.Squash.Compress:input_type -> squash.v1.CompressRequest
+	2,  // 8: squash.v1.Squash.CompressStream:input_type -> squash.v1.CompressChunk
+	9,  // 9: squash.v1.Squash.CreateAnimation:input_type -> squash.v1.CreateAnimationRequest
+	11, // 10: squash.v1.Squash.Inspect:input_type -> squash.v1.InspectRequest
+	6,  // 11: squash.v1.Squash.Compress:output_type -> squash.v1.CompressResponse
+	6,  // 12: squash.v1.Squash.CompressStream:output_type -> squash.v1.CompressResponse
+	10, // 13: squash.v1.Squash.CreateAnimation:output_type -> squash.v1.CreateAnimationResponse
+	12, // 14: squash.v1.Squash.Inspect:output_type -> squash.v1.ImageInfo
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_squash_v1_squash_proto_init() }
+func file_squash_v1_squash_proto_init() {
+	if File_squash_v1_squash_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_squash_v1_squash_proto_rawDesc), len(file_squash_v1_squash_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_squash_v1_squash_proto_goTypes,
+		DependencyIndexes: file_squash_v1_squash_proto_depIdxs,
+		MessageInfos:      file_squash_v1_squash_proto_msgTypes,
+	}.Build()
+	File_squash_v1_squash_proto = out.File
+	file_squash_v1_squash_proto_goTypes = nil
+	file_squash_v1_squash_proto_depIdxs = nil
+}
@@ -0,0 +1,676 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nfnt/resize"
+)
+
+// 与 TinyPNG API 兼容的接口：POST /shrink 压缩图片，结果通过 Location 中的 /output/<id> 获取，
+// 对 /output/<id> 发送 POST 可进行缩放（resize）、格式转换（convert）和保留元数据（preserve）
+
+const (
+	shrinkQuality   = 80        // 压缩质量（TinyPNG 不提供质量参数）
+	shrinkOutputTTL = time.Hour // 压缩结果保留时间
+	shrinkMaxOutput = 256       // 最多保留的压缩结果数
+	shrinkMaxBytes  = 256 << 20 // 保留的压缩结果（原图加输出）最多占用的内存
+)
+
+// apiKey 允许访问 TinyPNG 兼容接口的 API 密钥
+type apiKey struct {
+	Name string `json:"name"` // 备注名，用于日志
+	Key  string `json:"key"`
+}
+
+// apiKeyConfig API 密钥配置文件
+type apiKeyConfig struct {
+	Keys []apiKey `json:"keys"`
+}
+
+// defaultAPIKeysPath 返回默认的 API 密钥配置文件路径
+func defaultAPIKeysPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "api-keys.json"), nil
+}
+
+// loadAPIKeys 读取 API 密钥配置，文件不存在时返回空列表
+func loadAPIKeys(path string) ([]apiKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, newError(ErrRead, err, "tinify.keys_read_failed", path)
+	}
+	var cfg apiKeyConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, newError(ErrInvalidOptions, err, "tinify.keys_invalid", path)
+	}
+	keys := make([]apiKey, 0, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		if k.Key != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// shrinkOutput 保存的压缩结果，缩放和格式转换从原图重新编码
+type shrinkOutput struct {
+	input   []byte
+	name    string
+	enc     encodedImage
+	created time.Time
+}
+
+// size 压缩结果占用的内存（原图和输出数据）
+func (o *shrinkOutput) size() int64 {
+	return int64(len(o.input) + len(o.enc.Data))
+}
+
+// tinifyAPI TinyPNG 兼容接口的状态
+type tinifyAPI struct {
+	server   *apiServer
+	keysPath string
+
+	mu      sync.Mutex
+	keys    []apiKey
+	counts  map[string]int // 各密钥的压缩次数，通过 Compression-Count 响应头返回
+	outputs map[string]*shrinkOutput
+	order   []string // 压缩结果按创建顺序排列，用于清理
+	bytes   int64    // outputs 中原图和输出数据的总大小
+}
+
+func newTinifyAPI(s *apiServer) *tinifyAPI {
+	t := &tinifyAPI{
+		server:  s,
+		counts:  make(map[string]int),
+		outputs: make(map[string]*shrinkOutput),
+	}
+	s.mux.HandleFunc("POST /shrink", t.handleShrink)
+	s.mux.HandleFunc("GET /output/{id}", t.handleOutput)
+	s.mux.HandleFunc("POST /output/{id}", t.handleOutput)
+	return t
+}
+
+// setKeys 设置允许访问的 API 密钥，path 为配置文件路径（用于提示）
+func (t *tinifyAPI) setKeys(keys []apiKey, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys = keys
+	t.keysPath = path
+}
+
+// tinifyError TinyPNG 格式的错误响应
+type tinifyError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func writeTinifyError(w http.ResponseWriter, status int, name, message string) {
+	writeJSON(w, status, tinifyError{Error: name, Message: message})
+}
+
+// writeTinifyErr 按错误码返回 TinyPNG 格式的错误
+func writeTinifyErr(w http.ResponseWriter, err error) {
+	switch errorCode(err) {
+	case CodeUnsupportedFormat:
+		writeTinifyError(w, http.StatusUnsupportedMediaType, "Unsupported", err.Error())
+	case CodeDecode:
+		writeTinifyError(w, http.StatusUnsupportedMediaType, "DecodeError", err.Error())
+	case CodeTooLarge:
+		writeTinifyError(w, http.StatusRequestEntityTooLarge, "TooLarge", err.Error())
+	case CodeInvalidOptions, CodeRead:
+		writeTinifyError(w, http.StatusBadRequest, "BadRequest", err.Error())
+	case CodeCanceled:
+		writeTinifyError(w, http.StatusRequestTimeout, "Canceled", err.Error())
+	default:
+		writeTinifyError(w, http.StatusInternalServerError, "InternalServerError", err.Error())
+	}
+}
+
+// authenticate 校验 HTTP Basic 认证中的 API 密钥（用户名任意，tinify 客户端使用 "api"）
+func (t *tinifyAPI) authenticate(w http.ResponseWriter, r *http.Request) (apiKey, bool) {
+	t.mu.Lock()
+	keys, path := t.keys, t.keysPath
+	t.mu.Unlock()
+
+	if len(keys) == 0 {
+		writeTinifyError(w, http.StatusUnauthorized, "Unauthorized", tr("tinify.no_keys", path))
+		return apiKey{}, false
+	}
+	_, password, ok := r.BasicAuth()
+	if ok {
+		for _, k := range keys {
+			if subtle.ConstantTimeCompare([]byte(password), []byte(k.Key)) == 1 {
+				return k, true
+			}
+		}
+	}
+	w.Header().Set("WWW-Authenticate", `Basic realm="squash"`)
+	writeTinifyError(w, http.StatusUnauthorized, "Unauthorized", tr("tinify.unauthorized"))
+	return apiKey{}, false
+}
+
+// countCompression 记录一次压缩，返回该密钥的累计次数
+func (t *tinifyAPI) countCompression(key apiKey) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[key.Key]++
+	return t.counts[key.Key]
+}
+
+// compressionCount 返回该密钥的累计压缩次数
+func (t *tinifyAPI) compressionCount(key apiKey) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[key.Key]
+}
+
+// storeOutput 保存压缩结果并返回其 ID，同时清理过期、超出数量或超出 shrinkMaxBytes 的最早结果
+func (t *tinifyAPI) storeOutput(out *shrinkOutput) string {
+	var b [16]byte
+	rand.Read(b[:])
+	id := hex.EncodeToString(b[:])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	size := out.size()
+	for len(t.order) > 0 {
+		oldest := t.outputs[t.order[0]]
+		if len(t.order) < shrinkMaxOutput && t.bytes+size <= shrinkMaxBytes && now.Sub(oldest.created) < shrinkOutputTTL {
+			break
+		}
+		t.bytes -= oldest.size()
+		delete(t.outputs, t.order[0])
+		t.order = t.order[1:]
+	}
+	t.outputs[id] = out
+	t.order = append(t.order, id)
+	t.bytes += size
+	return id
+}
+
+// lookupOutput 查找未过期的压缩结果
+func (t *tinifyAPI) lookupOutput(id string) *shrinkOutput {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := t.outputs[id]
+	if out == nil || time.Since(out.created) >= shrinkOutputTTL {
+		return nil
+	}
+	return out
+}
+
+// shrinkSource JSON 请求中的图片来源
+type shrinkSource struct {
+	Source struct {
+		URL string `json:"url"`
+	} `json:"source"`
+}
+
+// handleShrink 压缩上传的图片（请求体为图片数据，或 {"source":{"url":...}}），返回压缩结果信息和 Location
+func (t *tinifyAPI) handleShrink(w http.ResponseWriter, r *http.Request) {
+	key, ok := t.authenticate(w, r)
+	if !ok {
+		return
+	}
+	s := t.server
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBody)
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeTinifyErr(w, bodyError(err))
+		return
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var src shrinkSource
+		if err := json.Unmarshal(data, &src); err != nil || src.Source.URL == "" {
+			writeTinifyError(w, http.StatusBadRequest, "InputMissing", tr("tinify.bad_source"))
+			return
+		}
+		data, err = fetchSource(r.Context(), src.Source.URL, s.maxBody)
+		if err != nil {
+			writeTinifyError(w, http.StatusBadRequest, "SourceUnavailable", err.Error())
+			return
+		}
+	}
+	if len(data) == 0 {
+		writeTinifyError(w, http.StatusBadRequest, "InputMissing", tr("server.no_file"))
+		return
+	}
+
+	if err := s.acquire(r.Context()); err != nil {
+		writeTinifyErr(w, err)
+		return
+	}
+	defer s.release()
+
+	name := "image" + sniffExtension(data)
+	enc, _, err := compressBytes(r.Context(), data, name, CompressOptions{Quality: shrinkQuality, OutputFormat: "original", KeepAspect: true})
+	if err != nil {
+		writeTinifyErr(w, err)
+		return
+	}
+	id := t.storeOutput(&shrinkOutput{input: data, name: name, enc: enc, created: time.Now()})
+	count := t.countCompression(key)
+	logger.Info("shrink", "key", key.Name, "id", id, "format", enc.Format, "input", len(data), "output", len(enc.Data))
+
+	location := requestBaseURL(r) + "/output/" + id
+	w.Header().Set("Location", location)
+	w.Header().Set("Compression-Count", strconv.Itoa(count))
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"input": map[string]interface{}{
+			"size": len(data),
+			"type": formatMimeType(enc.InputFormat),
+		},
+		"output": map[string]interface{}{
+			"size":   len(enc.Data),
+			"type":   enc.MimeType,
+			"width":  enc.NewWidth,
+			"height": enc.NewHeight,
+			"ratio":  math.Round(float64(len(enc.Data))/float64(len(data))*10000) / 10000,
+			"url":    location,
+		},
+	})
+}
+
+// outputRequest 对压缩结果的操作
+type outputRequest struct {
+	Resize *struct {
+		Method string `json:"method"` // scale, fit, cover, thumb
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	} `json:"resize"`
+	Preserve []string `json:"preserve"`
+	Convert  *struct {
+		Type json.RawMessage `json:"type"` // 单个 MIME 类型或数组，多个时返回最小的结果
+	} `json:"convert"`
+	Store json.RawMessage `json:"store"`
+}
+
+// handleOutput 下载压缩结果（GET），或按请求中的操作处理后下载（POST）
+func (t *tinifyAPI) handleOutput(w http.ResponseWriter, r *http.Request) {
+	key, ok := t.authenticate(w, r)
+	if !ok {
+		return
+	}
+	out := t.lookupOutput(r.PathValue("id"))
+	if out == nil {
+		writeTinifyError(w, http.StatusNotFound, "NotFound", tr("tinify.output_not_found"))
+		return
+	}
+
+	var req outputRequest
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			writeTinifyErr(w, bodyError(err))
+			return
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err := json.Unmarshal(body, &req); err != nil {
+				writeTinifyError(w, http.StatusBadRequest, "BadRequest", tr("server.invalid_options_json"))
+				return
+			}
+		}
+	}
+	if len(req.Store) > 0 {
+		writeTinifyError(w, http.StatusBadRequest, "BadRequest", tr("tinify.store_unsupported"))
+		return
+	}
+
+	data, mimeType, width, height := out.enc.Data, out.enc.MimeType, out.enc.NewWidth, out.enc.NewHeight
+	if req.Resize != nil || req.Convert != nil {
+		if err := t.server.acquire(r.Context()); err != nil {
+			writeTinifyErr(w, err)
+			return
+		}
+		var err error
+		data, mimeType, width, height, err = transformOutput(r.Context(), out, req)
+		t.server.release()
+		if err != nil {
+			writeTinifyErr(w, err)
+			return
+		}
+		t.countCompression(key)
+	}
+
+	if len(req.Preserve) > 0 {
+		var err error
+		if data, err = preserveMetadata(out.input, data, mimeType, req.Preserve); err != nil {
+			writeTinifyErr(w, err)
+			return
+		}
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", mimeType)
+	h.Set("Content-Length", strconv.Itoa(len(data)))
+	h.Set("Image-Width", strconv.Itoa(width))
+	h.Set("Image-Height", strconv.Itoa(height))
+	h.Set("Compression-Count", strconv.Itoa(t.compressionCount(key)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// transformOutput 从原图缩放并按目标格式重新编码，多个目标格式时返回最小的结果
+func transformOutput(ctx context.Context, out *shrinkOutput, req outputRequest) ([]byte, string, int, int, error) {
+	formats := []string{out.enc.Format}
+	if req.Convert != nil {
+		var err error
+		if formats, err = convertFormats(req.Convert.Type); err != nil {
+			return nil, "", 0, 0, err
+		}
+	}
+
+	img, _, err := decodeImage(out.input, out.name)
+	if err != nil {
+		return nil, "", 0, 0, err
+	}
+	if req.Resize != nil {
+		if img, err = tinifyResize(img, req.Resize.Method, req.Resize.Width, req.Resize.Height); err != nil {
+			return nil, "", 0, 0, err
+		}
+	}
+	if err := checkCanceled(ctx); err != nil {
+		return nil, "", 0, 0, err
+	}
+
+	var best []byte
+	var bestMime string
+	for _, format := range formats {
+		data, mimeType, err := encodeImage(ctx, img, format, shrinkQuality)
+		if err != nil {
+			return nil, "", 0, 0, withKind(ErrEncode, err, "err.compress")
+		}
+		if best == nil || len(data) < len(best) {
+			best, bestMime = data, mimeType
+		}
+	}
+	b := img.Bounds()
+	return best, bestMime, b.Dx(), b.Dy(), nil
+}
+
+// convertFormats 解析 convert.type（单个 MIME 类型或数组，"*/*" 表示所有可用格式）
+func convertFormats(raw json.RawMessage) ([]string, error) {
+	var types []string
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		types = []string{single}
+	} else if err := json.Unmarshal(raw, &types); err != nil {
+		return nil, newError(ErrInvalidOptions, err, "tinify.bad_convert")
+	}
+
+	var formats []string
+	for _, t := range types {
+		if t == "*/*" {
+			for _, f := range []string{"png", "jpeg", "webp", "avif"} {
+				if responsiveFormatSupported(f) && !containsString(formats, f) {
+					formats = append(formats, f)
+				}
+			}
+			continue
+		}
+		f := strings.TrimPrefix(t, "image/")
+		if f == "jpg" {
+			f = "jpeg"
+		}
+		if !responsiveFormatSupported(f) {
+			return nil, newError(ErrUnsupportedFormat, nil, "err.unsupported_output", t)
+		}
+		if !containsString(formats, f) {
+			formats = append(formats, f)
+		}
+	}
+	if len(formats) == 0 {
+		return nil, newError(ErrInvalidOptions, nil, "tinify.bad_convert")
+	}
+	return formats, nil
+}
+
+// tinifyResize 按 TinyPNG 的缩放方式调整尺寸
+// scale：按给定的宽或高等比缩放；fit：等比缩放到框内；cover、thumb：等比缩放并居中裁剪到给定尺寸
+// 只缩小不放大；thumb 没有主体识别，与 cover 相同。缩放前检查目标尺寸，超过 maxImagePixels 时返回 ErrTooLarge
+func tinifyResize(img image.Image, method string, width, height int) (image.Image, error) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	switch method {
+	case "scale":
+		if width < 0 || height < 0 || (width > 0) == (height > 0) {
+			return nil, newError(ErrInvalidOptions, nil, "tinify.scale_dimension")
+		}
+		if (width > 0 && width >= w) || (height > 0 && height >= h) {
+			return img, nil
+		}
+		tw, th := float64(width), float64(height)
+		if width > 0 {
+			th = tw * float64(h) / float64(w)
+		} else {
+			tw = th * float64(w) / float64(h)
+		}
+		if err := checkResizeSize(tw, th); err != nil {
+			return nil, err
+		}
+		return resize.Resize(uint(width), uint(height), img, resize.Lanczos3), nil
+	case "fit":
+		if width <= 0 || height <= 0 {
+			return nil, newError(ErrInvalidOptions, nil, "tinify.need_dimensions", method)
+		}
+		if err := checkResizeSize(float64(min(width, w)), float64(min(height, h))); err != nil {
+			return nil, err
+		}
+		return resize.Thumbnail(uint(width), uint(height), img, resize.Lanczos3), nil
+	case "cover", "thumb":
+		if width <= 0 || height <= 0 {
+			return nil, newError(ErrInvalidOptions, nil, "tinify.need_dimensions", method)
+		}
+		width, height = min(width, w), min(height, h)
+		scale := math.Max(float64(width)/float64(w), float64(height)/float64(h))
+		sw := max(width, int(math.Round(float64(w)*scale)))
+		sh := max(height, int(math.Round(float64(h)*scale)))
+		if err := checkResizeSize(float64(sw), float64(sh)); err != nil {
+			return nil, err
+		}
+		scaled := resize.Resize(uint(sw), uint(sh), img, resize.Lanczos3)
+
+		// 居中裁剪
+		x0 := (sw - width) / 2
+		y0 := (sh - height) / 2
+		dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+		sb := scaled.Bounds()
+		draw.Draw(dst, dst.Bounds(), scaled, image.Pt(sb.Min.X+x0, sb.Min.Y+y0), draw.Src)
+		return dst, nil
+	}
+	return nil, newError(ErrInvalidOptions, nil, "tinify.bad_resize_method", method)
+}
+
+// preserveMetadata 将原图中指定类别的元数据写入输出，目前只支持 JPEG 到 JPEG
+// 原图没有对应元数据或输出不是 JPEG 时原样返回
+func preserveMetadata(input, output []byte, mimeType string, preserve []string) ([]byte, error) {
+	for _, p := range preserve {
+		if p != PreserveCopyright && p != PreserveCreation && p != PreserveLocation {
+			return nil, newError(ErrInvalidOptions, nil, "tinify.bad_preserve", p)
+		}
+	}
+	if mimeType != "image/jpeg" {
+		return output, nil
+	}
+	exif := jpegExif(input)
+	if exif == nil {
+		return output, nil
+	}
+	filtered, err := filterExif(exif, preserve)
+	if err != nil || filtered == nil {
+		// 原图元数据损坏时不保留，不影响压缩结果
+		if err != nil {
+			logger.Warn("exif parse failed", "error", err)
+		}
+		return output, nil
+	}
+	return insertJPEGExif(output, filtered)
+}
+
+// errSourceBlocked source.url 解析到非公网地址
+var errSourceBlocked = errors.New("non-public address")
+
+// sourceClient 下载 source.url 使用的客户端：不使用代理，连接时检查实际连接的 IP，
+// 拒绝本机、内网、链路本地（如 169.254.169.254）等非公网地址，重定向后的每次连接同样检查
+var sourceClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip, err := netip.ParseAddr(host)
+				if err != nil || !isPublicAddr(ip) {
+					return fmt.Errorf("%w: %s", errSourceBlocked, host)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return newError(ErrInvalidOptions, nil, "tinify.bad_source")
+		}
+		return nil
+	},
+}
+
+// isPublicAddr 判断是否为公网单播地址
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// nonPublicPrefixes IsPrivate 之外的保留地址段：运营商级 NAT、基准测试、文档、NAT64 等
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// fetchSource 下载 source.url 指向的图片（只允许公网地址）
+func fetchSource(ctx context.Context, rawURL string, maxBody int64) ([]byte, error) {
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return nil, newError(ErrInvalidOptions, nil, "tinify.bad_source")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, newError(ErrInvalidOptions, err, "tinify.bad_source")
+	}
+	resp, err := sourceClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errSourceBlocked) {
+			return nil, newError(ErrInvalidOptions, err, "tinify.source_blocked", rawURL)
+		}
+		return nil, newError(ErrRead, err, "tinify.fetch_failed", rawURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError(ErrRead, fmt.Errorf("HTTP %d", resp.StatusCode), "tinify.fetch_failed", rawURL)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBody+1))
+	if err != nil {
+		return nil, newError(ErrRead, err, "tinify.fetch_failed", rawURL)
+	}
+	if int64(len(data)) > maxBody {
+		return nil, newError(ErrTooLarge, nil, "server.body_too_large", formatFileSize(maxBody))
+	}
+	return data, nil
+}
+
+// requestBaseURL 返回客户端访问本服务使用的地址（支持反向代理的 X-Forwarded-Proto）
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// sniffExtension 按文件内容推断扩展名，用于没有文件名的上传
+func sniffExtension(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "image/bmp":
+		return ".bmp"
+	}
+	if len(data) >= 12 && string(data[4:12]) == "ftypavif" {
+		return ".avif"
+	}
+	if bytes.HasPrefix(data, []byte("II*\x00")) || bytes.HasPrefix(data, []byte("MM\x00*")) {
+		return ".tiff"
+	}
+	return ""
+}
+
+// formatMimeType 返回图片格式对应的 MIME 类型
+func formatMimeType(format string) string {
+	switch format {
+	case "jpeg", "jpg":
+		return "image/jpeg"
+	case "tif":
+		return "image/tiff"
+	}
+	return "image/" + format
+}
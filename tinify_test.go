@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"testing"
+	"time"
+)
+
+// 各缩放方式的输出尺寸，只缩小不放大
+func TestTinifyResize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	cases := []struct {
+		method        string
+		width, height int
+		want          image.Point // 零值表示 ErrInvalidOptions
+	}{
+		{"scale", 20, 0, image.Pt(20, 10)},
+		{"scale", 0, 10, image.Pt(20, 10)},
+		{"scale", 100000, 0, image.Pt(40, 20)},
+		{"scale", 0, 100000, image.Pt(40, 20)},
+		{"scale", 10, 10, image.Point{}},
+		{"scale", 0, 0, image.Point{}},
+		{"scale", -5, 10, image.Point{}},
+		{"fit", 10, 10, image.Pt(10, 5)},
+		{"fit", 100000, 100000, image.Pt(40, 20)},
+		{"fit", 0, 10, image.Point{}},
+		{"cover", 10, 10, image.Pt(10, 10)},
+		{"cover", 100000, 100000, image.Pt(40, 20)},
+		{"cover", 100000, 5, image.Pt(40, 5)},
+		{"cover", -1, 10, image.Point{}},
+		{"thumb", 30, 5, image.Pt(30, 5)},
+		{"crop", 10, 10, image.Point{}},
+	}
+	for _, c := range cases {
+		got, err := tinifyResize(img, c.method, c.width, c.height)
+		if c.want == (image.Point{}) {
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("%s %dx%d: want ErrInvalidOptions, got %v", c.method, c.width, c.height, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %dx%d: %v", c.method, c.width, c.height, err)
+			continue
+		}
+		if got.Bounds().Size() != c.want {
+			t.Errorf("%s %dx%d: got %v, want %v", c.method, c.width, c.height, got.Bounds().Size(), c.want)
+		}
+	}
+}
+
+// 保留的压缩结果超过 shrinkMaxBytes 时清理最早的结果
+func TestStoreOutputBytes(t *testing.T) {
+	api := &tinifyAPI{outputs: make(map[string]*shrinkOutput)}
+	chunk := make([]byte, shrinkMaxBytes/4)
+	var ids []string
+	for range 6 {
+		ids = append(ids, api.storeOutput(&shrinkOutput{input: chunk, enc: encodedImage{Data: chunk}, created: time.Now()}))
+	}
+	if api.bytes > shrinkMaxBytes {
+		t.Fatalf("%d bytes stored, limit %d", api.bytes, shrinkMaxBytes)
+	}
+	for i, id := range ids {
+		if kept := api.lookupOutput(id) != nil; kept != (i >= 4) {
+			t.Errorf("output %d kept = %v", i, kept)
+		}
+	}
+}
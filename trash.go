@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// trashMu 保护回收站索引文件的读写
+var trashMu sync.Mutex
+
+// trashDir 返回回收站目录，不存在时创建
+func trashDir() (string, error) {
+	dataDir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(dataDir, "trash")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// loadTrashIndex 读取回收站索引
+func loadTrashIndex(dir string) ([]TrashEntry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if os.IsNotExist(err) {
+		return []TrashEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// saveTrashIndex 写入回收站索引
+func saveTrashIndex(dir string, entries []TrashEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, "index.json"), data, 0644)
+}
+
+// moveToTrash 将文件原始内容保存到回收站，并记录原路径以便恢复
+func moveToTrash(path string, original []byte, stat os.FileInfo) (TrashEntry, error) {
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	dir, err := trashDir()
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	now := time.Now()
+	id := newTimeID()
+	entryDir := filepath.Join(dir, id)
+	if err := os.Mkdir(entryDir, 0755); err != nil {
+		return TrashEntry{}, err
+	}
+
+	trashedPath := filepath.Join(entryDir, filepath.Base(path))
+	if err := writeFileAtomic(trashedPath, original, stat.Mode().Perm()); err != nil {
+		return TrashEntry{}, err
+	}
+	os.Chtimes(trashedPath, stat.ModTime(), stat.ModTime())
+
+	entries, err := loadTrashIndex(dir)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	entry := TrashEntry{
+		ID:           id,
+		OriginalPath: absPath,
+		TrashedPath:  trashedPath,
+		Size:         int64(len(original)),
+		TrashedAt:    now.Format(time.RFC3339),
+	}
+	entries = append(entries, entry)
+	if err := saveTrashIndex(dir, entries); err != nil {
+		return TrashEntry{}, err
+	}
+	return entry, nil
+}
+
+// restoreFromTrash 将回收站中的文件写回原路径，并从回收站移除
+func restoreFromTrash(id string) (TrashEntry, error) {
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	dir, err := trashDir()
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	entries, err := loadTrashIndex(dir)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+
+	for i, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+		data, err := os.ReadFile(entry.TrashedPath)
+		if err != nil {
+			return entry, err
+		}
+		stat, err := os.Stat(entry.TrashedPath)
+		if err != nil {
+			return entry, err
+		}
+		if err := writeFileAtomic(entry.OriginalPath, data, stat.Mode().Perm()); err != nil {
+			return entry, err
+		}
+		os.Chtimes(entry.OriginalPath, stat.ModTime(), stat.ModTime())
+
+		os.RemoveAll(filepath.Dir(entry.TrashedPath))
+		entries = append(entries[:i], entries[i+1:]...)
+		return entry, saveTrashIndex(dir, entries)
+	}
+	return TrashEntry{}, newError(ErrWrite, nil, "trash.not_found", id)
+}
+
+// ListTrash 列出回收站中可恢复的原始文件
+func (a *App) ListTrash() []TrashEntry {
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	dir, err := trashDir()
+	if err != nil {
+		return []TrashEntry{}
+	}
+	entries, err := loadTrashIndex(dir)
+	if err != nil {
+		return []TrashEntry{}
+	}
+	return entries
+}
+
+// RestoreTrash 将回收站中的原始文件恢复到原位置
+func (a *App) RestoreTrash(id string) ActionResult {
+	entry, err := restoreFromTrash(id)
+	if err != nil {
+		return ActionResult{Success: false, Message: tr("restore.failed", err)}
+	}
+	return ActionResult{Success: true, Message: tr("trash.restored", entry.OriginalPath)}
+}
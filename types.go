@@ -11,6 +11,8 @@ type ImageInfo struct {
 	Height  int    `json:"height"`
 	Format  string `json:"format"`
 	Preview string `json:"preview"`
+	Code    string `json:"code"`    // 读取失败时的错误码
+	Message string `json:"message"` // 读取失败时的错误信息
 }
 
 // CompressOptions 压缩选项
@@ -18,14 +20,33 @@ type CompressOptions struct {
 	Quality      int    `json:"quality"`
 	MaxWidth     uint   `json:"maxWidth"`
 	MaxHeight    uint   `json:"maxHeight"`
-	OutputFormat string `json:"outputFormat"` // "original", "jpeg", "png", "webp"
+	OutputFormat string `json:"outputFormat"` // "original", "auto", "best", "jpeg", "png", "webp", "avif"
 	OutputDir    string `json:"outputDir"`
 	KeepAspect   bool   `json:"keepAspect"`
+	NameTemplate string `json:"nameTemplate"` // 文件名模板，支持 {name} {ext} {w} {h} {quality} {date} {hash}，默认 "{name}.{ext}"，输出到源文件所在目录时为 "{name}.min.{ext}"
+	Collision    string `json:"collision"`    // 冲突策略："overwrite"（默认）, "skip", "increment", "fail"
+	InPlace      bool   `json:"inPlace"`      // 原地优化：结果原子替换源文件（仅在更小时），忽略输出目录和文件名模板
+	Backup       string `json:"backup"`       // 原地优化时的备份方式："none"（默认）, "orig", "trash"
+
+	AcceptFormats []string `json:"acceptFormats"` // "best" 时可接受的格式（格式名或 MIME 类型，如 HTTP Accept 中的各项），为空时不限制
+	Background    string   `json:"background"`    // 输出格式不支持透明通道时透明区域的背景色：#rrggbb、#rgb 或 "auto"（边缘最常见的颜色），默认白色
+
+	JPEGProgressive bool   `json:"jpegProgressive"` // 输出渐进式 JPEG
+	JPEGSubsampling string `json:"jpegSubsampling"` // JPEG 色度抽样："420"（默认）, "422", "444"
+	JPEGQuantTable  string `json:"jpegQuantTable"`  // JPEG 量化表："standard"（默认）, "mozjpeg"，或 64/128 个逗号分隔的数（自然顺序，按质量缩放）
+	JPEGLossless    bool   `json:"jpegLossless"`    // JPEG 无损优化：JPEG 输入、JPEG 输出且不缩放时不重新编码，只重写熵编码（忽略质量等选项）
+	PNGEffort       int    `json:"pngEffort"`       // PNG 无损优化级别：0 使用标准库编码器（默认），1-3 依次更慢、更小；非 0 且质量为 100 时不量化
+
+	IgnoreProjectConfig bool `json:"ignoreProjectConfig"` // 不使用项目配置文件（.squashrc / squash.toml）中的规则
+	Force               bool `json:"force"`               // 不使用缓存，并且重新压缩由 Squash 生成的文件
+
+	JobID string `json:"jobId,omitempty"` // 调用方指定的任务 ID，可通过 Cancel 取消（不保存到预设）
 }
 
 // CompressResult 压缩结果
 type CompressResult struct {
 	Success          bool    `json:"success"`
+	Code             string  `json:"code"` // 失败时的错误码，如 "decode_failed"，成功时为空
 	Message          string  `json:"message"`
 	OriginalSize     int64   `json:"originalSize"`
 	NewSize          int64   `json:"newSize"`
@@ -37,43 +58,321 @@ type CompressResult struct {
 	NewWidth         int     `json:"newWidth"`
 	NewHeight        int     `json:"newHeight"`
 	CompressionRatio float64 `json:"compressionRatio"`
+	Skipped          bool    `json:"skipped"`    // 目标已存在且冲突策略为跳过
+	BackupPath       string  `json:"backupPath"` // 原地优化时原始文件的备份位置
+
+	ProjectConfig string   `json:"projectConfig"` // 使用的项目配置文件
+	AppliedRules  []string `json:"appliedRules"`  // 命中的项目配置规则
+	InputHash     string   `json:"inputHash"`     // 源文件内容的 SHA-256
+	Cached        bool     `json:"cached"`        // 使用了缓存的编码结果
+
+	InputPath    string   `json:"inputPath"`
+	InputFormat  string   `json:"inputFormat"`
+	OutputFormat string   `json:"outputFormat"`
+	Quality      int      `json:"quality"`    // 实际使用的质量（应用项目配置之后）
+	DurationMs   int64    `json:"durationMs"` // 处理耗时
+	Warnings     []string `json:"warnings"`   // 不影响成功的提示，如保持了原文件
+
+	FormatCandidates []FormatCandidate `json:"formatCandidates,omitempty"` // "best" 时各候选格式的编码大小，OutputFormat 为最终选中的格式
+	FormatDecision   *FormatDecision   `json:"formatDecision,omitempty"`   // "auto" 时的内容分析和选择理由
+	Lossless         bool              `json:"lossless"`                   // 使用了 JPEG 无损优化，像素与原图完全相同
+}
+
+// FormatDecision "auto" 输出格式根据图片内容做出的选择
+type FormatDecision struct {
+	Mode         string  `json:"mode"`         // "png-lossless", "png-quantized", "jpeg", "webp"
+	Format       string  `json:"format"`       // 输出格式
+	Reason       string  `json:"reason"`       // 选择理由（按当前语言生成）
+	UniqueColors int     `json:"uniqueColors"` // 采样得到的颜色数
+	HasAlpha     bool    `json:"hasAlpha"`     // 是否带透明通道
+	FlatRatio    float64 `json:"flatRatio"`    // 与相邻像素颜色相同的比例
+	EdgeRatio    float64 `json:"edgeRatio"`    // 与相邻像素差异很大（硬边缘）的比例
+	NoiseRatio   float64 `json:"noiseRatio"`   // 与相邻像素有细微差异（噪点、渐变）的比例
+}
+
+// FormatCandidate "best" 输出格式下参与比较的一种格式
+type FormatCandidate struct {
+	Format string `json:"format"`
+	Size   int64  `json:"size"`
 }
 
 // GifOptions GIF 生成选项
 type GifOptions struct {
-	FrameDelay int    `json:"frameDelay"` // 帧延迟，单位：毫秒
-	LoopCount  int    `json:"loopCount"`  // 循环次数，0=无限循环
-	MaxWidth   uint   `json:"maxWidth"`   // 最大宽度
-	MaxHeight  uint   `json:"maxHeight"`  // 最大高度
-	OutputDir  string `json:"outputDir"`  // 输出目录
-	OutputName string `json:"outputName"` // 输出文件名（不含扩展名）
+	FrameDelay int    `json:"frameDelay"`      // 帧延迟，单位：毫秒
+	LoopCount  int    `json:"loopCount"`       // 循环次数，0=无限循环
+	MaxWidth   uint   `json:"maxWidth"`        // 最大宽度
+	MaxHeight  uint   `json:"maxHeight"`       // 最大高度
+	OutputDir  string `json:"outputDir"`       // 输出目录
+	OutputName string `json:"outputName"`      // 输出文件名（不含扩展名）
+	Collision  string `json:"collision"`       // 冲突策略，同 CompressOptions.Collision
+	JobID      string `json:"jobId,omitempty"` // 任务 ID，同 CompressOptions.JobID
 }
 
 // GifResult GIF 生成结果
 type GifResult struct {
-	Success    bool   `json:"success"`
-	Message    string `json:"message"`
-	OutputPath string `json:"outputPath"`
-	FileSize   int64  `json:"fileSize"`
-	FrameCount int    `json:"frameCount"`
-	Width      int    `json:"width"`
-	Height     int    `json:"height"`
-	Preview    string `json:"preview"` // Base64 预览
+	Success    bool     `json:"success"`
+	Code       string   `json:"code"` // 失败时的错误码，同 CompressResult.Code
+	Message    string   `json:"message"`
+	OutputPath string   `json:"outputPath"`
+	FileSize   int64    `json:"fileSize"`
+	FrameCount int      `json:"frameCount"`
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	Preview    string   `json:"preview"`   // Base64 预览
+	Skipped    bool     `json:"skipped"`   // 目标已存在且冲突策略为跳过
+	InputHash  string   `json:"inputHash"` // 源文件内容的 SHA-256（GIF 压缩）
+	Warnings   []string `json:"warnings"`  // 不影响成功的提示，如预览生成失败
 }
 
 // GifCompressOptions GIF 压缩选项
 type GifCompressOptions struct {
-	MaxWidth  uint   `json:"maxWidth"`  // 最大宽度，0表示不限制
-	MaxHeight uint   `json:"maxHeight"` // 最大高度，0表示不限制
-	Colors    int    `json:"colors"`    // 颜色数量 2-256，越少文件越小
-	Lossy     int    `json:"lossy"`     // 有损压缩级别 0-200，0=无损
-	OutputDir string `json:"outputDir"` // 输出目录
+	MaxWidth     uint   `json:"maxWidth"`        // 最大宽度，0表示不限制
+	MaxHeight    uint   `json:"maxHeight"`       // 最大高度，0表示不限制
+	Colors       int    `json:"colors"`          // 颜色数量 2-256，越少文件越小
+	Lossy        int    `json:"lossy"`           // 有损压缩级别 0-200，0=无损
+	OutputDir    string `json:"outputDir"`       // 输出目录
+	NameTemplate string `json:"nameTemplate"`    // 文件名模板，默认 "{name}_compressed.{ext}"
+	Collision    string `json:"collision"`       // 冲突策略，同 CompressOptions.Collision
+	JobID        string `json:"jobId,omitempty"` // 任务 ID，同 CompressOptions.JobID
 }
 
 // colorBox 表示 Median Cut 算法中的颜色盒子
 type colorBox struct {
-	colors         []color.RGBA
-	rMin, rMax     uint8
-	gMin, gMax     uint8
-	bMin, bMax     uint8
+	colors     []color.RGBA
+	rMin, rMax uint8
+	gMin, gMax uint8
+	bMin, bMax uint8
+}
+
+// ResponsiveSpec 响应式图片集（srcset）生成规格
+type ResponsiveSpec struct {
+	Widths       []uint   `json:"widths"`          // 输出宽度列表，为空时使用 320/640/1024/1600/2400
+	Formats      []string `json:"formats"`         // 输出格式列表，为空时使用 avif/webp/jpeg
+	Quality      int      `json:"quality"`         // 压缩质量 1-100
+	OutputDir    string   `json:"outputDir"`       // 输出目录，为空时使用源文件所在目录
+	NameTemplate string   `json:"nameTemplate"`    // 文件名模板，变量同 CompressOptions.NameTemplate，默认 "{name}-{w}.{ext}"
+	Collision    string   `json:"collision"`       // 冲突策略，同 CompressOptions.Collision
+	URLPrefix    string   `json:"urlPrefix"`       // HTML 片段中的 URL 前缀，如 "/assets/img/"
+	Sizes        string   `json:"sizes"`           // <img sizes> 属性，默认 "100vw"
+	Alt          string   `json:"alt"`             // <img alt> 属性
+	AllowUpscale bool     `json:"allowUpscale"`    // 是否允许输出宽度大于原图
+	JobID        string   `json:"jobId,omitempty"` // 任务 ID，同 CompressOptions.JobID；取消时删除已写入的变体
+}
+
+// ResponsiveVariant 响应式图片集中的单个变体
+type ResponsiveVariant struct {
+	Format   string `json:"format"`
+	MimeType string `json:"mimeType"`
+	Path     string `json:"path"`
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int64  `json:"size"`
+}
+
+// ResponsiveResult 响应式图片集生成结果
+type ResponsiveResult struct {
+	Success        bool                `json:"success"`
+	Code           string              `json:"code"` // 失败时的错误码，同 CompressResult.Code
+	Message        string              `json:"message"`
+	OriginalSize   int64               `json:"originalSize"`
+	OriginalWidth  int                 `json:"originalWidth"`
+	OriginalHeight int                 `json:"originalHeight"`
+	TotalSize      int64               `json:"totalSize"`
+	Variants       []ResponsiveVariant `json:"variants"` // 清单：所有已写入的变体
+	HTML           string              `json:"html"`     // 可直接粘贴的 <picture>/srcset 片段
+}
+
+// TrashEntry 回收站中保存的原始文件
+type TrashEntry struct {
+	ID           string `json:"id"`
+	OriginalPath string `json:"originalPath"`
+	TrashedPath  string `json:"trashedPath"`
+	Size         int64  `json:"size"`
+	TrashedAt    string `json:"trashedAt"`
+}
+
+// ActionResult 通用操作结果
+type ActionResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// DirectoryOptions 文件夹递归压缩选项
+type DirectoryOptions struct {
+	Compress      CompressOptions `json:"compress"`        // 压缩选项，OutputDir 作为镜像目录树的根
+	Include       []string        `json:"include"`         // 包含的 glob 模式（支持 **），为空时包含所有支持的图片
+	Exclude       []string        `json:"exclude"`         // 排除的 glob 模式
+	IncludeHidden bool            `json:"includeHidden"`   // 是否处理隐藏文件和系统文件
+	Reports       []string        `json:"reports"`         // 完成后写入的报告路径，按扩展名输出 JSON（.json）或 CSV（.csv）
+	JobID         string          `json:"jobId,omitempty"` // 任务 ID，同 CompressOptions.JobID；取消后剩余文件不再处理
+}
+
+// DirectoryFileResult 文件夹压缩中单个文件的结果
+type DirectoryFileResult struct {
+	RelPath string         `json:"relPath"` // 相对于根目录的路径
+	Result  CompressResult `json:"result"`
+}
+
+// DirectoryResult 文件夹压缩结果
+type DirectoryResult struct {
+	Success      bool                  `json:"success"`
+	Message      string                `json:"message"`
+	Root         string                `json:"root"`
+	OutputDir    string                `json:"outputDir"`
+	Total        int                   `json:"total"`
+	Succeeded    int                   `json:"succeeded"`
+	Failed       int                   `json:"failed"`
+	Skipped      int                   `json:"skipped"`
+	Canceled     int                   `json:"canceled"` // 因取消未完成的文件数
+	OriginalSize int64                 `json:"originalSize"`
+	NewSize      int64                 `json:"newSize"`
+	Files        []DirectoryFileResult `json:"files"`
+}
+
+// WatchOptions 监视文件夹选项
+type WatchOptions struct {
+	Dir        string          `json:"dir"`        // 监视的文件夹
+	Compress   CompressOptions `json:"compress"`   // 压缩选项，OutputDir 必须为其他文件夹
+	Preset     string          `json:"preset"`     // 预设名称，设置后使用预设的压缩选项（OutputDir 以本选项为准）
+	Recursive  bool            `json:"recursive"`  // 是否同时监视子文件夹
+	DebounceMs int             `json:"debounceMs"` // 文件停止变化多久后开始处理，默认 1000ms
+	Mode       string          `json:"mode"`       // "copy"（默认，保留源文件）或 "move"（成功后删除源文件）
+	Include    []string        `json:"include"`    // 包含的 glob 模式，为空时包含所有支持的图片
+	Exclude    []string        `json:"exclude"`    // 排除的 glob 模式
+}
+
+// WatchInfo 监视任务状态
+type WatchInfo struct {
+	ID        string `json:"id"`
+	Dir       string `json:"dir"`
+	OutputDir string `json:"outputDir"`
+	Processed int    `json:"processed"`
+	Failed    int    `json:"failed"`
+	StartedAt string `json:"startedAt"`
+}
+
+// WatchResult 开始监视的结果
+type WatchResult struct {
+	Success bool      `json:"success"`
+	Message string    `json:"message"`
+	Watch   WatchInfo `json:"watch"`
+}
+
+// Preset 命名的压缩预设，可保存到磁盘并在团队间共享
+type Preset struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Compress    *CompressOptions    `json:"compress,omitempty"`
+	Gif         *GifOptions         `json:"gif,omitempty"`
+	GifCompress *GifCompressOptions `json:"gifCompress,omitempty"`
+}
+
+// PresetResult 预设操作结果
+type PresetResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Preset  Preset `json:"preset"`
+}
+
+// HistoryEntry 一次压缩操作的历史记录
+type HistoryEntry struct {
+	ID           string      `json:"id"`
+	Time         string      `json:"time"`
+	Kind         string      `json:"kind"` // "image", "gif", "gif-sequence"
+	InputPath    string      `json:"inputPath"`
+	InputHash    string      `json:"inputHash"`
+	Options      interface{} `json:"options"`
+	OutputPath   string      `json:"outputPath"`
+	OutputHash   string      `json:"outputHash"`
+	BackupPath   string      `json:"backupPath"` // 原地优化时保留的原始文件
+	OriginalSize int64       `json:"originalSize"`
+	NewSize      int64       `json:"newSize"`
+	Success      bool        `json:"success"`
+	Message      string      `json:"message"`
+	Reverted     bool        `json:"reverted"`
+}
+
+// ReportEntry 批处理报告中的单个文件
+type ReportEntry struct {
+	InputPath      string   `json:"inputPath"`
+	OutputPath     string   `json:"outputPath"`
+	Status         string   `json:"status"` // "ok", "skipped", "failed"
+	InputFormat    string   `json:"inputFormat"`
+	OutputFormat   string   `json:"outputFormat"`
+	Quality        int      `json:"quality"`
+	OriginalSize   int64    `json:"originalSize"`
+	NewSize        int64    `json:"newSize"`
+	OriginalWidth  int      `json:"originalWidth"`
+	OriginalHeight int      `json:"originalHeight"`
+	NewWidth       int      `json:"newWidth"`
+	NewHeight      int      `json:"newHeight"`
+	Ratio          float64  `json:"ratio"` // 体积减少的百分比
+	DurationMs     int64    `json:"durationMs"`
+	Warnings       []string `json:"warnings"`
+	Code           string   `json:"code,omitempty"` // 失败时的错误码
+	Error          string   `json:"error,omitempty"`
+}
+
+// ReportTotals 批处理报告合计（大小只统计成功的文件）
+type ReportTotals struct {
+	Files        int     `json:"files"`
+	Succeeded    int     `json:"succeeded"`
+	Skipped      int     `json:"skipped"`
+	Failed       int     `json:"failed"`
+	Canceled     int     `json:"canceled"`
+	Warnings     int     `json:"warnings"`
+	OriginalSize int64   `json:"originalSize"`
+	NewSize      int64   `json:"newSize"`
+	SavedBytes   int64   `json:"savedBytes"`
+	Ratio        float64 `json:"ratio"`
+	DurationMs   int64   `json:"durationMs"`
+}
+
+// BatchReport 批处理报告，供 CI 等工具读取
+type BatchReport struct {
+	GeneratedAt string        `json:"generatedAt"`
+	Files       []ReportEntry `json:"files"`
+	Totals      ReportTotals  `json:"totals"`
+}
+
+// JobRequest 提交到任务队列的请求，按 Kind 使用对应的选项
+type JobRequest struct {
+	Kind        string              `json:"kind"`     // "compress", "directory", "gif_sequence", "gif_compress", "responsive"
+	Priority    int                 `json:"priority"` // 优先级，越大越先执行，相同时先提交的先执行
+	Paths       []string            `json:"paths"`    // compress: 图片列表；directory: 文件夹；gif_sequence: 序列帧；gif_compress/responsive: 源文件
+	Compress    *CompressOptions    `json:"compress,omitempty"`
+	Directory   *DirectoryOptions   `json:"directory,omitempty"`
+	Gif         *GifOptions         `json:"gif,omitempty"`
+	GifCompress *GifCompressOptions `json:"gifCompress,omitempty"`
+	Responsive  *ResponsiveSpec     `json:"responsive,omitempty"`
+}
+
+// Job 队列中的任务及其状态
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`   // "queued", "running", "done", "failed", "canceled"
+	Priority   int        `json:"priority"` // 同 JobRequest.Priority
+	Progress   int        `json:"progress"` // 0-100
+	Message    string     `json:"message"`
+	Code       string     `json:"code"` // 失败或取消时的错误码
+	CreatedAt  string     `json:"createdAt"`
+	StartedAt  string     `json:"startedAt"`
+	FinishedAt string     `json:"finishedAt"`
+	Request    JobRequest `json:"request"`
+
+	// 完成后按 Kind 填写其中一项
+	Compress   []CompressResult  `json:"compress,omitempty"`
+	Directory  *DirectoryResult  `json:"directory,omitempty"`
+	Gif        *GifResult        `json:"gif,omitempty"`
+	Responsive *ResponsiveResult `json:"responsive,omitempty"`
+}
+
+// JobSubmitResult 提交任务的结果
+type JobSubmitResult struct {
+	Success bool   `json:"success"`
+	Code    string `json:"code"` // 请求无效时的错误码
+	Message string `json:"message"`
+	JobID   string `json:"jobId"`
 }
@@ -1,10 +1,24 @@
 package main
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 )
 
+// newTimeID 生成按时间递增的 ID，附加随机后缀，同一时刻（时钟精度较低或并发处理时）生成的 ID 也不会重复
+func newTimeID() string {
+	var b [4]byte
+	rand.Read(b[:])
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + hex.EncodeToString(b[:])
+}
+
 // sortImagePaths 按文件名自然排序
 func sortImagePaths(paths []string) []string {
 	sorted := make([]string, len(paths))
@@ -65,3 +79,34 @@ func formatFileSize(size int64) string {
 		return fmt.Sprintf("%.2f MB", float64(size)/(1024*1024))
 	}
 }
+
+// containsString 判断字符串切片是否包含指定值
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// hashBytes 计算内容的 SHA-256（十六进制）
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashFile 计算文件内容的 SHA-256（十六进制）
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
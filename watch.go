@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// folderWatcher 监视一个文件夹，在新文件写入完成后自动压缩
+type folderWatcher struct {
+	id       string
+	app      *App
+	options  WatchOptions
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+	onResult func(path string, result CompressResult)
+
+	mu        sync.Mutex
+	timers    map[string]*time.Timer
+	queue     chan string
+	ctx       context.Context // 停止监视时取消，正在压缩的文件随之中止
+	cancel    context.CancelFunc
+	stopOnce  sync.Once
+	processed int
+	failed    int
+	startedAt time.Time
+}
+
+// newFolderWatcher 校验选项并开始监视
+func newFolderWatcher(a *App, id string, options WatchOptions, onResult func(string, CompressResult)) (*folderWatcher, error) {
+	stat, err := os.Stat(options.Dir)
+	if err != nil {
+		return nil, newError(ErrRead, nil, "err.open_folder_detail", err)
+	}
+	if !stat.IsDir() {
+		return nil, newError(ErrInvalidOptions, nil, "err.not_folder", options.Dir)
+	}
+	if options.Compress.InPlace {
+		return nil, newError(ErrInvalidOptions, nil, "watch.no_in_place")
+	}
+	if options.Compress.OutputDir == "" || sameFilePath(options.Compress.OutputDir, options.Dir) {
+		return nil, newError(ErrInvalidOptions, nil, "watch.need_output_dir")
+	}
+	if err := os.MkdirAll(options.Compress.OutputDir, 0755); err != nil {
+		return nil, newError(ErrWrite, nil, "err.create_output_dir_detail", err)
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, newError(ErrRead, err, "watch.start_failed")
+	}
+
+	debounce := time.Duration(options.DebounceMs) * time.Millisecond
+	if debounce <= 0 {
+		debounce = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &folderWatcher{
+		id:        id,
+		app:       a,
+		options:   options,
+		watcher:   fsw,
+		debounce:  debounce,
+		onResult:  onResult,
+		timers:    make(map[string]*time.Timer),
+		queue:     make(chan string, 256),
+		ctx:       ctx,
+		cancel:    cancel,
+		startedAt: time.Now(),
+	}
+
+	if err := w.addDir(options.Dir); err != nil {
+		cancel()
+		fsw.Close()
+		return nil, newError(ErrRead, err, "watch.add_failed", options.Dir)
+	}
+
+	go w.loop()
+	go w.worker()
+	return w, nil
+}
+
+// addDir 添加监视目录，递归模式下同时添加子目录
+func (w *folderWatcher) addDir(dir string) error {
+	if !w.options.Recursive {
+		return w.watcher.Add(dir)
+	}
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && (isHiddenOrSystem(d.Name()) || w.isOutputDir(path)) {
+			return filepath.SkipDir
+		}
+		return w.watcher.Add(path)
+	})
+}
+
+// isOutputDir 判断目录是否为输出目录（输出目录在监视目录内部时需要忽略）
+func (w *folderWatcher) isOutputDir(path string) bool {
+	return sameFilePath(path, w.options.Compress.OutputDir)
+}
+
+// loop 接收文件系统事件，对每个文件做防抖
+func (w *folderWatcher) loop() {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+
+			stat, err := os.Stat(event.Name)
+			if err != nil {
+				continue
+			}
+			if stat.IsDir() {
+				if event.Has(fsnotify.Create) && w.options.Recursive {
+					w.addDir(event.Name)
+				}
+				continue
+			}
+			if !w.accepts(event.Name) {
+				continue
+			}
+			w.schedule(event.Name)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("watch error", "dir", w.options.Dir, "error", err)
+			w.onResult("", failResult(newError(ErrRead, err, "watch.error")))
+		}
+	}
+}
+
+// accepts 判断文件是否需要处理
+func (w *folderWatcher) accepts(path string) bool {
+	if isHiddenOrSystem(filepath.Base(path)) {
+		return false
+	}
+	rel, err := filepath.Rel(w.options.Dir, path)
+	if err != nil {
+		return false
+	}
+	if len(w.options.Include) > 0 {
+		if !matchAnyGlob(w.options.Include, rel) {
+			return false
+		}
+	} else if !isSupportedImage(path) {
+		return false
+	}
+	return !matchAnyGlob(w.options.Exclude, rel)
+}
+
+// schedule 重置文件的防抖计时器，文件停止变化后才加入处理队列
+func (w *folderWatcher) schedule(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if t, ok := w.timers[path]; ok {
+		t.Stop()
+	}
+	w.timers[path] = time.AfterFunc(w.debounce, func() {
+		w.mu.Lock()
+		delete(w.timers, path)
+		w.mu.Unlock()
+
+		select {
+		case w.queue <- path:
+		case <-w.ctx.Done():
+		}
+	})
+}
+
+// waitStable 等待文件大小不再变化，避免处理仍在写入的文件
+func (w *folderWatcher) waitStable(path string) bool {
+	var lastSize int64 = -1
+	for i := 0; i < 50; i++ {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		if stat.Size() > 0 && stat.Size() == lastSize {
+			return true
+		}
+		lastSize = stat.Size()
+
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-w.ctx.Done():
+			return false
+		}
+	}
+	return false
+}
+
+// worker 串行处理队列中的文件
+func (w *folderWatcher) worker() {
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case path := <-w.queue:
+			if !w.waitStable(path) {
+				continue
+			}
+			w.process(path)
+		}
+	}
+}
+
+// process 压缩单个文件，按模式决定是否删除源文件
+func (w *folderWatcher) process(path string) {
+	options := w.options.Compress
+	if rel, err := filepath.Rel(w.options.Dir, path); err == nil {
+		options.OutputDir = filepath.Join(options.OutputDir, filepath.Dir(rel))
+	}
+
+	result := w.app.compressFile(w.ctx, path, options, false)
+	if result.Code == CodeCanceled {
+		return
+	}
+
+	w.mu.Lock()
+	if result.Success {
+		w.processed++
+	} else {
+		w.failed++
+	}
+	w.mu.Unlock()
+
+	if result.Success && !result.Skipped && w.options.Mode == "move" {
+		if err := os.Remove(path); err != nil {
+			logger.Warn("remove source failed", "path", path, "error", err)
+			result.Message += tr("watch.remove_source_failed", err)
+		}
+	}
+	w.onResult(path, result)
+}
+
+// info 返回监视状态
+func (w *folderWatcher) info() WatchInfo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WatchInfo{
+		ID:        w.id,
+		Dir:       w.options.Dir,
+		OutputDir: w.options.Compress.OutputDir,
+		Processed: w.processed,
+		Failed:    w.failed,
+		StartedAt: w.startedAt.Format(time.RFC3339),
+	}
+}
+
+// stop 停止监视
+func (w *folderWatcher) stop() {
+	w.stopOnce.Do(func() {
+		w.cancel()
+		w.watcher.Close()
+
+		w.mu.Lock()
+		for _, t := range w.timers {
+			t.Stop()
+		}
+		w.mu.Unlock()
+	})
+}
+
+// StartWatch 开始监视文件夹，新增或修改的图片会自动压缩到输出目录
+func (a *App) StartWatch(options WatchOptions) WatchResult {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+
+	a.watchSeq++
+	id := fmt.Sprintf("watch-%d", a.watchSeq)
+
+	// 使用预设时，以预设的压缩选项为准，输出目录仍以监视选项为准
+	if options.Preset != "" {
+		preset, err := findPreset(options.Preset, options.Dir)
+		if err != nil {
+			return WatchResult{Success: false, Message: err.Error()}
+		}
+		if preset.Compress == nil {
+			return WatchResult{Success: false, Message: tr("preset.no_compress_options", options.Preset)}
+		}
+		outputDir := options.Compress.OutputDir
+		options.Compress = *preset.Compress
+		if outputDir != "" {
+			options.Compress.OutputDir = outputDir
+		}
+	}
+
+	w, err := newFolderWatcher(a, id, options, func(path string, result CompressResult) {
+		a.emit("watch-event", map[string]interface{}{
+			"watchId": id,
+			"path":    path,
+			"result":  result,
+		})
+	})
+	if err != nil {
+		return WatchResult{Success: false, Message: err.Error()}
+	}
+
+	a.watches[id] = w
+	return WatchResult{Success: true, Message: tr("watch.started"), Watch: w.info()}
+}
+
+// StopWatch 停止监视
+func (a *App) StopWatch(id string) ActionResult {
+	a.watchMu.Lock()
+	w, ok := a.watches[id]
+	delete(a.watches, id)
+	a.watchMu.Unlock()
+
+	if !ok {
+		return ActionResult{Success: false, Message: tr("watch.not_found", id)}
+	}
+	w.stop()
+	return ActionResult{Success: true, Message: tr("watch.stopped")}
+}
+
+// ListWatches 列出正在运行的监视任务
+func (a *App) ListWatches() []WatchInfo {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+
+	list := make([]WatchInfo, 0, len(a.watches))
+	for _, w := range a.watches {
+		list = append(list, w.info())
+	}
+	return list
+}
+
+// stopAllWatches 停止所有监视任务（应用退出时调用）
+func (a *App) stopAllWatches() {
+	a.watchMu.Lock()
+	defer a.watchMu.Unlock()
+
+	for id, w := range a.watches {
+		w.stop()
+		delete(a.watches, id)
+	}
+}
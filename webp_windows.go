@@ -5,7 +5,6 @@ package main
 
 import (
 	"bytes"
-	"fmt"
 	"image"
 
 	"golang.org/x/image/webp"
@@ -18,7 +17,7 @@ func decodeWebp(data []byte) (image.Image, error) {
 
 // encodeWebp 编码为 WebP 格式 (Windows 不支持)
 func encodeWebp(buf *bytes.Buffer, img image.Image, quality int) error {
-	return fmt.Errorf("WebP 编码在 Windows 版本暂不支持，请选择其他格式")
+	return newError(ErrUnsupportedFormat, nil, "webp.windows_unsupported")
 }
 
 // webpSupported 是否支持 WebP 输出
@@ -0,0 +1,779 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/adler32"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// 类似 zopfli 的 deflate 压缩：为每个位置找出各长度的最近匹配，按符号代价做最短路径解析，
+// 用上一轮的符号统计更新代价反复迭代，再按 Huffman 编码代价分块。比 zlib 最高级别小几个百分点，但慢很多
+const (
+	zopfliWindow     = 32768
+	zopfliMinMatch   = 3
+	zopfliMaxMatch   = 258
+	zopfliHashBits   = 16
+	zopfliMaxChain   = 1024    // 每个位置最多检查的候选匹配数
+	zopfliChunkSize  = 1 << 20 // 每段单独查找匹配和解析，限制内存占用
+	zopfliSplitStep  = 2048    // 分块时候选分割点的间隔（符号数）
+	zopfliMaxBlocks  = 16      // 每段最多分成的块数
+	zopfliBlockIters = 3       // 分块后每块按自己的统计再迭代的次数
+)
+
+var deflateLengthBase = [29]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 15, 17, 19, 23, 27, 31, 35, 43, 51, 59, 67, 83, 99, 115, 131, 163, 195, 227, 258}
+var deflateLengthExtra = [29]uint{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 0}
+var deflateDistBase = [30]int{1, 2, 3, 4, 5, 7, 9, 13, 17, 25, 33, 49, 65, 97, 129, 193, 257, 385, 513, 769, 1025, 1537, 2049, 3073, 4097, 6145, 8193, 12289, 16385, 24577}
+var deflateDistExtra = [30]uint{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6, 7, 7, 8, 8, 9, 9, 10, 10, 11, 11, 12, 12, 13, 13}
+
+// deflateLengthCode 匹配长度（3-258）对应的长度码下标（符号为 257 + 下标）
+var deflateLengthCode = func() (t [zopfliMaxMatch + 1]int) {
+	for c := 0; c < 28; c++ {
+		for l := deflateLengthBase[c]; l < deflateLengthBase[c+1]; l++ {
+			t[l] = c
+		}
+	}
+	t[zopfliMaxMatch] = 28
+	return t
+}()
+
+// deflateDistCode 返回距离（1-32768）对应的距离码
+func deflateDistCode(d int) int {
+	if d <= 4 {
+		return d - 1
+	}
+	l := bits.Len(uint(d - 1))
+	return 2*l - 2 + int((d-1)>>(l-2)&1)
+}
+
+// deflateCodeLengthOrder 码长码的码长在动态块头中的顺序
+var deflateCodeLengthOrder = [19]int{16, 17, 18, 0, 8, 7, 9, 6, 10, 5, 11, 4, 12, 3, 13, 2, 14, 1, 15}
+
+// lz77Item 字面量（length 为 0）或 (length, dist) 匹配
+type lz77Item struct {
+	lit    byte
+	length uint16
+	dist   uint16
+}
+
+// lz77Histogram 字面量/长度符号（0-285）和距离符号（0-29）的频率
+type lz77Histogram struct {
+	lit  [286]int
+	dist [30]int
+}
+
+func (h *lz77Histogram) add(it lz77Item) {
+	if it.length == 0 {
+		h.lit[it.lit]++
+		return
+	}
+	h.lit[257+deflateLengthCode[it.length]]++
+	h.dist[deflateDistCode(int(it.dist))]++
+}
+
+// zopfliMatch 某位置的一个匹配断点：长度不超过 length 的匹配最近的距离为 dist
+type zopfliMatch struct {
+	length uint16
+	dist   uint16
+}
+
+// zopfliMatcher 哈希链匹配查找器，跨段保留窗口内的状态
+type zopfliMatcher struct {
+	data []byte
+	head []int32
+	prev []int32
+	next int // 下一个要插入哈希链的位置
+}
+
+func newZopfliMatcher(data []byte) *zopfliMatcher {
+	m := &zopfliMatcher{data: data, head: make([]int32, 1<<zopfliHashBits), prev: make([]int32, zopfliWindow)}
+	for i := range m.head {
+		m.head[i] = -1
+	}
+	return m
+}
+
+func (m *zopfliMatcher) hash(i int) int {
+	v := uint32(m.data[i])<<16 | uint32(m.data[i+1])<<8 | uint32(m.data[i+2])
+	return int((v * 2654435761) >> (32 - zopfliHashBits))
+}
+
+// insertUpTo 把 end 之前的位置插入哈希链
+func (m *zopfliMatcher) insertUpTo(end int) {
+	for ; m.next < end; m.next++ {
+		if m.next+zopfliMinMatch > len(m.data) {
+			continue
+		}
+		h := m.hash(m.next)
+		m.prev[m.next&(zopfliWindow-1)] = m.head[h]
+		m.head[h] = int32(m.next)
+	}
+}
+
+// find 返回 pos 处的匹配断点（长度递增），调用前 pos 之前的位置应已插入
+func (m *zopfliMatcher) find(pos int, out []zopfliMatch) []zopfliMatch {
+	out = out[:0]
+	maxLen := min(zopfliMaxMatch, len(m.data)-pos)
+	if maxLen < zopfliMinMatch {
+		return out
+	}
+	data := m.data
+	best := zopfliMinMatch - 1
+	cand := int(m.head[m.hash(pos)])
+	for chain := zopfliMaxChain; cand >= 0 && pos-cand <= zopfliWindow && chain > 0; chain-- {
+		if data[cand+best] == data[pos+best] {
+			l := 0
+			for l < maxLen && data[cand+l] == data[pos+l] {
+				l++
+			}
+			if l > best {
+				best = l
+				out = append(out, zopfliMatch{uint16(l), uint16(pos - cand)})
+				if l == maxLen {
+					break
+				}
+			}
+		}
+		next := int(m.prev[cand&(zopfliWindow-1)])
+		if next >= cand {
+			break // 槽位已被更新的位置覆盖
+		}
+		cand = next
+	}
+	return out
+}
+
+// zopfliCosts 每个符号的代价（位）
+type zopfliCosts struct {
+	lit    [286]float64
+	dist   [30]float64
+	length [zopfliMaxMatch + 1]float64 // 长度符号加附加位
+}
+
+// fixedZopfliCosts 第一轮使用固定 Huffman 表的码长
+func fixedZopfliCosts() *zopfliCosts {
+	c := &zopfliCosts{}
+	for s := range c.lit {
+		switch {
+		case s < 144:
+			c.lit[s] = 8
+		case s < 256:
+			c.lit[s] = 9
+		case s < 280:
+			c.lit[s] = 7
+		default:
+			c.lit[s] = 8
+		}
+	}
+	for s := range c.dist {
+		c.dist[s] = 5 + float64(deflateDistExtra[s])
+	}
+	c.fillLengths()
+	return c
+}
+
+// statsZopfliCosts 由符号频率估计代价，未出现的符号按出现一次计算
+func statsZopfliCosts(h *lz77Histogram) *zopfliCosts {
+	c := &zopfliCosts{}
+	entropy := func(freq []int, out []float64) {
+		total := 0
+		for _, f := range freq {
+			total += f
+		}
+		logTotal := math.Log2(float64(max(total, 1)))
+		for s, f := range freq {
+			out[s] = logTotal - math.Log2(float64(max(f, 1)))
+		}
+	}
+	entropy(h.lit[:], c.lit[:])
+	entropy(h.dist[:], c.dist[:])
+	for s := range c.dist {
+		c.dist[s] += float64(deflateDistExtra[s])
+	}
+	c.fillLengths()
+	return c
+}
+
+func (c *zopfliCosts) fillLengths() {
+	for l := zopfliMinMatch; l <= zopfliMaxMatch; l++ {
+		code := deflateLengthCode[l]
+		c.length[l] = c.lit[257+code] + float64(deflateLengthExtra[code])
+	}
+}
+
+// zopfliChunk 一段输入及其各位置的匹配断点
+type zopfliChunk struct {
+	data       []byte
+	start, end int
+	offsets    []int32 // 位置 i 的断点为 matches[offsets[i-start]:offsets[i-start+1]]
+	matches    []zopfliMatch
+	same       []uint16 // 从该位置起相同字节的个数（最多 65535）
+}
+
+func (z *zopfliChunk) at(i int) []zopfliMatch {
+	return z.matches[z.offsets[i-z.start]:z.offsets[i-z.start+1]]
+}
+
+// parse 用给定代价对 [from, to) 做最短路径解析
+func (z *zopfliChunk) parse(from, to int, c *zopfliCosts) []lz77Item {
+	n := to - from
+	cost := make([]float64, n+1)
+	length := make([]uint16, n+1)
+	dist := make([]uint16, n+1)
+	for i := 1; i <= n; i++ {
+		cost[i] = math.Inf(1)
+	}
+	maxRunCost := c.length[zopfliMaxMatch] + c.dist[0]
+	for i := 0; i < n; i++ {
+		pos := from + i
+		base := cost[i]
+		// 长串相同字节的中间只考虑最长的匹配（与 zopfli 相同），避免每个位置都尝试所有长度
+		if i > zopfliMaxMatch && int(z.same[pos-z.start]) > 2*zopfliMaxMatch && n-i > zopfliMaxMatch &&
+			z.data[pos-1] == z.data[pos] {
+			if v := base + maxRunCost; v < cost[i+zopfliMaxMatch] {
+				cost[i+zopfliMaxMatch], length[i+zopfliMaxMatch], dist[i+zopfliMaxMatch] = v, zopfliMaxMatch, 1
+			}
+			continue
+		}
+		if v := base + c.lit[z.data[pos]]; v < cost[i+1] {
+			cost[i+1], length[i+1], dist[i+1] = v, 1, 0
+		}
+		prev := zopfliMinMatch - 1
+		for _, m := range z.at(pos) {
+			l := min(int(m.length), n-i)
+			if l <= prev {
+				break
+			}
+			dc := c.dist[deflateDistCode(int(m.dist))]
+			for k := prev + 1; k <= l; k++ {
+				if v := base + c.length[k] + dc; v < cost[i+k] {
+					cost[i+k], length[i+k], dist[i+k] = v, uint16(k), m.dist
+				}
+			}
+			prev = l
+		}
+	}
+
+	var items []lz77Item
+	for i := n; i > 0; {
+		l := int(length[i])
+		if l == 1 {
+			items = append(items, lz77Item{lit: z.data[from+i-1]})
+		} else {
+			items = append(items, lz77Item{length: uint16(l), dist: dist[i]})
+		}
+		i -= l
+	}
+	for a, b := 0, len(items)-1; a < b; a, b = a+1, b-1 {
+		items[a], items[b] = items[b], items[a]
+	}
+	return items
+}
+
+// optimize 迭代解析 [from, to)，每轮用上一轮的统计作为代价，返回编码后最小的结果
+func (z *zopfliChunk) optimize(ctx context.Context, from, to int, c *zopfliCosts, iterations int) ([]lz77Item, error) {
+	var best []lz77Item
+	bestBits := math.MaxInt
+	for it := 0; it < iterations; it++ {
+		if err := checkCanceled(ctx); err != nil {
+			return nil, err
+		}
+		items := z.parse(from, to, c)
+		h := histogramOf(items)
+		if b := blockBits(h); b < bestBits {
+			best, bestBits = items, b
+		}
+		c = statsZopfliCosts(h)
+	}
+	return best, nil
+}
+
+// literalHistogram 全部用字面量编码时的符号频率
+func literalHistogram(data []byte) *lz77Histogram {
+	h := &lz77Histogram{}
+	for _, c := range data {
+		h.lit[c]++
+	}
+	h.lit[256] = 1
+	return h
+}
+
+func histogramOf(items []lz77Item) *lz77Histogram {
+	h := &lz77Histogram{}
+	for _, it := range items {
+		h.add(it)
+	}
+	h.lit[256] = 1
+	return h
+}
+
+// zopfliZlib 用类似 zopfli 的方式压缩为 zlib 数据流
+func zopfliZlib(ctx context.Context, data []byte, iterations int) ([]byte, error) {
+	w := &deflateBitWriter{out: []byte{0x78, 0xDA}}
+	m := newZopfliMatcher(data)
+	for start := 0; start < len(data) || start == 0; start += zopfliChunkSize {
+		end := min(start+zopfliChunkSize, len(data))
+		z, err := buildZopfliChunk(ctx, m, start, end)
+		if err != nil {
+			return nil, err
+		}
+		items, err := z.optimize(ctx, start, end, fixedZopfliCosts(), iterations)
+		if err != nil {
+			return nil, err
+		}
+		// 再从只有字面量的统计出发解析一次：匹配收益很小的数据（如小字母表的随机数据）
+		// 从固定代价出发会停在大量短匹配的局部最优，比全部用字面量还大
+		alt, err := z.optimize(ctx, start, end, statsZopfliCosts(literalHistogram(data[start:end])), iterations)
+		if err != nil {
+			return nil, err
+		}
+		if blockBits(histogramOf(alt)) < blockBits(histogramOf(items)) {
+			items = alt
+		}
+		final := end == len(data)
+		bounds := splitLZ77Blocks(items)
+		pos := start
+		for i := 0; i+1 < len(bounds); i++ {
+			block := items[bounds[i]:bounds[i+1]]
+			size := 0
+			for _, it := range block {
+				size += max(1, int(it.length))
+			}
+			// 每块按自己的统计再迭代几次
+			if len(bounds) > 2 {
+				again, err := z.optimize(ctx, pos, pos+size, statsZopfliCosts(histogramOf(block)), zopfliBlockIters)
+				if err != nil {
+					return nil, err
+				}
+				if blockBits(histogramOf(again)) < blockBits(histogramOf(block)) {
+					block = again
+				}
+			}
+			w.writeBlock(block, data[pos:pos+size], final && i+2 == len(bounds))
+			pos += size
+		}
+		if end == len(data) {
+			break
+		}
+	}
+	w.flush()
+	return binary.BigEndian.AppendUint32(w.out, adler32.Checksum(data)), nil
+}
+
+// buildZopfliChunk 查找 [start, end) 各位置的匹配断点
+func buildZopfliChunk(ctx context.Context, m *zopfliMatcher, start, end int) (*zopfliChunk, error) {
+	z := &zopfliChunk{data: m.data, start: start, end: end, offsets: make([]int32, end-start+1), same: make([]uint16, end-start)}
+	for i := end - 1; i >= start; i-- {
+		z.same[i-start] = 1
+		if i+1 < end && m.data[i+1] == m.data[i] && z.same[i+1-start] < math.MaxUint16 {
+			z.same[i-start] = z.same[i+1-start] + 1
+		}
+	}
+	var buf []zopfliMatch
+	for i := start; i < end; i++ {
+		if (i-start)%65536 == 0 {
+			if err := checkCanceled(ctx); err != nil {
+				return nil, err
+			}
+		}
+		m.insertUpTo(i)
+		buf = m.find(i, buf)
+		z.matches = append(z.matches, buf...)
+		z.offsets[i-start+1] = int32(len(z.matches))
+	}
+	m.insertUpTo(end)
+	return z, nil
+}
+
+// splitLZ77Blocks 按编码代价把符号序列分块（递归二分，每次选使总代价最小的分割点），返回各块的边界
+func splitLZ77Blocks(items []lz77Item) []int {
+	// 候选分割点处的累计直方图
+	points := []int{0}
+	for p := zopfliSplitStep; p < len(items); p += zopfliSplitStep {
+		points = append(points, p)
+	}
+	points = append(points, len(items))
+	prefix := make([]lz77Histogram, len(points))
+	for i := 1; i < len(points); i++ {
+		prefix[i] = prefix[i-1]
+		for _, it := range items[points[i-1]:points[i]] {
+			prefix[i].add(it)
+		}
+	}
+	memo := make(map[[2]int]int)
+	cost := func(a, b int) int {
+		if v, ok := memo[[2]int{a, b}]; ok {
+			return v
+		}
+		var h lz77Histogram
+		for s := range h.lit {
+			h.lit[s] = prefix[b].lit[s] - prefix[a].lit[s]
+		}
+		for s := range h.dist {
+			h.dist[s] = prefix[b].dist[s] - prefix[a].dist[s]
+		}
+		h.lit[256] = 1
+		memo[[2]int{a, b}] = blockBits(&h)
+		return memo[[2]int{a, b}]
+	}
+
+	cuts := []int{0, len(points) - 1}
+	for len(cuts)-1 < zopfliMaxBlocks {
+		bestGain, bestAt, bestCut := 0, -1, 0
+		for i := 0; i+1 < len(cuts); i++ {
+			a, b := cuts[i], cuts[i+1]
+			whole := cost(a, b)
+			for p := a + 1; p < b; p++ {
+				if gain := whole - cost(a, p) - cost(p, b); gain > bestGain {
+					bestGain, bestAt, bestCut = gain, i, p
+				}
+			}
+		}
+		if bestAt < 0 {
+			break
+		}
+		cuts = append(cuts[:bestAt+1], append([]int{bestCut}, cuts[bestAt+1:]...)...)
+	}
+	bounds := make([]int, len(cuts))
+	for i, c := range cuts {
+		bounds[i] = points[c]
+	}
+	return bounds
+}
+
+// blockBits 估计一个块的位数（动态和固定 Huffman 表中较小的一种）
+func blockBits(h *lz77Histogram) int {
+	dynamic, _ := dynamicBlock(h)
+	return min(dynamic, fixedBlockBits(h))
+}
+
+func extraBits(h *lz77Histogram) int {
+	n := 0
+	for c, e := range deflateLengthExtra {
+		n += h.lit[257+c] * int(e)
+	}
+	for c, e := range deflateDistExtra {
+		n += h.dist[c] * int(e)
+	}
+	return n
+}
+
+func fixedLitLengths() []uint8 {
+	l := make([]uint8, 288)
+	for s := range l {
+		switch {
+		case s < 144:
+			l[s] = 8
+		case s < 256:
+			l[s] = 9
+		case s < 280:
+			l[s] = 7
+		default:
+			l[s] = 8
+		}
+	}
+	return l
+}
+
+func fixedBlockBits(h *lz77Histogram) int {
+	lit := fixedLitLengths()
+	n := 3 + extraBits(h)
+	for s, f := range h.lit {
+		n += f * int(lit[s])
+	}
+	for _, f := range h.dist {
+		n += f * 5
+	}
+	return n
+}
+
+// deflateHeader 动态块的码表：各符号码长和码长的游程编码
+type deflateHeader struct {
+	lit, dist []uint8
+	hlit      int
+	hdist     int
+	rle       [][2]int // 码长码符号和附加值
+	cl        []uint8  // 码长码的码长
+	hclen     int
+}
+
+// dynamicBlock 计算动态块的位数和码表
+func dynamicBlock(h *lz77Histogram) (int, *deflateHeader) {
+	d := &deflateHeader{lit: huffmanLengthsLimited(h.lit[:], 15), dist: huffmanLengthsLimited(h.dist[:], 15)}
+	d.hlit, d.hdist = 257, 1
+	for s := range d.lit {
+		if d.lit[s] > 0 {
+			d.hlit = max(d.hlit, s+1)
+		}
+	}
+	for s := range d.dist {
+		if d.dist[s] > 0 {
+			d.hdist = max(d.hdist, s+1)
+		}
+	}
+	seq := append(append([]uint8{}, d.lit[:d.hlit]...), d.dist[:d.hdist]...)
+	d.rle = rleCodeLengths(seq)
+	var clFreq [19]int
+	for _, r := range d.rle {
+		clFreq[r[0]]++
+	}
+	d.cl = huffmanLengthsLimited(clFreq[:], 7)
+	d.hclen = 4
+	for i, s := range deflateCodeLengthOrder {
+		if d.cl[s] > 0 {
+			d.hclen = max(d.hclen, i+1)
+		}
+	}
+
+	n := 3 + 5 + 5 + 4 + 3*d.hclen + extraBits(h)
+	for _, r := range d.rle {
+		n += int(d.cl[r[0]])
+		switch r[0] {
+		case 16:
+			n += 2
+		case 17:
+			n += 3
+		case 18:
+			n += 7
+		}
+	}
+	for s, f := range h.lit {
+		n += f * int(d.lit[s])
+	}
+	for s, f := range h.dist {
+		n += f * int(d.dist[s])
+	}
+	return n, d
+}
+
+// rleCodeLengths 用码长码 16（重复前一个 3-6 次）、17（3-10 个 0）、18（11-138 个 0）压缩码长序列
+func rleCodeLengths(seq []uint8) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(seq); {
+		v := seq[i]
+		run := 1
+		for i+run < len(seq) && seq[i+run] == v {
+			run++
+		}
+		i += run
+		if v == 0 {
+			for run >= 11 {
+				r := min(run, 138)
+				out = append(out, [2]int{18, r - 11})
+				run -= r
+			}
+			if run >= 3 {
+				out = append(out, [2]int{17, run - 3})
+				run = 0
+			}
+		} else {
+			out = append(out, [2]int{int(v), 0})
+			run--
+			for run >= 3 {
+				r := min(run, 6)
+				out = append(out, [2]int{16, r - 3})
+				run -= r
+			}
+		}
+		for ; run > 0; run-- {
+			out = append(out, [2]int{int(v), 0})
+		}
+	}
+	return out
+}
+
+// huffmanLengthsLimited 用 package-merge 算法生成码长不超过 limit 的最优码长
+// 只有一个符号时再补一个码长为 1 的符号，保证码表完整（有的解码器不接受不完整的码表）
+func huffmanLengthsLimited(freq []int, limit int) []uint8 {
+	lengths := make([]uint8, len(freq))
+	var syms []int
+	for s, f := range freq {
+		if f > 0 {
+			syms = append(syms, s)
+		}
+	}
+	switch len(syms) {
+	case 0:
+		lengths[0], lengths[1] = 1, 1
+		return lengths
+	case 1:
+		other := 0
+		if syms[0] == 0 {
+			other = 1
+		}
+		lengths[syms[0]], lengths[other] = 1, 1
+		return lengths
+	}
+	sort.SliceStable(syms, func(a, b int) bool { return freq[syms[a]] < freq[syms[b]] })
+
+	// 每层的列表：叶子（sym >= 0）和由上一层相邻两项合成的包（sym < 0），按权重排序
+	type node struct {
+		weight int
+		sym    int
+	}
+	leaves := make([]node, len(syms))
+	for i, s := range syms {
+		leaves[i] = node{freq[s], s}
+	}
+	levels := [][]node{leaves}
+	for l := 1; l < limit; l++ {
+		prev := levels[len(levels)-1]
+		var merged []node
+		i := 0
+		for j := 0; j+1 < len(prev) || i < len(leaves); {
+			if j+1 < len(prev) && (i >= len(leaves) || prev[j].weight+prev[j+1].weight < leaves[i].weight) {
+				merged = append(merged, node{prev[j].weight + prev[j+1].weight, -1})
+				j += 2
+			} else {
+				merged = append(merged, leaves[i])
+				i++
+			}
+		}
+		levels = append(levels, merged)
+	}
+	// 取最后一层的前 2n-2 项，逐层展开包，每个叶子每出现一次码长加一
+	take := 2*len(syms) - 2
+	for l := len(levels) - 1; l >= 0 && take > 0; l-- {
+		packages := 0
+		for _, nd := range levels[l][:take] {
+			if nd.sym >= 0 {
+				lengths[nd.sym]++
+			} else {
+				packages++
+			}
+		}
+		take = 2 * packages
+	}
+	return lengths
+}
+
+// deflateCodes 由码长生成规范 Huffman 码（按 deflate 的位序反转）
+func deflateCodes(lengths []uint8) []uint16 {
+	var count [16]int
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [16]int
+	code := 0
+	for l := 1; l < 16; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	codes := make([]uint16, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = uint16(bits.Reverse16(uint16(next[l])) >> (16 - l))
+		next[l]++
+	}
+	return codes
+}
+
+// deflateBitWriter 按 deflate 的位序（低位在前）写出
+type deflateBitWriter struct {
+	out  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *deflateBitWriter) bits(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.out = append(w.out, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *deflateBitWriter) flush() {
+	if w.nacc > 0 {
+		w.out = append(w.out, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+}
+
+// deflateMaxStored 不压缩块的最大长度
+const deflateMaxStored = 65535
+
+// writeBlock 写出一个块，使用动态 Huffman 表、固定 Huffman 表和不压缩中最小的一种，raw 为块对应的原始数据
+func (w *deflateBitWriter) writeBlock(items []lz77Item, raw []byte, final bool) {
+	h := histogramOf(items)
+	dynamicSize, d := dynamicBlock(h)
+	fixedSize := fixedBlockBits(h)
+	bfinal := uint32(0)
+	if final {
+		bfinal = 1
+	}
+	// 不压缩块按最坏的对齐计算：每块 3 位块头、最多 7 位填充和 32 位长度
+	if len(raw) > 0 && len(raw)*8+ceilDiv(len(raw), deflateMaxStored)*42 < min(dynamicSize, fixedSize) {
+		w.writeStored(raw, final)
+		return
+	}
+	var litLens, distLens []uint8
+	if fixedSize <= dynamicSize {
+		w.bits(bfinal|1<<1, 3)
+		litLens = fixedLitLengths()
+		distLens = make([]uint8, 30)
+		for i := range distLens {
+			distLens[i] = 5
+		}
+	} else {
+		w.bits(bfinal|2<<1, 3)
+		w.bits(uint32(d.hlit-257), 5)
+		w.bits(uint32(d.hdist-1), 5)
+		w.bits(uint32(d.hclen-4), 4)
+		for _, s := range deflateCodeLengthOrder[:d.hclen] {
+			w.bits(uint32(d.cl[s]), 3)
+		}
+		clCodes := deflateCodes(d.cl)
+		for _, r := range d.rle {
+			w.bits(uint32(clCodes[r[0]]), uint(d.cl[r[0]]))
+			switch r[0] {
+			case 16:
+				w.bits(uint32(r[1]), 2)
+			case 17:
+				w.bits(uint32(r[1]), 3)
+			case 18:
+				w.bits(uint32(r[1]), 7)
+			}
+		}
+		litLens, distLens = d.lit, d.dist
+	}
+
+	litCodes, distCodes := deflateCodes(litLens), deflateCodes(distLens)
+	for _, it := range items {
+		if it.length == 0 {
+			w.bits(uint32(litCodes[it.lit]), uint(litLens[it.lit]))
+			continue
+		}
+		lc := deflateLengthCode[it.length]
+		w.bits(uint32(litCodes[257+lc]), uint(litLens[257+lc]))
+		w.bits(uint32(int(it.length)-deflateLengthBase[lc]), deflateLengthExtra[lc])
+		dc := deflateDistCode(int(it.dist))
+		w.bits(uint32(distCodes[dc]), uint(distLens[dc]))
+		w.bits(uint32(int(it.dist)-deflateDistBase[dc]), deflateDistExtra[dc])
+	}
+	w.bits(uint32(litCodes[256]), uint(litLens[256]))
+}
+
+// writeStored 把 raw 写成不压缩块，超过 65535 字节时分成多块
+func (w *deflateBitWriter) writeStored(raw []byte, final bool) {
+	for len(raw) > 0 {
+		n := min(len(raw), deflateMaxStored)
+		bfinal := uint32(0)
+		if final && n == len(raw) {
+			bfinal = 1
+		}
+		w.bits(bfinal, 3)
+		w.flush()
+		w.out = binary.LittleEndian.AppendUint16(w.out, uint16(n))
+		w.out = binary.LittleEndian.AppendUint16(w.out, ^uint16(n))
+		w.out = append(w.out, raw[:n]...)
+		raw = raw[n:]
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// testDeflateInputs 空数据、单字节、长重复（超过最大匹配长度）、文本、随机数据以及跨越分段的数据
+func testDeflateInputs() map[string][]byte {
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 70000) // 超过一个不压缩块的最大长度
+	rng.Read(random)
+
+	// 小字母表的随机数据：匹配短而多，分块和动态码表都会用到
+	skewed := make([]byte, 60000)
+	for i := range skewed {
+		skewed[i] = "aaaabbc\n"[rng.Intn(8)]
+	}
+
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 300)
+	mixed := append(append(append([]byte{}, text...), random[:5000]...), bytes.Repeat([]byte{0}, 70000)...)
+
+	// 超过一个分段（zopfliChunkSize），匹配可以引用上一段的数据
+	large := bytes.Repeat(append(append([]byte{}, random[:3000]...), "0123456789"...), zopfliChunkSize/3000+10)
+
+	return map[string][]byte{
+		"empty":  {},
+		"single": {42},
+		"run":    bytes.Repeat([]byte{7}, 100000),
+		"text":   []byte(text),
+		"random": random,
+		"skewed": skewed,
+		"mixed":  mixed,
+		"large":  large,
+	}
+}
+
+// zopfliZlib 的输出是合法的 zlib 数据流，解压后与原数据相同
+func TestZopfliZlibRoundTrip(t *testing.T) {
+	for name, data := range testDeflateInputs() {
+		t.Run(name, func(t *testing.T) {
+			iterations := zopfliIterations
+			if len(data) > zopfliChunkSize {
+				iterations = 1
+			}
+			compressed, err := zopfliZlib(context.Background(), data, iterations)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := zlib.NewReader(bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("compress/zlib: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("decompressed %d bytes, want %d", len(got), len(data))
+			}
+
+			// 不应比标准库最高级别更大（不可压缩的数据使用不压缩块）
+			std, err := zlibCompress(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(compressed) > len(std) {
+				t.Errorf("zopfli %d bytes, compress/zlib %d bytes", len(compressed), len(std))
+			}
+		})
+	}
+}
+
+// 限长码长：不超过上限，且码表完整（Kraft 和恰好为 1）
+func TestHuffmanLengthsLimited(t *testing.T) {
+	fib := make([]int, 30)
+	a, b := 1, 1
+	for i := range fib {
+		fib[i] = a
+		a, b = b, a+b
+	}
+	cases := map[string][]int{
+		"none":      make([]int, 19),
+		"one":       {0, 0, 5, 0},
+		"two":       {3, 0, 0, 9},
+		"uniform":   {1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		"fibonacci": fib,
+	}
+	for name, freq := range cases {
+		for _, limit := range []int{7, 15} {
+			lengths := huffmanLengthsLimited(freq, limit)
+			kraft := 0
+			for s, l := range lengths {
+				if int(l) > limit {
+					t.Fatalf("%s/%d: symbol %d has length %d", name, limit, s, l)
+				}
+				if freq[s] > 0 && l == 0 {
+					t.Fatalf("%s/%d: used symbol %d has no code", name, limit, s)
+				}
+				if l > 0 {
+					kraft += 1 << (limit - int(l))
+				}
+			}
+			if kraft != 1<<limit {
+				t.Errorf("%s/%d: incomplete code, Kraft sum %d/%d", name, limit, kraft, 1<<limit)
+			}
+		}
+	}
+}